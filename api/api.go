@@ -0,0 +1,178 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package api serves the synced objects, their states and history via a read-only JSON REST API, so that consumers
+// don't depend on the database schema.
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/Icinga/icingadb/utils"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// Prefix is the path the current version of the API is served at.
+const Prefix = "/v1/"
+
+var apiObserver = connection.DbIoSeconds.WithLabelValues("mysql", "select api")
+
+// page is the response to a request of a resource.
+type page struct {
+	Data []map[string]interface{} `json:"data"`
+	Meta pageMeta                 `json:"meta"`
+}
+
+type pageMeta struct {
+	// Total is the number of objects matching the filters on all pages.
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// resourceInfo describes a resource in the response to Prefix.
+type resourceInfo struct {
+	Name   string            `json:"name"`
+	Path   string            `json:"path"`
+	Fields map[string]string `json:"fields"`
+	// Filters are the fields which can only be filtered by.
+	Filters []string `json:"filters,omitempty"`
+}
+
+// NewAPI returns the handler of Prefix. It serves the objects of the environment of super if enabled in the settings
+// returned by settings. If those have a token, it's required as bearer token.
+func NewAPI(super *supervisor.Supervisor, settings func() *config.ApiInfo) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(Prefix, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != Prefix {
+			utils.WriteJSONError(w, http.StatusNotFound, "unknown resource")
+			return
+		}
+
+		infos := make([]resourceInfo, 0, len(resources))
+		for _, res := range resources {
+			info := resourceInfo{Name: res.name, Path: Prefix + res.name, Fields: map[string]string{}}
+			for _, f := range res.fields {
+				if f.member == "" {
+					info.Fields[f.name] = string(f.kind)
+				} else {
+					info.Filters = append(info.Filters, f.name)
+				}
+			}
+
+			infos = append(infos, info)
+		}
+
+		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"resources": infos})
+	})
+
+	for _, res := range resources {
+		res := res
+		mux.HandleFunc(Prefix+res.name, func(w http.ResponseWriter, r *http.Request) {
+			q, err := parseQuery(res, r.URL.Query(), settings().MaxLimit)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			envId := super.EnvId
+			if envId == nil || !super.Dbw.IsConnected() {
+				utils.WriteJSONError(w, http.StatusServiceUnavailable, "database not available yet")
+				return
+			}
+
+			result, err := fetchPage(super.Dbw, res, q, envId)
+			if err != nil {
+				log.WithFields(log.Fields{"context": "API", "resource": res.name}).Error(err)
+				utils.WriteJSONError(w, http.StatusInternalServerError, "can't query the database")
+				return
+			}
+
+			utils.WriteJSON(w, http.StatusOK, result)
+		})
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := settings()
+		if !info.Enabled {
+			utils.WriteJSONError(w, http.StatusNotFound, "the API is disabled, set [api] enabled to enable it")
+			return
+		}
+
+		if info.Token != "" && !utils.HasBearerToken(r, info.Token) {
+			utils.WriteJSONError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// fetchPage queries the page q of res in the environment envId.
+func fetchPage(dbw *connection.DBWrapper, res *resource, q *query, envId []byte) (*page, error) {
+	where := " WHERE " + strings.Join(append([]string{res.environment + " = ?"}, q.where...), " AND ")
+	args := append([]interface{}{envId}, q.args...)
+
+	count, err := dbw.SqlFetchAll(apiObserver, "SELECT COUNT(*) FROM "+res.from+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := res.selected()
+	columns := make([]string, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, f.column)
+	}
+
+	rows, err := dbw.SqlFetchAll(apiObserver, fmt.Sprintf(
+		"SELECT %s FROM %s%s ORDER BY %s LIMIT %d OFFSET %d",
+		strings.Join(columns, ", "), res.from, where, strings.Join(q.order, ", "), q.limit, q.offset,
+	), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &page{Data: make([]map[string]interface{}, 0, len(rows)), Meta: pageMeta{Limit: q.limit, Offset: q.offset}}
+	if len(count) > 0 {
+		result.Meta.Total, _ = count[0][0].(int64)
+	}
+
+	for _, row := range rows {
+		object := make(map[string]interface{}, len(fields))
+		for i, f := range fields {
+			object[f.name] = convertValue(f.kind, row[i])
+		}
+
+		result.Data = append(result.Data, object)
+	}
+
+	return result, nil
+}
+
+// convertValue converts a database value of a field of the given kind into its JSON representation.
+func convertValue(k kind, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch k {
+	case kindBool:
+		return value == "y"
+	case kindID:
+		if b, ok := value.([]byte); ok {
+			return hex.EncodeToString(b)
+		}
+	}
+
+	return value
+}
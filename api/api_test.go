@@ -0,0 +1,193 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package api
+
+import (
+	"encoding/json"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/schema"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAPI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dialect, err := connection.GetDialect("sqlite")
+	require.NoError(t, err)
+
+	dbw, err := connection.NewDBWrapperWithDialect(dialect, filepath.Join(dir, "icingadb.db"), 4)
+	require.NoError(t, err)
+
+	dbw.WaitForConnection()
+	require.NoError(t, schema.Migrate(dbw))
+
+	env := []byte{0xca, 0xfe}
+	exec := func(query string, args ...interface{}) {
+		_, err := dbw.SqlExec(apiObserver, query, args...)
+		require.NoError(t, err)
+	}
+
+	for _, h := range []struct {
+		id        byte
+		env       []byte
+		name      string
+		interval  int
+		notifying string
+	}{
+		{1, env, "web-1", 60, "y"},
+		{2, env, "web-2", 300, "n"},
+		{3, env, "db_1", 60, "y"},
+		{4, []byte{0xbe, 0xef}, "web-3", 60, "y"},
+	} {
+		exec(
+			"INSERT INTO host (id, environment_id, name_checksum, properties_checksum, customvars_checksum, "+
+				"groups_checksum, name, name_ci, display_name, address, address6, checkcommand, checkcommand_id, "+
+				"max_check_attempts, check_timeperiod, check_interval, check_retry_interval, active_checks_enabled, "+
+				"passive_checks_enabled, event_handler_enabled, notifications_enabled, flapping_enabled, "+
+				"flapping_threshold_low, flapping_threshold_high, perfdata_enabled, eventcommand, is_volatile, notes, "+
+				"icon_image_alt, zone, command_endpoint) "+
+				"VALUES (?, ?, '', '', '', '', ?, ?, ?, '127.0.0.1', '', 'hostalive', '', 3, '', ?, 30, 'y', 'y', 'y', ?, "+
+				"'n', 25, 30, 'y', '', 'n', '', '', 'master', '')",
+			[]byte{h.id}, h.env, h.name, h.name, h.name, h.interval, h.notifying,
+		)
+	}
+
+	exec(
+		"INSERT INTO hostgroup (id, environment_id, name_checksum, properties_checksum, customvars_checksum, name, "+
+			"name_ci, display_name) VALUES (?, ?, '', '', '', 'linux', 'linux', 'Linux')",
+		[]byte{9}, env,
+	)
+	exec(
+		"INSERT INTO hostgroup_member (id, environment_id, host_id, hostgroup_id) VALUES (?, ?, ?, ?)",
+		[]byte{1}, env, []byte{1}, []byte{9},
+	)
+	exec(
+		"INSERT INTO state_history (id, environment_id, object_type, host_id, event_time, state_type, soft_state, "+
+			"hard_state, previous_soft_state, previous_hard_state, attempt, max_check_attempts, output) "+
+			"VALUES (?, ?, 'host', ?, 1000, 'hard', 1, 1, 0, 0, 3, 3, 'PING CRITICAL')",
+		[]byte{5}, env, []byte{1},
+	)
+	exec(
+		"INSERT INTO history (id, environment_id, object_type, host_id, state_history_id, event_type, event_time) "+
+			"VALUES (?, ?, 'host', ?, ?, 'state_change', 1000)",
+		[]byte{5}, env, []byte{1}, []byte{5},
+	)
+
+	settings := &config.ApiInfo{MaxLimit: 2}
+	super := &supervisor.Supervisor{Dbw: dbw}
+	api := NewAPI(super, func() *config.ApiInfo { return settings })
+
+	do := func(path, authorization string, response interface{}) int {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", path, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		api.ServeHTTP(recorder, request)
+
+		if response != nil {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response), recorder.Body.String())
+		}
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, do("/v1/hosts", "", nil), "the API must be disabled by default")
+
+	settings.Enabled = true
+	assert.Equal(t, http.StatusServiceUnavailable, do("/v1/hosts", "", nil))
+
+	super.EnvId = env
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+		Meta pageMeta                 `json:"meta"`
+	}
+
+	require.Equal(t, http.StatusOK, do("/v1/hosts", "", &result))
+	assert.Equal(t, pageMeta{Total: 3, Limit: 2, Offset: 0}, result.Meta)
+	require.Len(t, result.Data, 2)
+	assert.Equal(t, "db_1", result.Data[0]["name"])
+	assert.Equal(t, "03", result.Data[0]["id"])
+	assert.Equal(t, true, result.Data[0]["notifications_enabled"])
+	assert.Equal(t, float64(60), result.Data[0]["check_interval"])
+	assert.Nil(t, result.Data[0]["check_timeout"])
+	assert.NotContains(t, result.Data[0], "hostgroup")
+	assert.Equal(t, "web-1", result.Data[1]["name"])
+
+	result.Data = nil
+	require.Equal(t, http.StatusOK, do("/v1/hosts?offset=2", "", &result))
+	assert.Equal(t, pageMeta{Total: 3, Limit: 2, Offset: 2}, result.Meta)
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "web-2", result.Data[0]["name"])
+
+	for query, names := range map[string][]interface{}{
+		"name=web*&sort=-name":               {"web-2", "web-1"},
+		"name=db?1":                          {},
+		"name=db_1":                          {"db_1"},
+		"check_interval.gt=60":               {"web-2"},
+		"notifications_enabled=false":        {"web-2"},
+		"hostgroup=linux":                    {"web-1"},
+		"name.ne=web-1&name.ne=web-2":        {"db_1"},
+		"sort=-check_interval,-name&limit=1": {"web-2"},
+		"id=02":                              {"web-2"},
+	} {
+		result.Data = nil
+		require.Equal(t, http.StatusOK, do("/v1/hosts?"+query, "", &result), query)
+
+		actual := []interface{}{}
+		for _, host := range result.Data {
+			actual = append(actual, host["name"])
+		}
+
+		assert.Equal(t, names, actual, query)
+	}
+
+	result.Data = nil
+	require.Equal(t, http.StatusOK, do("/v1/history?event_type=state_change", "", &result))
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "web-1", result.Data[0]["host_name"])
+	assert.Nil(t, result.Data[0]["service_name"])
+	assert.Equal(t, "PING CRITICAL", result.Data[0]["output"])
+	assert.Equal(t, float64(1000), result.Data[0]["event_time"])
+
+	var apiError struct {
+		Error string `json:"error"`
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do("/v1/hosts?limit=3", "", &apiError))
+	assert.Equal(t, "limit must be between 1 and 2", apiError.Error)
+	assert.Equal(t, http.StatusNotFound, do("/v1/colors", "", nil))
+
+	var index struct {
+		Resources []resourceInfo `json:"resources"`
+	}
+
+	require.Equal(t, http.StatusOK, do("/v1/", "", &index))
+	require.Len(t, index.Resources, len(resources))
+	assert.Equal(t, "/v1/hosts", index.Resources[0].Path)
+	assert.Equal(t, "boolean", index.Resources[0].Fields["notifications_enabled"])
+	assert.Equal(t, []string{"hostgroup"}, index.Resources[0].Filters)
+
+	// All resources must be queryable.
+	for _, res := range resources {
+		assert.Equal(t, http.StatusOK, do("/v1/"+res.name, "", nil), res.name)
+	}
+
+	settings.Token = "secret"
+	assert.Equal(t, http.StatusUnauthorized, do("/v1/hosts", "", nil))
+	assert.Equal(t, http.StatusUnauthorized, do("/v1/hosts", "Bearer guess", nil))
+	assert.Equal(t, http.StatusOK, do("/v1/hosts", "Bearer secret", nil))
+}
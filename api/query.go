@@ -0,0 +1,203 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultLimit is the size of a page unless the limit parameter is given.
+const defaultLimit = 100
+
+// operators map the suffixes of filter parameters, e.g. last_state_change.gt, to SQL.
+var operators = map[string]string{
+	"":   "=",
+	"eq": "=",
+	"ne": "<>",
+	"lt": "<",
+	"le": "<=",
+	"gt": ">",
+	"ge": ">=",
+}
+
+// likeEscaper escapes the wildcards of LIKE with an escape character which is the same in every SQL dialect.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_", "*", "%")
+
+// query is a request for a page of a resource.
+type query struct {
+	where  []string
+	args   []interface{}
+	order  []string
+	limit  int
+	offset int
+}
+
+// parseQuery converts the parameters of a request for r:
+//
+// limit and offset select the page, limit must not exceed maxLimit and defaults to defaultLimit or maxLimit.
+// sort is a comma separated list of fields, descending if prefixed with "-".
+// All others filter by the field they're named after, e.g. host_name=web* or hard_state.ge=2.
+// Multiple values of the same parameter match any of them, those of ne filters none of them.
+// In eq and ne filters of strings, * matches any string.
+func parseQuery(r *resource, params url.Values, maxLimit int) (*query, error) {
+	q := &query{limit: defaultLimit}
+	if q.limit > maxLimit {
+		q.limit = maxLimit
+	}
+
+	var err error
+	if value := params.Get("limit"); value != "" {
+		if q.limit, err = strconv.Atoi(value); err != nil || q.limit < 1 || q.limit > maxLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+	}
+
+	if value := params.Get("offset"); value != "" {
+		if q.offset, err = strconv.Atoi(value); err != nil || q.offset < 0 {
+			return nil, fmt.Errorf("offset must not be negative")
+		}
+	}
+
+	sorting := params.Get("sort")
+	if sorting == "" {
+		sorting = r.sort
+	}
+
+	if err := q.parseSort(r, sorting); err != nil {
+		return nil, err
+	}
+
+	// Sort the parameters, so that the same request always results in the same query.
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch name {
+		case "limit", "offset", "sort":
+			continue
+		}
+
+		if err := q.parseFilter(r, name, params[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+// parseSort adds the ORDER BY columns of sorting.
+func (q *query) parseSort(r *resource, sorting string) error {
+	for _, name := range strings.Split(sorting, ",") {
+		direction := "ASC"
+		if strings.HasPrefix(name, "-") {
+			direction = "DESC"
+			name = name[1:]
+		}
+
+		f := r.field(name)
+		if f == nil || f.member != "" {
+			return fmt.Errorf("can't sort by unknown field %q", name)
+		}
+
+		q.order = append(q.order, f.column+" "+direction)
+	}
+
+	q.order = append(q.order, r.key+" ASC")
+
+	return nil
+}
+
+// parseFilter adds the condition of the filter parameter name with the given values.
+func (q *query) parseFilter(r *resource, name string, values []string) error {
+	fieldName, op := name, ""
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		fieldName, op = name[:dot], name[dot+1:]
+	}
+
+	f := r.field(fieldName)
+	if f == nil {
+		return fmt.Errorf("can't filter by unknown field %q", fieldName)
+	}
+
+	operator, ok := operators[op]
+	if !ok {
+		return fmt.Errorf("unknown operator %q of filter %s, use one of eq, ne, lt, le, gt or ge", op, name)
+	}
+
+	if operator != "=" && operator != "<>" && (f.kind == kindBool || f.kind == kindID || f.member != "") {
+		return fmt.Errorf("filter %s only supports eq and ne", name)
+	}
+
+	if operator == "<>" && f.member != "" {
+		return fmt.Errorf("filter %s only supports eq", name)
+	}
+
+	conditions := make([]string, 0, len(values))
+	for _, value := range values {
+		arg, err := convertFilterValue(f.kind, value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q of filter %s: %s", value, name, err.Error())
+		}
+
+		condition := f.column + " " + operator + " ?"
+		if f.kind == kindString && (operator == "=" || operator == "<>") && strings.Contains(value, "*") {
+			arg = likeEscaper.Replace(value)
+			if operator == "=" {
+				condition = f.column + " LIKE ? ESCAPE '!'"
+			} else {
+				condition = f.column + " NOT LIKE ? ESCAPE '!'"
+			}
+		}
+
+		if f.member != "" {
+			condition = fmt.Sprintf(f.member, condition)
+		}
+
+		conditions = append(conditions, condition)
+		q.args = append(q.args, arg)
+	}
+
+	if len(conditions) == 1 {
+		q.where = append(q.where, conditions[0])
+	} else if operator == "<>" {
+		q.where = append(q.where, "("+strings.Join(conditions, " AND ")+")")
+	} else {
+		q.where = append(q.where, "("+strings.Join(conditions, " OR ")+")")
+	}
+
+	return nil
+}
+
+// convertFilterValue converts a filter value into the database representation of a field of the given kind.
+func convertFilterValue(k kind, value string) (interface{}, error) {
+	switch k {
+	case kindInt:
+		return strconv.ParseInt(value, 10, 64)
+	case kindFloat:
+		return strconv.ParseFloat(value, 64)
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if b {
+			return "y", nil
+		}
+
+		return "n", nil
+	case kindID:
+		return hex.DecodeString(value)
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,65 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	hosts := resources[0]
+	require.Equal(t, "hosts", hosts.name)
+
+	params, err := url.ParseQuery(
+		"name=web*&name=db_1&address.ne=127.0.0.1&check_interval.ge=60&notifications_enabled=false" +
+			"&hostgroup=linux&sort=-check_interval,name&limit=10&offset=20",
+	)
+	require.NoError(t, err)
+
+	q, err := parseQuery(hosts, params, 1000)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"h.address <> ?",
+		"h.check_interval >= ?",
+		"h.id IN (SELECT hgm.host_id FROM hostgroup_member hgm JOIN hostgroup hg ON hg.id = hgm.hostgroup_id " +
+			"WHERE hg.name = ?)",
+		"(h.name LIKE ? ESCAPE '!' OR h.name = ?)",
+		"h.notifications_enabled = ?",
+	}, q.where)
+	assert.Equal(t, []interface{}{"127.0.0.1", int64(60), "linux", "web%", "db_1", "n"}, q.args)
+	assert.Equal(t, []string{"h.check_interval DESC", "h.name ASC", "h.id ASC"}, q.order)
+	assert.Equal(t, 10, q.limit)
+	assert.Equal(t, 20, q.offset)
+
+	q, err = parseQuery(hosts, url.Values{"name.ne": {"*_1*", "a!b*"}}, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(h.name NOT LIKE ? ESCAPE '!' AND h.name NOT LIKE ? ESCAPE '!')"}, q.where)
+	assert.Equal(t, []interface{}{"%!_1%", "a!!b%"}, q.args)
+	assert.Equal(t, []string{"h.name ASC", "h.id ASC"}, q.order, "the default sort must be used")
+	assert.Equal(t, defaultLimit, q.limit)
+
+	for query, problem := range map[string]string{
+		"limit=0":                "limit must be between 1 and 1000",
+		"limit=1001":             "limit must be between 1 and 1000",
+		"offset=-1":              "offset must not be negative",
+		"sort=color":             `can't sort by unknown field "color"`,
+		"sort=hostgroup":         `can't sort by unknown field "hostgroup"`,
+		"color=red":              `can't filter by unknown field "color"`,
+		"name.like=web":          `unknown operator "like" of filter name.like, use one of eq, ne, lt, le, gt or ge`,
+		"is_volatile.gt=0":       "filter is_volatile.gt only supports eq and ne",
+		"hostgroup.ne=linux":     "filter hostgroup.ne only supports eq",
+		"check_interval=often":   `invalid value "often" of filter check_interval: strconv.ParseInt: parsing "often": invalid syntax`,
+		"id=xyz":                 `invalid value "xyz" of filter id: encoding/hex: invalid byte: U+0078 'x'`,
+		"perfdata_enabled=maybe": `invalid value "maybe" of filter perfdata_enabled: strconv.ParseBool: parsing "maybe": invalid syntax`,
+	} {
+		params, err := url.ParseQuery(query)
+		require.NoError(t, err)
+
+		_, err = parseQuery(hosts, params, 1000)
+		assert.EqualError(t, err, problem, query)
+	}
+}
@@ -0,0 +1,316 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package api
+
+// kind tells how the values of a field are encoded in responses and how filter values are converted for it.
+type kind string
+
+const (
+	kindString kind = "string"
+	kindInt    kind = "integer"
+	kindFloat  kind = "number"
+	// kindBool is an enum('y', 'n') column.
+	kindBool kind = "boolean"
+	// kindID is a binary column, encoded as hex.
+	kindID kind = "id"
+)
+
+// field is a property of the objects of a resource.
+type field struct {
+	name   string
+	column string
+	kind   kind
+	// member is set for fields which can only be filtered by, e.g. group membership. It's a condition with a %s
+	// for the condition on column.
+	member string
+}
+
+// resource is a collection of objects served at /v1/<name>.
+type resource struct {
+	name string
+	// from is the FROM clause of the queries, including joins.
+	from string
+	// environment is the environment_id column of the main table.
+	environment string
+	// key is a unique column of the main table. It's the last sort criterion, so that the order of pages is stable.
+	key    string
+	fields []field
+	// sort is the default sort parameter.
+	sort string
+}
+
+// field returns the field with the given name, nil if there is none.
+func (r *resource) field(name string) *field {
+	for i := range r.fields {
+		if r.fields[i].name == name {
+			return &r.fields[i]
+		}
+	}
+
+	return nil
+}
+
+// selected returns the fields included in responses, i.e. all but the member ones.
+func (r *resource) selected() []field {
+	fields := make([]field, 0, len(r.fields))
+	for _, f := range r.fields {
+		if f.member == "" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// checkableFields are the fields of the host or service with the given table alias.
+func checkableFields(alias string) []field {
+	return []field{
+		{name: "display_name", column: alias + ".display_name", kind: kindString},
+		{name: "checkcommand", column: alias + ".checkcommand", kind: kindString},
+		{name: "check_timeperiod", column: alias + ".check_timeperiod", kind: kindString},
+		{name: "max_check_attempts", column: alias + ".max_check_attempts", kind: kindInt},
+		{name: "check_timeout", column: alias + ".check_timeout", kind: kindInt},
+		{name: "check_interval", column: alias + ".check_interval", kind: kindInt},
+		{name: "check_retry_interval", column: alias + ".check_retry_interval", kind: kindInt},
+		{name: "active_checks_enabled", column: alias + ".active_checks_enabled", kind: kindBool},
+		{name: "passive_checks_enabled", column: alias + ".passive_checks_enabled", kind: kindBool},
+		{name: "event_handler_enabled", column: alias + ".event_handler_enabled", kind: kindBool},
+		{name: "notifications_enabled", column: alias + ".notifications_enabled", kind: kindBool},
+		{name: "flapping_enabled", column: alias + ".flapping_enabled", kind: kindBool},
+		{name: "perfdata_enabled", column: alias + ".perfdata_enabled", kind: kindBool},
+		{name: "is_volatile", column: alias + ".is_volatile", kind: kindBool},
+		{name: "notes", column: alias + ".notes", kind: kindString},
+		{name: "zone", column: alias + ".zone", kind: kindString},
+		{name: "command_endpoint", column: alias + ".command_endpoint", kind: kindString},
+	}
+}
+
+// stateFields are the fields of the host_state or service_state with the given table alias.
+func stateFields(alias string) []field {
+	return []field{
+		{name: "state_type", column: alias + ".state_type", kind: kindString},
+		{name: "soft_state", column: alias + ".soft_state", kind: kindInt},
+		{name: "hard_state", column: alias + ".hard_state", kind: kindInt},
+		{name: "previous_hard_state", column: alias + ".previous_hard_state", kind: kindInt},
+		{name: "attempt", column: alias + ".attempt", kind: kindInt},
+		{name: "severity", column: alias + ".severity", kind: kindInt},
+		{name: "output", column: alias + ".output", kind: kindString},
+		{name: "long_output", column: alias + ".long_output", kind: kindString},
+		{name: "performance_data", column: alias + ".performance_data", kind: kindString},
+		{name: "is_problem", column: alias + ".is_problem", kind: kindBool},
+		{name: "is_handled", column: alias + ".is_handled", kind: kindBool},
+		{name: "is_reachable", column: alias + ".is_reachable", kind: kindBool},
+		{name: "is_flapping", column: alias + ".is_flapping", kind: kindBool},
+		// is_acknowledged is "y", "n" or "sticky".
+		{name: "is_acknowledged", column: alias + ".is_acknowledged", kind: kindString},
+		{name: "in_downtime", column: alias + ".in_downtime", kind: kindBool},
+		{name: "execution_time", column: alias + ".execution_time", kind: kindInt},
+		{name: "latency", column: alias + ".latency", kind: kindInt},
+		{name: "check_source", column: alias + ".check_source", kind: kindString},
+		{name: "last_update", column: alias + ".last_update", kind: kindInt},
+		{name: "last_state_change", column: alias + ".last_state_change", kind: kindInt},
+		{name: "next_check", column: alias + ".next_check", kind: kindInt},
+		{name: "next_update", column: alias + ".next_update", kind: kindInt},
+	}
+}
+
+// objectFields are the fields referring to the host or service of comments, downtimes and history,
+// whose table has the given alias. The host has to be joined as h and the service as s.
+func objectFields(alias string) []field {
+	return []field{
+		{name: "object_type", column: alias + ".object_type", kind: kindString},
+		{name: "host_id", column: alias + ".host_id", kind: kindID},
+		{name: "service_id", column: alias + ".service_id", kind: kindID},
+		{name: "host_name", column: "h.name", kind: kindString},
+		{name: "service_name", column: "s.name", kind: kindString},
+	}
+}
+
+// groupFields are the fields of the hostgroup or servicegroup with the given table alias.
+func groupFields(alias string) []field {
+	return []field{
+		{name: "id", column: alias + ".id", kind: kindID},
+		{name: "name", column: alias + ".name", kind: kindString},
+		{name: "display_name", column: alias + ".display_name", kind: kindString},
+	}
+}
+
+func concat(fields ...[]field) []field {
+	var all []field
+	for _, f := range fields {
+		all = append(all, f...)
+	}
+
+	return all
+}
+
+// resources are served at /v1/<name>. Their fields are the contract of the API, don't remove or rename any.
+var resources = []*resource{
+	{
+		name:        "hosts",
+		from:        "host h",
+		environment: "h.environment_id",
+		key:         "h.id",
+		fields: concat(
+			[]field{
+				{name: "id", column: "h.id", kind: kindID},
+				{name: "name", column: "h.name", kind: kindString},
+				{name: "address", column: "h.address", kind: kindString},
+				{name: "address6", column: "h.address6", kind: kindString},
+			},
+			checkableFields("h"),
+			[]field{{
+				name: "hostgroup", column: "hg.name", kind: kindString,
+				member: "h.id IN (SELECT hgm.host_id FROM hostgroup_member hgm " +
+					"JOIN hostgroup hg ON hg.id = hgm.hostgroup_id WHERE %s)",
+			}},
+		),
+		sort: "name",
+	},
+	{
+		name:        "services",
+		from:        "service s JOIN host h ON h.id = s.host_id",
+		environment: "s.environment_id",
+		key:         "s.id",
+		fields: concat(
+			[]field{
+				{name: "id", column: "s.id", kind: kindID},
+				{name: "host_id", column: "s.host_id", kind: kindID},
+				{name: "host_name", column: "h.name", kind: kindString},
+				{name: "name", column: "s.name", kind: kindString},
+			},
+			checkableFields("s"),
+			[]field{{
+				name: "servicegroup", column: "sg.name", kind: kindString,
+				member: "s.id IN (SELECT sgm.service_id FROM servicegroup_member sgm " +
+					"JOIN servicegroup sg ON sg.id = sgm.servicegroup_id WHERE %s)",
+			}},
+		),
+		sort: "host_name,name",
+	},
+	{
+		name:        "host_states",
+		from:        "host_state hs JOIN host h ON h.id = hs.host_id",
+		environment: "hs.environment_id",
+		key:         "hs.host_id",
+		fields: concat(
+			[]field{
+				{name: "host_id", column: "hs.host_id", kind: kindID},
+				{name: "host_name", column: "h.name", kind: kindString},
+			},
+			stateFields("hs"),
+		),
+		sort: "host_name",
+	},
+	{
+		name:        "service_states",
+		from:        "service_state ss JOIN service s ON s.id = ss.service_id JOIN host h ON h.id = s.host_id",
+		environment: "ss.environment_id",
+		key:         "ss.service_id",
+		fields: concat(
+			[]field{
+				{name: "service_id", column: "ss.service_id", kind: kindID},
+				{name: "host_id", column: "s.host_id", kind: kindID},
+				{name: "host_name", column: "h.name", kind: kindString},
+				{name: "service_name", column: "s.name", kind: kindString},
+			},
+			stateFields("ss"),
+		),
+		sort: "host_name,service_name",
+	},
+	{
+		name:        "hostgroups",
+		from:        "hostgroup hg",
+		environment: "hg.environment_id",
+		key:         "hg.id",
+		fields:      groupFields("hg"),
+		sort:        "name",
+	},
+	{
+		name:        "servicegroups",
+		from:        "servicegroup sg",
+		environment: "sg.environment_id",
+		key:         "sg.id",
+		fields:      groupFields("sg"),
+		sort:        "name",
+	},
+	{
+		name:        "comments",
+		from:        "comment c JOIN host h ON h.id = c.host_id LEFT JOIN service s ON s.id = c.service_id",
+		environment: "c.environment_id",
+		key:         "c.id",
+		fields: concat(
+			[]field{
+				{name: "id", column: "c.id", kind: kindID},
+				{name: "name", column: "c.name", kind: kindString},
+			},
+			objectFields("c"),
+			[]field{
+				{name: "author", column: "c.author", kind: kindString},
+				{name: "text", column: "c.text", kind: kindString},
+				// entry_type is "comment" or "ack".
+				{name: "entry_type", column: "c.entry_type", kind: kindString},
+				{name: "entry_time", column: "c.entry_time", kind: kindInt},
+				{name: "is_persistent", column: "c.is_persistent", kind: kindBool},
+				{name: "is_sticky", column: "c.is_sticky", kind: kindBool},
+				{name: "expire_time", column: "c.expire_time", kind: kindInt},
+			},
+		),
+		sort: "-entry_time",
+	},
+	{
+		name:        "downtimes",
+		from:        "downtime d JOIN host h ON h.id = d.host_id LEFT JOIN service s ON s.id = d.service_id",
+		environment: "d.environment_id",
+		key:         "d.id",
+		fields: concat(
+			[]field{
+				{name: "id", column: "d.id", kind: kindID},
+				{name: "name", column: "d.name", kind: kindString},
+			},
+			objectFields("d"),
+			[]field{
+				{name: "triggered_by_id", column: "d.triggered_by_id", kind: kindID},
+				{name: "author", column: "d.author", kind: kindString},
+				{name: "comment", column: "d.comment", kind: kindString},
+				{name: "entry_time", column: "d.entry_time", kind: kindInt},
+				{name: "scheduled_start_time", column: "d.scheduled_start_time", kind: kindInt},
+				{name: "scheduled_end_time", column: "d.scheduled_end_time", kind: kindInt},
+				{name: "flexible_duration", column: "d.flexible_duration", kind: kindInt},
+				{name: "is_flexible", column: "d.is_flexible", kind: kindBool},
+				{name: "is_in_effect", column: "d.is_in_effect", kind: kindBool},
+				{name: "start_time", column: "d.start_time", kind: kindInt},
+				{name: "end_time", column: "d.end_time", kind: kindInt},
+			},
+		),
+		sort: "scheduled_start_time",
+	},
+	{
+		// history is the timeline of all events. The state and notification fields are null for the other events.
+		name: "history",
+		from: "history hi JOIN host h ON h.id = hi.host_id LEFT JOIN service s ON s.id = hi.service_id " +
+			"LEFT JOIN state_history sh ON sh.id = hi.state_history_id " +
+			"LEFT JOIN notification_history nh ON nh.id = hi.notification_history_id",
+		environment: "hi.environment_id",
+		key:         "hi.id",
+		fields: concat(
+			[]field{
+				{name: "id", column: "hi.id", kind: kindID},
+				{name: "event_type", column: "hi.event_type", kind: kindString},
+				{name: "event_time", column: "hi.event_time", kind: kindInt},
+			},
+			objectFields("hi"),
+			[]field{
+				{name: "state_type", column: "sh.state_type", kind: kindString},
+				{name: "soft_state", column: "sh.soft_state", kind: kindInt},
+				{name: "hard_state", column: "sh.hard_state", kind: kindInt},
+				{name: "previous_hard_state", column: "sh.previous_hard_state", kind: kindInt},
+				{name: "output", column: "sh.output", kind: kindString},
+				{name: "notification_type", column: "nh.type", kind: kindString},
+				{name: "notification_author", column: "nh.author", kind: kindString},
+				{name: "notification_text", column: "nh.text", kind: kindString},
+			},
+		),
+		sort: "-event_time",
+	},
+}
@@ -0,0 +1,75 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/schema"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+
+// checkConfig validates the config file at path and, with -connect, the connections to Redis and the database
+// and the schema version. Problems are printed to stderr. It returns the exit code.
+func checkConfig(path string, args []string) int {
+	flags := flag.NewFlagSet("check-config", flag.ExitOnError)
+	connect := flags.Bool("connect", false, "also connect to Redis and the database and check the schema version")
+	_ = flags.Parse(args)
+
+	problems := config.Check(path)
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, problem.Error())
+	}
+
+	if len(problems) > 0 {
+		return 1
+	}
+
+	if *connect {
+		if err := config.ParseConfig(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err.Error())
+			return 1
+		}
+
+		// The connection wrappers log their attempts, only the outcome is of interest here.
+		log.SetLevel(log.FatalLevel)
+
+		if err := checkConnections(); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	}
+
+	fmt.Printf("%s: OK\n", path)
+
+	return 0
+}
+
+// checkConnections pings Redis and the database once and checks the schema version.
+func checkConnections() error {
+	redisConn, err := connectRedis(config.GetRedisInfo())
+	if err != nil {
+		return fmt.Errorf("redis: %s", err.Error())
+	}
+
+	if err := redisConn.Rdb.Ping().Err(); err != nil {
+		return fmt.Errorf("redis: %s", err.Error())
+	}
+
+	mysqlConn, err := connectDatabase(config.GetMysqlInfo())
+	if err != nil {
+		return fmt.Errorf("database: %s", err.Error())
+	}
+
+	if err := mysqlConn.Db.Ping(); err != nil {
+		return fmt.Errorf("database: %s", err.Error())
+	}
+
+	if err := schema.Check(mysqlConn); err != nil {
+		return fmt.Errorf("database: %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"fmt"
+	"github.com/go-ini/ini"
+	"reflect"
+	"strings"
+)
+
+// knownSections maps the sections of the config file to the struct they're mapped to.
+// Sections added to config have to be added here, too. [webhook.<name>] sections map to WebhookInfo.
+var knownSections = map[string]interface{}{
+	"logging":           Logging{},
+	"redis":             RedisInfo{},
+	"mysql":             MysqlInfo{},
+	"database":          MysqlInfo{},
+	"metrics":           MetricsInfo{},
+	"icingadb":          IcingadbInfo{},
+	"ha":                HaInfo{},
+	"history_retention": HistoryRetentionInfo{},
+	"perfdata_export":   PerfdataExportInfo{},
+	"api":               ApiInfo{},
+	"event_stream":      EventStreamInfo{},
+}
+
+// Check reads and validates the config file at path like ParseConfig, but without applying it.
+// In addition to the first invalid value, it reports all unknown sections and keys.
+func Check(path string) []error {
+	cfg, err := loadIni(path)
+	if err != nil {
+		return []error{err}
+	}
+
+	problems := unknownKeys(cfg)
+
+	if _, err := loadConfig(path); err != nil {
+		problems = append(problems, err)
+	}
+
+	return problems
+}
+
+// unknownKeys reports the sections and keys of cfg which aren't mapped to any setting.
+func unknownKeys(cfg *ini.File) []error {
+	var problems []error
+
+	for _, section := range cfg.Sections() {
+		name := section.Name()
+
+		if name == ini.DefaultSection {
+			for _, key := range section.Keys() {
+				problems = append(problems, fmt.Errorf("key %s outside of any section", key.Name()))
+			}
+
+			continue
+		}
+
+		known, ok := knownSections[name]
+		if strings.HasPrefix(name, "webhook.") {
+			known, ok = WebhookInfo{}, true
+		}
+
+		if !ok {
+			problems = append(problems, fmt.Errorf("unknown section [%s]", name))
+			continue
+		}
+
+		keys := iniKeys(known)
+		for _, key := range section.Keys() {
+			if !keys[key.Name()] {
+				problems = append(problems, fmt.Errorf("unknown key %s in section [%s]", key.Name(), name))
+			}
+		}
+	}
+
+	return problems
+}
+
+// iniKeys returns the ini tags of the fields of the struct s.
+func iniKeys(s interface{}) map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(s)
+
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("ini"); key != "" {
+			keys[key] = true
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,92 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	file, err := ioutil.TempFile("", "icingadb")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	defer os.Remove(file.Name())
+
+	writeTestConfig(t, file.Name(), `
+[redis]
+host=127.0.0.1
+[database]
+host=127.0.0.1
+user=icingadb
+password=icingadb
+[webhook.chat]
+url=https://chat.example.com/hooks/icinga
+event_types=notification,downtime_start
+`)
+	assert.Empty(t, Check(file.Name()))
+
+	for name, value := range map[string]string{
+		"ICINGADB_HISTORY_RETENTION_STATE": "30",
+		"ICINGADB_TEST_MYSQL_HOST":         "127.0.0.1",
+	} {
+		require.NoError(t, os.Setenv(name, value))
+		defer os.Unsetenv(name)
+	}
+
+	assert.Empty(t, Check(file.Name()), "variables of underscore sections and others mustn't be reported")
+
+	require.NoError(t, os.Setenv("ICINGADB_HISTORY_RETENTION_STATE", "-1"))
+	problems := Check(file.Name())
+	require.Len(t, problems, 1)
+	assert.EqualError(t, problems[0], "history_retention days must not be negative")
+	require.NoError(t, os.Unsetenv("ICINGADB_HISTORY_RETENTION_STATE"))
+
+	writeTestConfig(t, file.Name(), `
+debug=1
+[redis]
+host=127.0.0.1
+hots=127.0.0.2
+[databse]
+host=127.0.0.1
+[webhook.chat]
+url=https://chat.example.com/hooks/icinga
+retries=3
+`)
+	problems = Check(file.Name())
+	require.Len(t, problems, 5)
+	assert.EqualError(t, problems[0], "key debug outside of any section")
+	assert.EqualError(t, problems[1], "unknown key hots in section [redis]")
+	assert.EqualError(t, problems[2], "unknown section [databse]")
+	assert.EqualError(t, problems[3], "unknown key retries in section [webhook.chat]")
+	assert.EqualError(t, problems[4], "missing database host or socket")
+
+	for config, problem := range map[string]string{
+		"[redis]\nhost=127.0.0.1\nport=70000\n":                           `invalid redis port "70000"`,
+		"[redis]\nhost=127.0.0.1\npool_size=0\n":                          "redis pool_size must be at least 1",
+		"[redis]\nmaster_name=icinga\nsentinels=10.0.0.1\n":               "invalid redis sentinel 10.0.0.1: address 10.0.0.1: missing port in address",
+		"[redis]\nhost=127.0.0.1\n[logging]\nlevel=loud\n":                "invalid logging level: not a valid logrus Level: \"loud\"",
+		"[redis]\nhost=127.0.0.1\n[metrics]\nhost=127.0.0.1\nport=http\n": `invalid metrics port "http"`,
+		"[redis]\nhost=127.0.0.1\n[ha]\nheartbeat_timeout=20s\n": "ha heartbeat_timeout must not exceed takeover_timeout, " +
+			"otherwise others take over while the sync is still running",
+		"[redis]\nhost=127.0.0.1\n[ha]\nmax_heartbeat_gap=15s\n": "ha max_heartbeat_gap must be less than takeover_timeout, " +
+			"otherwise a takeover by others may go unnoticed",
+		"[redis]\nhost=127.0.0.1\n[webhook.chat]\nurl=chat.example.com\n": "webhook.chat url must be a http(s) URL",
+		"[redis]\nhost=127.0.0.1\n[api]\nmax_limit=0\n":                   "api max_limit must be at least 1",
+		"[redis]\nhost=127.0.0.1\n[event_stream]\nbuffer_size=0\n":        "event_stream buffer_size must be at least 1",
+		"[redis]\nhost=127.0.0.1\n[database]\ntype=pgsql\nhost=127.0.0.1\nuser=icingadb\npassword=icingadb\n" +
+			"tls_server_name=db.example.com\n": "database tls_server_name is not supported by pgsql, " +
+			"use the name of the certificate as host",
+		"[redis]\nhost=127.0.0.1\n[database]\ntype=pgsql\nhost=127.0.0.1\nuser=icingadb\npassword=icingadb\n" +
+			"write_timeout=1m\n": "database write_timeout is not supported by pgsql",
+	} {
+		writeTestConfig(t, file.Name(), config)
+		problems := Check(file.Name())
+		if assert.Len(t, problems, 1, config) {
+			assert.EqualError(t, problems[0], problem, config)
+		}
+	}
+}
@@ -4,44 +4,96 @@ package config
 
 import (
 	"errors"
-	"github.com/go-ini/ini"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"github.com/sirupsen/logrus"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Logging struct {
 	Level string `ini:"level"`
 }
 
-var logging = &Logging{
-	Level: "info",
+type RedisInfo struct {
+	Host          string `ini:"host"`
+	Port          string `ini:"port"`
+	User          string `ini:"user"`
+	Password      string `ini:"password"`
+	PoolSize      int    `ini:"pool_size"`
+	Tls           bool   `ini:"tls"`
+	TlsCa         string `ini:"tls_ca"`
+	TlsCert       string `ini:"tls_cert"`
+	TlsKey        string `ini:"tls_key"`
+	TlsServerName string `ini:"tls_server_name"`
+	TlsInsecure   bool   `ini:"tls_insecure"`
+	// MasterName and Sentinels enable Sentinel mode, host and port are ignored then.
+	MasterName       string   `ini:"master_name"`
+	Sentinels        []string `ini:"sentinels" delim:","`
+	SentinelPassword string   `ini:"sentinel_password"`
 }
 
-type RedisInfo struct {
-	Host     string `ini:"host"`
-	Port     string `ini:"port"`
-	User     string `ini:"user"`
-	Password string `ini:"password"`
-	PoolSize int    `ini:"pool_size"`
+// Address returns host:port or just the host if it's the path of a unix socket.
+func (r *RedisInfo) Address() string {
+	if strings.HasPrefix(r.Host, "/") {
+		return r.Host
+	}
+
+	return r.Host + ":" + r.Port
 }
 
-var redisInfo = &RedisInfo{
-	Port:     "6380",
-	PoolSize: 64,
+// TLS returns the TLS settings of the [redis] section.
+func (r *RedisInfo) TLS() *TLSInfo {
+	return &TLSInfo{
+		Enabled:    r.Tls,
+		Ca:         r.TlsCa,
+		Cert:       r.TlsCert,
+		Key:        r.TlsKey,
+		ServerName: r.TlsServerName,
+		Insecure:   r.TlsInsecure,
+	}
 }
 
 type MysqlInfo struct {
+	Type         string `ini:"type"`
 	Host         string `ini:"host"`
 	Port         string `ini:"port"`
 	Database     string `ini:"database"`
 	User         string `ini:"user"`
 	Password     string `ini:"password"`
+	Path         string `ini:"path"`
 	MaxOpenConns int    `ini:"max_open_conns"`
+	// Socket is the path of a unix socket, used instead of host and port.
+	Socket        string        `ini:"socket"`
+	Tls           bool          `ini:"tls"`
+	TlsCa         string        `ini:"tls_ca"`
+	TlsCert       string        `ini:"tls_cert"`
+	TlsKey        string        `ini:"tls_key"`
+	TlsServerName string        `ini:"tls_server_name"`
+	TlsInsecure   bool          `ini:"tls_insecure"`
+	Timeout       time.Duration `ini:"timeout"`
+	ReadTimeout   time.Duration `ini:"read_timeout"`
+	WriteTimeout  time.Duration `ini:"write_timeout"`
+	// Params are additional driver parameters in URL query format, e.g. "charset=utf8mb4&loc=UTC".
+	Params string `ini:"params"`
 }
 
-var mysqlInfo = &MysqlInfo{
-	Port:         "3306",
-	Database:     "icingadb",
-	MaxOpenConns: 50,
+// TLS returns the TLS settings of the database section.
+func (m *MysqlInfo) TLS() *TLSInfo {
+	return &TLSInfo{
+		Enabled:    m.Tls,
+		Ca:         m.TlsCa,
+		Cert:       m.TlsCert,
+		Key:        m.TlsKey,
+		ServerName: m.TlsServerName,
+		Insecure:   m.TlsInsecure,
+	}
 }
 
 type MetricsInfo struct {
@@ -49,63 +101,728 @@ type MetricsInfo struct {
 	Port string `ini:"port"`
 }
 
-var metricsInfo = &MetricsInfo{
-	Port: "8080",
+type IcingadbInfo struct {
+	// DecodeWorkers is the number of workers decoding the config objects from Redis.
+	DecodeWorkers int `ini:"decode_workers"`
+	// ShutdownTimeout is how long to wait for in-flight batches on shutdown before exiting anyway.
+	ShutdownTimeout time.Duration `ini:"shutdown_timeout"`
+	// DeadLetterStream is the Redis stream state and history entries are moved to if they can't be synced.
+	// If empty, such entries are dropped.
+	DeadLetterStream string `ini:"dead_letter_stream"`
+}
+
+type HaInfo struct {
+	// ApiToken is the bearer token required to hand over responsibility via HTTP. Handover is disabled without it.
+	ApiToken string `ini:"api_token"`
+	// HeartbeatTimeout is how long Icinga 2 may send no heartbeat before the sync is paused.
+	HeartbeatTimeout time.Duration `ini:"heartbeat_timeout"`
+	// TakeoverTimeout is how old the heartbeat of the responsible instance has to be for another one to take over.
+	TakeoverTimeout time.Duration `ini:"takeover_timeout"`
+	// MaxHeartbeatGap is how far apart two Icinga 2 heartbeats may be before the responsibility is verified.
+	MaxHeartbeatGap time.Duration `ini:"max_heartbeat_gap"`
+}
+
+// HistoryRetentionInfo configures after how many days the history of each type is deleted. 0 keeps it forever.
+type HistoryRetentionInfo struct {
+	State        int `ini:"state"`
+	Notification int `ini:"notification"`
+	Downtime     int `ini:"downtime"`
+	Comment      int `ini:"comment"`
+	Flapping     int `ini:"flapping"`
+	// Interval is how often outdated history is deleted.
+	Interval time.Duration `ini:"interval"`
+	// BatchSize is how many rows are deleted at once, so that the tables aren't locked for long.
+	BatchSize int `ini:"batch_size"`
+}
+
+// Days returns the retention of the given history type in days, 0 if it's kept forever.
+func (h *HistoryRetentionInfo) Days(historyType string) int {
+	switch historyType {
+	case "state":
+		return h.State
+	case "notification":
+		return h.Notification
+	case "downtime":
+		return h.Downtime
+	case "comment":
+		return h.Comment
+	case "flapping":
+		return h.Flapping
+	default:
+		return 0
+	}
+}
+
+func (h *HistoryRetentionInfo) validate() error {
+	if h.State < 0 || h.Notification < 0 || h.Downtime < 0 || h.Comment < 0 || h.Flapping < 0 {
+		return errors.New("history_retention days must not be negative")
+	}
+
+	if h.Interval < time.Minute {
+		return errors.New("history_retention interval must be at least one minute")
+	}
+
+	if h.BatchSize < 1 {
+		return errors.New("history_retention batch_size must be at least 1")
+	}
+
+	return nil
+}
+
+// ApiInfo configures the read-only REST API served at /v1/ next to the metrics.
+type ApiInfo struct {
+	// Enabled serves the API. Otherwise its URLs respond with 404 Not Found.
+	Enabled bool `ini:"enabled"`
+	// Token is the bearer token required for all requests, if not empty.
+	Token string `ini:"token"`
+	// MaxLimit is how many rows a single page may have at most.
+	MaxLimit int `ini:"max_limit"`
+}
+
+func (a *ApiInfo) validate() error {
+	if a.MaxLimit < 1 {
+		return errors.New("api max_limit must be at least 1")
+	}
+
+	return nil
+}
+
+// EventStreamInfo configures the live stream of state updates and history events served at /v1/events.
+type EventStreamInfo struct {
+	// Enabled serves the stream. Otherwise it responds with 404 Not Found.
+	Enabled bool `ini:"enabled"`
+	// Token is the bearer token required for subscribing, if not empty.
+	Token string `ini:"token"`
+	// BufferSize is how many events are buffered per subscriber. Subscribers falling further behind are disconnected.
+	BufferSize int `ini:"buffer_size"`
+	// MaxSubscribers is how many subscribers may be connected at once.
+	MaxSubscribers int `ini:"max_subscribers"`
+}
+
+func (e *EventStreamInfo) validate() error {
+	if e.BufferSize < 1 {
+		return errors.New("event_stream buffer_size must be at least 1")
+	}
+
+	if e.MaxSubscribers < 1 {
+		return errors.New("event_stream max_subscribers must be at least 1")
+	}
+
+	return nil
+}
+
+// PerfdataExportInfo configures the time-series databases the performance data of states is written to.
+type PerfdataExportInfo struct {
+	// InfluxdbUrl is the InfluxDB write endpoint, e.g. http://localhost:8086/write?db=icinga. Empty disables it.
+	InfluxdbUrl string `ini:"influxdb_url"`
+	// InfluxdbToken is sent as "Authorization: Token ..." if not empty.
+	InfluxdbToken string `ini:"influxdb_token"`
+	// GraphiteAddress is the host:port of the Graphite plaintext protocol. Empty disables it.
+	GraphiteAddress string `ini:"graphite_address"`
+	// GraphitePrefix is the first component of the Graphite metric paths.
+	GraphitePrefix string `ini:"graphite_prefix"`
+	// BatchSize is how many points are written at once.
+	BatchSize int `ini:"batch_size"`
+	// FlushInterval is how often the buffered points are written, if there are less than BatchSize.
+	FlushInterval time.Duration `ini:"flush_interval"`
+	// BufferSize is how many points are kept per writer while it's unavailable. The oldest are dropped beyond.
+	BufferSize int `ini:"buffer_size"`
+	// Timeout limits each write.
+	Timeout time.Duration `ini:"timeout"`
+}
+
+func (p *PerfdataExportInfo) validate() error {
+	if p.InfluxdbUrl != "" {
+		if u, err := url.Parse(p.InfluxdbUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("perfdata_export influxdb_url must be a http(s) URL")
+		}
+	}
+
+	if p.GraphiteAddress != "" {
+		if _, _, err := net.SplitHostPort(p.GraphiteAddress); err != nil {
+			return errors.New("perfdata_export graphite_address must be host:port")
+		}
+	}
+
+	if p.BatchSize < 1 {
+		return errors.New("perfdata_export batch_size must be at least 1")
+	}
+
+	if p.BufferSize < p.BatchSize {
+		return errors.New("perfdata_export buffer_size must be at least batch_size")
+	}
+
+	if p.FlushInterval <= 0 || p.Timeout <= 0 {
+		return errors.New("perfdata_export flush_interval and timeout must be positive")
+	}
+
+	return nil
+}
+
+// WebhookInfo configures an HTTP endpoint history events are posted to, from a [webhook.<name>] section.
+type WebhookInfo struct {
+	// Name is the part of the section name after "webhook.".
+	Name string `ini:"-"`
+	Url  string `ini:"url"`
+	// Secret signs the payloads with HMAC-SHA256 in the X-Icingadb-Signature header, if not empty.
+	Secret string `ini:"secret"`
+	// EventTypes, Hosts and Services select the events posted, empty ones match all.
+	// Hosts and Services are glob patterns of object names. If Services is set, only service events match.
+	EventTypes []string `ini:"event_types" delim:","`
+	Hosts      []string `ini:"hosts" delim:","`
+	Services   []string `ini:"services" delim:","`
+	// PayloadTemplate is a file with a Go text/template rendering the payload. The event is posted as JSON if empty.
+	PayloadTemplate string `ini:"payload_template"`
+	// Timeout limits each request.
+	Timeout time.Duration `ini:"timeout"`
+	// MaxRetries is how often a failed delivery is retried, with a backoff doubling from RetryInterval.
+	MaxRetries    int           `ini:"max_retries"`
+	RetryInterval time.Duration `ini:"retry_interval"`
+}
+
+// defaultWebhook returns a WebhookInfo with all defaults set.
+func defaultWebhook(name string) *WebhookInfo {
+	return &WebhookInfo{
+		Name:          name,
+		Timeout:       10 * time.Second,
+		MaxRetries:    10,
+		RetryInterval: 10 * time.Second,
+	}
+}
+
+func (w *WebhookInfo) validate() error {
+	if u, err := url.Parse(w.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook.%s url must be a http(s) URL", w.Name)
+	}
+
+	if w.Timeout <= 0 || w.RetryInterval <= 0 {
+		return fmt.Errorf("webhook.%s timeout and retry_interval must be positive", w.Name)
+	}
+
+	if w.MaxRetries < 0 {
+		return fmt.Errorf("webhook.%s max_retries must not be negative", w.Name)
+	}
+
+	return nil
+}
+
+func (h *HaInfo) validate() error {
+	if h.HeartbeatTimeout < time.Second || h.TakeoverTimeout < time.Second || h.MaxHeartbeatGap < time.Second {
+		return errors.New("ha timeouts must be at least one second")
+	}
+
+	if h.HeartbeatTimeout > h.TakeoverTimeout {
+		return errors.New("ha heartbeat_timeout must not exceed takeover_timeout, " +
+			"otherwise others take over while the sync is still running")
+	}
+
+	if h.MaxHeartbeatGap >= h.TakeoverTimeout {
+		return errors.New("ha max_heartbeat_gap must be less than takeover_timeout, " +
+			"otherwise a takeover by others may go unnoticed")
+	}
+
+	return nil
+}
+
+// config holds all sections of a config file.
+type config struct {
+	logging          *Logging
+	redis            *RedisInfo
+	mysql            *MysqlInfo
+	metrics          *MetricsInfo
+	icingadb         *IcingadbInfo
+	ha               *HaInfo
+	historyRetention *HistoryRetentionInfo
+	perfdataExport   *PerfdataExportInfo
+	api              *ApiInfo
+	eventStream      *EventStreamInfo
+	webhooks         []*WebhookInfo
+}
+
+// defaultConfig returns a config with all defaults set.
+func defaultConfig() *config {
+	return &config{
+		logging: &Logging{
+			Level: "info",
+		},
+		redis: &RedisInfo{
+			Port:     "6380",
+			PoolSize: 64,
+		},
+		mysql: &MysqlInfo{
+			Type:         "mysql",
+			Database:     "icingadb",
+			MaxOpenConns: 50,
+		},
+		metrics: &MetricsInfo{
+			Port: "8080",
+		},
+		icingadb: &IcingadbInfo{
+			DecodeWorkers:    16,
+			ShutdownTimeout:  30 * time.Second,
+			DeadLetterStream: "icingadb:dead-letter",
+		},
+		ha: &HaInfo{
+			HeartbeatTimeout: 15 * time.Second,
+			TakeoverTimeout:  15 * time.Second,
+			MaxHeartbeatGap:  10 * time.Second,
+		},
+		historyRetention: &HistoryRetentionInfo{
+			Interval:  time.Hour,
+			BatchSize: 1000,
+		},
+		perfdataExport: &PerfdataExportInfo{
+			GraphitePrefix: "icinga2",
+			BatchSize:      1000,
+			FlushInterval:  10 * time.Second,
+			BufferSize:     100000,
+			Timeout:        10 * time.Second,
+		},
+		api: &ApiInfo{
+			MaxLimit: 1000,
+		},
+		eventStream: &EventStreamInfo{
+			BufferSize:     1000,
+			MaxSubscribers: 100,
+		},
+	}
 }
 
+// current is the config in use, guarded by currentMu as it may be replaced on reload.
+var current = defaultConfig()
+var currentMu sync.RWMutex
+
 func ParseConfig(path string) error {
-	cfg, err := ini.Load(path)
+	c, err := loadConfig(path)
 	if err != nil {
 		return err
 	}
 
-	if err := cfg.Section("logging").MapTo(logging); err != nil {
-		return err
+	currentMu.Lock()
+	current = c
+	currentMu.Unlock()
+
+	return nil
+}
+
+// loadConfig reads and validates the config file at path.
+func loadConfig(path string) (*config, error) {
+	c := defaultConfig()
+
+	if err := c.parse(path); err != nil {
+		return nil, err
 	}
 
-	_, err = logrus.ParseLevel(logging.Level)
+	return c, nil
+}
+
+func (c *config) parse(path string) error {
+	cfg, err := loadIni(path)
 	if err != nil {
 		return err
 	}
 
-	if err := cfg.Section("redis").MapTo(redisInfo); err != nil {
+	if err := cfg.Section("logging").MapTo(c.logging); err != nil {
 		return err
 	}
 
-	if redisInfo.Host == "" {
+	_, err = logrus.ParseLevel(c.logging.Level)
+	if err != nil {
+		return fmt.Errorf("invalid logging level: %s", err.Error())
+	}
+
+	if err := cfg.Section("redis").MapTo(c.redis); err != nil {
+		return err
+	}
+
+	if len(c.redis.Sentinels) > 0 {
+		if c.redis.MasterName == "" {
+			return errors.New("missing redis master_name for sentinels")
+		}
+
+		for _, sentinel := range c.redis.Sentinels {
+			_, port, err := net.SplitHostPort(sentinel)
+			if err != nil {
+				return fmt.Errorf("invalid redis sentinel %s: %s", sentinel, err.Error())
+			}
+			if err := validatePort("redis sentinel", port); err != nil {
+				return err
+			}
+		}
+	} else if c.redis.Host == "" {
 		return errors.New("missing redis host")
+	} else if !strings.HasPrefix(c.redis.Host, "/") {
+		if err := validatePort("redis", c.redis.Port); err != nil {
+			return err
+		}
+	}
+
+	if c.redis.PoolSize < 1 {
+		return errors.New("redis pool_size must be at least 1")
+	}
+
+	if _, err := c.redis.TLS().MakeConfig(); err != nil {
+		return fmt.Errorf("invalid redis TLS settings: %s", err.Error())
+	}
+
+	// [mysql] is still honored for existing setups, [database] takes precedence.
+	if err = cfg.Section("mysql").MapTo(c.mysql); err != nil {
+		return err
+	}
+
+	if err = cfg.Section("database").MapTo(c.mysql); err != nil {
+		return err
 	}
 
-	if err = cfg.Section("mysql").MapTo(mysqlInfo); err != nil {
+	if err = cfg.Section("metrics").MapTo(c.metrics); err != nil {
 		return err
 	}
 
-	if err = cfg.Section("metrics").MapTo(metricsInfo); err != nil {
+	if c.metrics.Host != "" {
+		if err := validatePort("metrics", c.metrics.Port); err != nil {
+			return err
+		}
+	}
+
+	if err = cfg.Section("icingadb").MapTo(c.icingadb); err != nil {
 		return err
 	}
 
-	if mysqlInfo.Host == "" {
-		return errors.New("missing mysql host")
+	if err = cfg.Section("ha").MapTo(c.ha); err != nil {
+		return err
 	}
-	if mysqlInfo.User == "" || mysqlInfo.Password == "" {
-		return errors.New("missing mysql credentials")
+
+	if err = c.ha.validate(); err != nil {
+		return err
+	}
+
+	if err = cfg.Section("history_retention").MapTo(c.historyRetention); err != nil {
+		return err
+	}
+
+	if err = c.historyRetention.validate(); err != nil {
+		return err
+	}
+
+	if err = cfg.Section("perfdata_export").MapTo(c.perfdataExport); err != nil {
+		return err
+	}
+
+	if err = c.perfdataExport.validate(); err != nil {
+		return err
+	}
+
+	if err = cfg.Section("api").MapTo(c.api); err != nil {
+		return err
+	}
+
+	if err = c.api.validate(); err != nil {
+		return err
+	}
+
+	if err = cfg.Section("event_stream").MapTo(c.eventStream); err != nil {
+		return err
+	}
+
+	if err = c.eventStream.validate(); err != nil {
+		return err
+	}
+
+	for _, section := range cfg.ChildSections("webhook") {
+		webhook := defaultWebhook(strings.TrimPrefix(section.Name(), "webhook."))
+		if err = section.MapTo(webhook); err != nil {
+			return err
+		}
+
+		if err = webhook.validate(); err != nil {
+			return err
+		}
+
+		c.webhooks = append(c.webhooks, webhook)
+	}
+
+	if c.icingadb.DecodeWorkers < 1 {
+		return errors.New("icingadb decode_workers must be at least 1")
+	}
+	if c.icingadb.ShutdownTimeout <= 0 {
+		return errors.New("icingadb shutdown_timeout must be positive")
+	}
+
+	return c.mysql.validate()
+}
+
+// validatePort checks whether port is a valid TCP port of the section.
+func validatePort(section string, port string) error {
+	if number, err := strconv.ParseUint(port, 10, 16); err != nil || number == 0 {
+		return fmt.Errorf("invalid %s port %q", section, port)
+	}
+
+	return nil
+}
+
+func (m *MysqlInfo) validate() error {
+	if m.MaxOpenConns < 1 {
+		return errors.New("database max_open_conns must be at least 1")
+	}
+
+	switch m.Type {
+	case "mysql", "pgsql":
+	case "sqlite":
+		if m.Path == "" {
+			return errors.New("missing sqlite database path")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown database type %s", m.Type)
+	}
+
+	if m.Port == "" {
+		if m.Type == "pgsql" {
+			m.Port = "5432"
+		} else {
+			m.Port = "3306"
+		}
+	}
+
+	if m.Host == "" && m.Socket == "" {
+		return errors.New("missing database host or socket")
+	}
+	if m.Socket == "" {
+		if err := validatePort("database", m.Port); err != nil {
+			return err
+		}
+	}
+	if m.User == "" || m.Password == "" {
+		return errors.New("missing database credentials")
+	}
+
+	if _, err := m.TLS().MakeConfig(); err != nil {
+		return fmt.Errorf("invalid database TLS settings: %s", err.Error())
+	}
+
+	if m.Type == "pgsql" {
+		// The PostgreSQL driver always verifies the certificate against the host and has no client side write timeout.
+		if m.TlsServerName != "" {
+			return errors.New("database tls_server_name is not supported by pgsql, use the name of the certificate as host")
+		}
+		if m.WriteTimeout != 0 {
+			return errors.New("database write_timeout is not supported by pgsql")
+		}
+	}
+
+	if _, err := url.ParseQuery(m.Params); err != nil {
+		return fmt.Errorf("invalid database params: %s", err.Error())
 	}
 
 	return nil
 }
 
 func GetLogging() *Logging {
-	return logging
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.logging
+}
+
+// Dsn returns the data source name for the configured database type.
+func (m *MysqlInfo) Dsn() (string, error) {
+	switch m.Type {
+	case "sqlite":
+		return m.Path, nil
+	case "pgsql":
+		return m.pgsqlDsn()
+	default:
+		return m.mysqlDsn()
+	}
+}
+
+// mysqlTLSConfigName is the name the TLS settings are registered as with the MySQL driver.
+const mysqlTLSConfigName = "icingadb"
+
+func (m *MysqlInfo) mysqlDsn() (string, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = m.User
+	cfg.Passwd = m.Password
+	cfg.DBName = m.Database
+	cfg.Timeout = m.Timeout
+	cfg.ReadTimeout = m.ReadTimeout
+	cfg.WriteTimeout = m.WriteTimeout
+
+	if m.Socket != "" {
+		cfg.Net = "unix"
+		cfg.Addr = m.Socket
+	} else {
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(m.Host, m.Port)
+	}
+
+	tlsConfig, err := m.TLS().MakeConfig()
+	if err != nil {
+		return "", err
+	}
+
+	if tlsConfig != nil {
+		if err := mysql.RegisterTLSConfig(mysqlTLSConfigName, tlsConfig); err != nil {
+			return "", err
+		}
+
+		cfg.TLSConfig = mysqlTLSConfigName
+	}
+
+	dsn := cfg.FormatDSN()
+	if m.Params == "" {
+		return dsn, nil
+	}
+
+	// Let the driver parse the extra params, so that its own ones (e.g. charset or loc) are honored.
+	sep := "?"
+	if strings.Contains(dsn[strings.LastIndex(dsn, "/"):], "?") {
+		sep = "&"
+	}
+
+	parsed, err := mysql.ParseDSN(dsn + sep + m.Params)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.FormatDSN(), nil
+}
+
+func (m *MysqlInfo) pgsqlDsn() (string, error) {
+	params := map[string]string{
+		"host":     m.Host,
+		"port":     m.Port,
+		"dbname":   m.Database,
+		"user":     m.User,
+		"password": m.Password,
+		"sslmode":  "disable",
+	}
+
+	if m.Socket != "" {
+		// The driver expects the directory containing the socket.
+		params["host"] = filepath.Dir(m.Socket)
+	}
+
+	if m.Timeout > 0 {
+		params["connect_timeout"] = strconv.Itoa(int(m.Timeout.Seconds()))
+	}
+
+	if m.ReadTimeout > 0 {
+		// Passed on as run-time parameter, so the server cancels statements running longer.
+		params["statement_timeout"] = strconv.FormatInt(int64(m.ReadTimeout/time.Millisecond), 10)
+	}
+
+	if m.Tls {
+		if m.TlsInsecure {
+			params["sslmode"] = "require"
+		} else {
+			params["sslmode"] = "verify-full"
+		}
+
+		params["sslrootcert"] = m.TlsCa
+		params["sslcert"] = m.TlsCert
+		params["sslkey"] = m.TlsKey
+	}
+
+	extra, err := url.ParseQuery(m.Params)
+	if err != nil {
+		return "", err
+	}
+
+	for key := range extra {
+		params[key] = extra.Get(key)
+	}
+
+	keys := make([]string, 0, len(params))
+	for key, value := range params {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + quoteDsnValue(params[key])
+	}
+
+	return strings.Join(pairs, " "), nil
+}
+
+// quoteDsnValue quotes a value for a PostgreSQL key/value connection string.
+func quoteDsnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
 }
 
 func GetMysqlInfo() *MysqlInfo {
-	return mysqlInfo
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.mysql
 }
 
 func GetRedisInfo() *RedisInfo {
-	return redisInfo
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.redis
 }
 
 func GetMetricsInfo() *MetricsInfo {
-	return metricsInfo
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.metrics
+}
+
+func GetHaInfo() *HaInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.ha
+}
+
+func GetHistoryRetentionInfo() *HistoryRetentionInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.historyRetention
+}
+
+func GetPerfdataExportInfo() *PerfdataExportInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.perfdataExport
+}
+
+func GetApiInfo() *ApiInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.api
+}
+
+func GetEventStreamInfo() *EventStreamInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.eventStream
+}
+
+// GetWebhooks returns the [webhook.<name>] sections in the order of the config file.
+func GetWebhooks() []*WebhookInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.webhooks
+}
+
+func GetIcingadbInfo() *IcingadbInfo {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current.icingadb
 }
@@ -0,0 +1,84 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMysqlInfo_Dsn(t *testing.T) {
+	info := &MysqlInfo{
+		Type:         "mysql",
+		Host:         "db.example.com",
+		Port:         "3306",
+		Database:     "icingadb",
+		User:         "icingadb",
+		Password:     "p@ss/w?rd",
+		Timeout:      10 * time.Second,
+		ReadTimeout:  time.Minute,
+		WriteTimeout: time.Minute,
+		Params:       "charset=utf8mb4&loc=Europe%2FBerlin&wait_timeout=600",
+	}
+
+	dsn, err := info.Dsn()
+	require.NoError(t, err)
+
+	cfg, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tcp", cfg.Net)
+	assert.Equal(t, "db.example.com:3306", cfg.Addr)
+	assert.Equal(t, "icingadb", cfg.DBName)
+	assert.Equal(t, "p@ss/w?rd", cfg.Passwd)
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+	assert.Equal(t, time.Minute, cfg.ReadTimeout)
+	assert.Equal(t, "Europe/Berlin", cfg.Loc.String())
+	assert.Equal(t, "utf8mb4", cfg.Params["charset"])
+	assert.Equal(t, "600", cfg.Params["wait_timeout"])
+
+	info.Socket = "/run/mysqld/mysqld.sock"
+	info.Params = ""
+	info.Tls = true
+	info.TlsInsecure = true
+
+	dsn, err = info.Dsn()
+	require.NoError(t, err)
+
+	cfg, err = mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+
+	assert.Equal(t, "unix", cfg.Net)
+	assert.Equal(t, "/run/mysqld/mysqld.sock", cfg.Addr)
+	assert.Equal(t, mysqlTLSConfigName, cfg.TLSConfig)
+}
+
+func TestMysqlInfo_PgsqlDsn(t *testing.T) {
+	info := &MysqlInfo{
+		Type:        "pgsql",
+		Host:        "db.example.com",
+		Port:        "5432",
+		Database:    "icingadb",
+		User:        "icingadb",
+		Password:    "it's secret",
+		Timeout:     10 * time.Second,
+		ReadTimeout: time.Minute,
+		Tls:         true,
+		TlsCa:       "/etc/ssl/ca.crt",
+		Params:      "application_name=icingadb",
+	}
+
+	dsn, err := info.Dsn()
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		`application_name='icingadb' connect_timeout='10' dbname='icingadb' host='db.example.com' `+
+			`password='it\'s secret' port='5432' sslmode='verify-full' sslrootcert='/etc/ssl/ca.crt' `+
+			`statement_timeout='60000' user='icingadb'`,
+		dsn,
+	)
+}
@@ -0,0 +1,73 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"reflect"
+)
+
+// Reload reads the config file at path again and applies the settings which can be changed at runtime:
+// [logging], [metrics], [icingadb], [history_retention], [api], [event_stream], api_token of [ha]
+// and max_open_conns of [database]. All other settings stay as they are until the next restart.
+// Reload returns the keys of those settings which have been changed in the file.
+// On error, the current config is kept.
+func Reload(path string) (restartRequired []string, err error) {
+	c, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	currentMu.Lock()
+	defer currentMu.Unlock()
+
+	restartRequired = append(restartRequired, changedKeys("redis", current.redis, c.redis)...)
+	restartRequired = append(restartRequired, changedKeys("database", current.mysql, c.mysql, "max_open_conns")...)
+	restartRequired = append(restartRequired, changedKeys("ha", current.ha, c.ha, "api_token")...)
+	restartRequired = append(restartRequired, changedKeys("perfdata_export", current.perfdataExport, c.perfdataExport)...)
+	if !reflect.DeepEqual(current.webhooks, c.webhooks) {
+		restartRequired = append(restartRequired, "webhook")
+	}
+
+	mysql := *current.mysql
+	mysql.MaxOpenConns = c.mysql.MaxOpenConns
+
+	ha := *current.ha
+	ha.ApiToken = c.ha.ApiToken
+
+	c.redis = current.redis
+	c.mysql = &mysql
+	c.ha = &ha
+	c.perfdataExport = current.perfdataExport
+	c.webhooks = current.webhooks
+	current = c
+
+	return restartRequired, nil
+}
+
+// changedKeys returns the ini keys of section whose values differ between the structs old and new,
+// except for the ignored ones.
+func changedKeys(section string, old, new interface{}, ignore ...string) []string {
+	var keys []string
+	oldValue := reflect.ValueOf(old).Elem()
+	newValue := reflect.ValueOf(new).Elem()
+
+Fields:
+	for i := 0; i < oldValue.NumField(); i++ {
+		key := oldValue.Type().Field(i).Tag.Get("ini")
+		if key == "" {
+			continue
+		}
+
+		for _, ignored := range ignore {
+			if key == ignored {
+				continue Fields
+			}
+		}
+
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			keys = append(keys, section+"."+key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,74 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func writeTestConfig(t *testing.T, path string, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func TestReload(t *testing.T) {
+	file, err := ioutil.TempFile("", "icingadb")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	defer os.Remove(file.Name())
+
+	writeTestConfig(t, file.Name(), `
+[redis]
+host=127.0.0.1
+[database]
+host=127.0.0.1
+user=icingadb
+password=icingadb
+[logging]
+level=info
+`)
+	require.NoError(t, ParseConfig(file.Name()))
+
+	writeTestConfig(t, file.Name(), `
+[redis]
+host=redis.example.com
+[database]
+host=127.0.0.1
+user=icingadb
+password=icingadb
+max_open_conns=10
+[logging]
+level=debug
+[metrics]
+host=127.0.0.1
+[icingadb]
+decode_workers=4
+[history_retention]
+state=90
+[perfdata_export]
+graphite_address=127.0.0.1:2003
+[api]
+enabled=1
+`)
+	restartRequired, err := Reload(file.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"redis.host", "perfdata_export.graphite_address"}, restartRequired)
+	assert.Equal(t, "127.0.0.1", GetRedisInfo().Host, "settings requiring a restart must be kept")
+	assert.Equal(t, 10, GetMysqlInfo().MaxOpenConns)
+	assert.Equal(t, "debug", GetLogging().Level)
+	assert.Equal(t, "127.0.0.1", GetMetricsInfo().Host)
+	assert.Equal(t, 4, GetIcingadbInfo().DecodeWorkers)
+	assert.Equal(t, 90, GetHistoryRetentionInfo().Days("state"))
+	assert.Equal(t, 0, GetHistoryRetentionInfo().Days("flapping"), "history must be kept forever by default")
+	assert.Equal(t, "", GetPerfdataExportInfo().GraphiteAddress)
+	assert.True(t, GetApiInfo().Enabled)
+
+	writeTestConfig(t, file.Name(), "[logging]\nlevel=loud\n")
+	_, err = Reload(file.Name())
+	assert.Error(t, err)
+	assert.Equal(t, "debug", GetLogging().Level, "an invalid config must not be applied")
+}
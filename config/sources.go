@@ -0,0 +1,137 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"fmt"
+	"github.com/go-ini/ini"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// envPrefix is the prefix of environment variables overriding config keys, e.g. ICINGADB_REDIS_HOST.
+const envPrefix = "ICINGADB_"
+
+// fileSuffix marks keys whose value is read from the given file, e.g. password_file.
+const fileSuffix = "_file"
+
+// loadIni reads the config file at path, merges the *.ini snippets of the conf.d directory next to it
+// in lexical order and applies the overrides from the environment. Keys ending in _file are replaced
+// by the key without that suffix set to the content of the file.
+func loadIni(path string) (*ini.File, error) {
+	snippets, err := confDSnippets(filepath.Join(filepath.Dir(path), "conf.d"))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := ini.Load(path, snippets...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range applyEnv(cfg, os.Environ()) {
+		logrus.Warnf("Ignoring environment variable %s, it doesn't name a key of a known config section", name)
+	}
+
+	if err := resolveFileKeys(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// confDSnippets returns the *.ini files of dir sorted by name. A missing dir is no error.
+func confDSnippets(dir string) ([]interface{}, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.ini"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	snippets := make([]interface{}, 0, len(files))
+	for _, file := range files {
+		snippets = append(snippets, file)
+	}
+
+	return snippets, nil
+}
+
+// applyEnv sets the key of the section named by each ICINGADB_<SECTION>_<KEY> variable of environ.
+// As section names may contain underscores, <SECTION> is the longest known section the name starts with,
+// e.g. ICINGADB_HISTORY_RETENTION_STATE sets state of [history_retention].
+// ICINGADB_MYSQL_* variables are applied to [database], which takes precedence over [mysql].
+// It returns the names of the ICINGADB_* variables which don't name a key of a known section, they're not applied.
+func applyEnv(cfg *ini.File, environ []string) []string {
+	sort.Strings(environ)
+
+	// ICINGADB_DATABASE_* is sorted before ICINGADB_MYSQL_*, but has to win.
+	sort.SliceStable(environ, func(i, j int) bool {
+		return strings.HasPrefix(environ[i], envPrefix+"MYSQL_") && !strings.HasPrefix(environ[j], envPrefix+"MYSQL_")
+	})
+
+	var ignored []string
+	for _, variable := range environ {
+		if !strings.HasPrefix(variable, envPrefix) {
+			continue
+		}
+
+		nameValue := strings.SplitN(variable, "=", 2)
+		section, key := envSectionKey(strings.ToLower(strings.TrimPrefix(nameValue[0], envPrefix)))
+		if len(nameValue) != 2 || section == "" {
+			ignored = append(ignored, nameValue[0])
+			continue
+		}
+
+		if section == "mysql" {
+			section = "database"
+		}
+
+		cfg.Section(section).Key(key).SetValue(nameValue[1])
+	}
+
+	return ignored
+}
+
+// envSectionKey splits name, e.g. history_retention_state, into the longest known section it starts with
+// and the rest as key. It returns empty strings if there's no such section or no key.
+func envSectionKey(name string) (section, key string) {
+	for known := range knownSections {
+		if len(known) > len(section) && len(name) > len(known)+1 && strings.HasPrefix(name, known+"_") {
+			section = known
+		}
+	}
+
+	if section == "" {
+		return "", ""
+	}
+
+	return section, name[len(section)+1:]
+}
+
+// resolveFileKeys replaces every key ending in _file by the key without that suffix,
+// set to the content of the named file without trailing line breaks.
+func resolveFileKeys(cfg *ini.File) error {
+	for _, section := range cfg.Sections() {
+		for _, key := range section.Keys() {
+			name := key.Name()
+			if !strings.HasSuffix(name, fileSuffix) || name == fileSuffix {
+				continue
+			}
+
+			content, err := ioutil.ReadFile(key.String())
+			if err != nil {
+				return fmt.Errorf("can't read [%s] %s: %s", section.Name(), name, err.Error())
+			}
+
+			section.Key(strings.TrimSuffix(name, fileSuffix)).SetValue(strings.TrimRight(string(content), "\r\n"))
+			section.DeleteKey(name)
+		}
+	}
+
+	return nil
+}
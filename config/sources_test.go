@@ -0,0 +1,78 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"github.com/go-ini/ini"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	cfg := ini.Empty()
+	cfg.Section("redis").Key("host").SetValue("127.0.0.1")
+
+	ignored := applyEnv(cfg, []string{
+		"HOME=/root",
+		"ICINGADB_REDIS_HOST=redis.example.com",
+		"ICINGADB_REDIS_TLS_SERVER_NAME=redis",
+		"ICINGADB_DATABASE_USER=icingadb",
+		"ICINGADB_MYSQL_USER=root",
+		"ICINGADB_MYSQL_PASSWORD=secret",
+		"ICINGADB_HISTORY_RETENTION_STATE=30",
+		"ICINGADB_EVENT_STREAM_BUFFER_SIZE=10",
+		"ICINGADB_HA_API_TOKEN=secret",
+		"ICINGADB_TEST_MYSQL_HOST=127.0.0.1",
+		"ICINGADB_REDIS_=redis.example.com",
+		"ICINGADB_BROKEN",
+	})
+
+	assert.Equal(t, "redis.example.com", cfg.Section("redis").Key("host").String())
+	assert.Equal(t, "redis", cfg.Section("redis").Key("tls_server_name").String())
+	assert.Equal(t, "icingadb", cfg.Section("database").Key("user").String(), "DATABASE must win over MYSQL")
+	assert.Equal(t, "secret", cfg.Section("database").Key("password").String())
+	assert.Equal(t, "30", cfg.Section("history_retention").Key("state").String())
+	assert.Equal(t, "10", cfg.Section("event_stream").Key("buffer_size").String())
+	assert.Equal(t, "secret", cfg.Section("ha").Key("api_token").String())
+	assert.Equal(t, []string{"ICINGADB_BROKEN", "ICINGADB_REDIS_", "ICINGADB_TEST_MYSQL_HOST"}, ignored)
+	for _, section := range []string{"history", "test"} {
+		_, err := cfg.GetSection(section)
+		assert.Error(t, err, "section [%s] must not be created", section)
+	}
+}
+
+func TestLoadIni(t *testing.T) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "icingadb.ini")
+	writeTestConfig(t, path, "[redis]\nhost=127.0.0.1\nport=6380\n[database]\npassword=icingadb\n")
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conf.d"), 0700))
+	writeTestConfig(t, filepath.Join(dir, "conf.d", "10-redis.ini"), "[redis]\nhost=redis1\nport=6379\n")
+	writeTestConfig(t, filepath.Join(dir, "conf.d", "20-redis.ini"), "[redis]\nhost=redis2\n")
+
+	passwordFile := filepath.Join(dir, "password")
+	writeTestConfig(t, passwordFile, "s3cr3t\n")
+
+	require.NoError(t, os.Setenv("ICINGADB_DATABASE_PASSWORD_FILE", passwordFile))
+	defer os.Unsetenv("ICINGADB_DATABASE_PASSWORD_FILE")
+
+	cfg, err := loadIni(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "redis2", cfg.Section("redis").Key("host").String(), "snippets must be merged in order")
+	assert.Equal(t, "6379", cfg.Section("redis").Key("port").String())
+	assert.Equal(t, "s3cr3t", cfg.Section("database").Key("password").String())
+	assert.False(t, cfg.Section("database").HasKey("password_file"))
+
+	require.NoError(t, os.Setenv("ICINGADB_DATABASE_PASSWORD_FILE", filepath.Join(dir, "nonexistent")))
+
+	_, err = loadIni(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSInfo holds the TLS settings shared by the connection sections.
+type TLSInfo struct {
+	Enabled    bool
+	Ca         string
+	Cert       string
+	Key        string
+	ServerName string
+	Insecure   bool
+}
+
+// MakeConfig returns the tls.Config described by t, nil if TLS is disabled.
+func (t *TLSInfo) MakeConfig() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.Insecure,
+	}
+
+	if t.Cert != "" || t.Key != "" {
+		if t.Cert == "" || t.Key == "" {
+			return nil, errors.New("both cert and key are required for a TLS client certificate")
+		}
+
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.Ca != "" {
+		raw, err := ioutil.ReadFile(t.Ca)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return nil, fmt.Errorf("can't parse CA file %s", t.Ca)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,36 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTLSInfo_MakeConfig(t *testing.T) {
+	tlsConfig, err := (&TLSInfo{Ca: "/nonexistent"}).MakeConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig, "TLS must stay disabled unless enabled explicitly")
+
+	tlsConfig, err = (&TLSInfo{Enabled: true, ServerName: "redis", Insecure: true}).MakeConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "redis", tlsConfig.ServerName)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	_, err = (&TLSInfo{Enabled: true, Cert: "client.crt"}).MakeConfig()
+	assert.Error(t, err, "cert without key must be rejected")
+
+	ca, err := ioutil.TempFile("", "icingadb")
+	require.NoError(t, err)
+	defer os.Remove(ca.Name())
+
+	_, err = ca.WriteString("not a certificate")
+	require.NoError(t, err)
+	require.NoError(t, ca.Close())
+
+	_, err = (&TLSInfo{Enabled: true, Ca: ca.Name()}).MakeConfig()
+	assert.Error(t, err, "invalid CA file must be rejected")
+}
@@ -0,0 +1,112 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/configobject/configsync"
+	"github.com/Icinga/icingadb/ha"
+	"github.com/Icinga/icingadb/schema"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"strings"
+)
+
+// configDelta prints what the config sync would insert, update and delete per object type, without writing anything.
+// It returns the exit code.
+func configDelta(path string, args []string) int {
+	flags := flag.NewFlagSet("config-delta", flag.ExitOnError)
+	environment := flags.String("environment", "", "name of the Icinga 2 environment, empty by default as in Icinga 2")
+	types := flags.String("type", "", "comma separated object types to report, e.g. host,service (default all)")
+	withNames := flags.Bool("names", false, "list the objects to insert, update and delete")
+	format := flags.String("format", "text", "output format, text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: config-delta [options]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	write := configsync.WriteDeltaText
+	switch *format {
+	case "text":
+	case "json":
+		write = configsync.WriteDeltaJSON
+	default:
+		fmt.Fprintf(os.Stderr, "-format: invalid format %q\n", *format)
+		return 2
+	}
+
+	objectTypes := configObjectTypes
+	if *types != "" {
+		objectTypes = nil
+
+	Types:
+		for _, name := range strings.Split(*types, ",") {
+			name = strings.TrimSpace(name)
+			for _, objectInformation := range configObjectTypes {
+				if objectInformation.ObjectType == name {
+					objectTypes = append(objectTypes, objectInformation)
+					continue Types
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "-type: unknown object type %q\n", name)
+			return 2
+		}
+	}
+
+	if err := config.ParseConfig(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, err.Error())
+		return 1
+	}
+
+	// The connection wrapper logs its attempts, only the outcome is of interest here.
+	log.SetLevel(log.FatalLevel)
+
+	redisConn, err := connectRedis(config.GetRedisInfo())
+	if err == nil {
+		err = redisConn.Rdb.Ping().Err()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "redis: %s\n", err.Error())
+		return 1
+	}
+
+	mysqlConn, err := connectDatabase(config.GetMysqlInfo())
+	if err == nil {
+		err = mysqlConn.Db.Ping()
+	}
+
+	if err == nil {
+		err = schema.Check(mysqlConn)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "database: %s\n", err.Error())
+		return 1
+	}
+
+	// Like the HA, which takes the environment of the Icinga 2 heartbeat.
+	envId := ha.Sha1bytes([]byte(*environment))
+	report := configsync.NewDeltaReport(envId)
+
+	for _, objectInformation := range objectTypes {
+		delta, err := configsync.DryRun(redisConn, mysqlConn, envId, objectInformation, *withNames)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", objectInformation.ObjectType, err.Error())
+			return 1
+		}
+
+		report.Types = append(report.Types, delta)
+	}
+
+	if err := write(os.Stdout, report); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	return 0
+}
@@ -3,7 +3,6 @@
 package configsync
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/Icinga/icingadb/configobject"
 	"github.com/Icinga/icingadb/connection"
@@ -14,6 +13,7 @@ import (
 	"github.com/go-redis/redis"
 	log "github.com/sirupsen/logrus"
 	"regexp"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,6 +26,35 @@ type Checksums struct {
 	GroupsChecksum     string `json:"groups_checksum"`
 }
 
+// initialSyncs tracks for each Operator's object type whether its config sync finished since getting responsibility.
+var initialSyncs = struct {
+	sync.Mutex
+	done map[string]bool
+}{done: map[string]bool{}}
+
+func setInitialSyncDone(objectType string, done bool) {
+	initialSyncs.Lock()
+	initialSyncs.done[objectType] = done
+	initialSyncs.Unlock()
+}
+
+// InitialSyncsPending returns the object types whose config sync hasn't finished yet, sorted by name.
+func InitialSyncsPending() []string {
+	initialSyncs.Lock()
+	defer initialSyncs.Unlock()
+
+	pending := make([]string, 0)
+	for objectType, done := range initialSyncs.done {
+		if !done {
+			pending = append(pending, objectType)
+		}
+	}
+
+	sort.Strings(pending)
+
+	return pending
+}
+
 // Operator is the main worker for each config type. It takes a reference to a supervisor super, holding all required
 // connection information and other control mechanisms, a channel chHA, which informs the Operator of the current HA
 // state, and a ObjectInformation reference defining the type and providing the necessary factories.
@@ -59,8 +88,28 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 		wgDelete     *sync.WaitGroup
 		wgUpdate     *sync.WaitGroup
 	)
+	setInitialSyncDone(objectInformation.ObjectType, false)
+
 	log.Debugf("%s: Ready", objectInformation.ObjectType)
-	for msg := range chHA {
+	for {
+		var msg int
+
+		select {
+		case m, ok := <-chHA:
+			if !ok {
+				return nil
+			}
+
+			msg = m
+		case <-super.Done:
+			// The writes in progress are waited for by super.Workers.
+			if done != nil {
+				close(done)
+			}
+
+			return nil
+		}
+
 		switch msg {
 		// Icinga 2 probably restarted or died, stop operations and tell all workers to shut down.
 		case ha.Notify_StopSync:
@@ -68,6 +117,7 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 				log.Debugf("%s: Lost responsibility", objectInformation.ObjectType)
 				close(done)
 				done = nil
+				setInitialSyncDone(objectInformation.ObjectType, false)
 			}
 		// Starts up the whole sync process.
 		case ha.Notify_StartSync:
@@ -95,14 +145,17 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 
 			updateCounter := new(uint32)
 
+			// Used to tell when the insert, delete and update below have finished
+			wgInitialSync := &sync.WaitGroup{}
+
 			go InsertPrepWorker(super, objectInformation, done, chInsert, chInsertBack)
-			go InsertExecWorker(super, objectInformation, done, chInsertBack, wgInsert)
+			super.Go(func() { InsertExecWorker(super, objectInformation, done, chInsertBack, wgInsert) })
 
-			go DeleteExecWorker(super, objectInformation, done, chDelete, wgDelete)
+			super.Go(func() { DeleteExecWorker(super, objectInformation, done, chDelete, wgDelete) })
 
 			go UpdateCompWorker(super, objectInformation, done, chUpdateComp, chUpdate, wgUpdate)
 			go UpdatePrepWorker(super, objectInformation, done, chUpdate, chUpdateBack)
-			go UpdateExecWorker(super, objectInformation, done, chUpdateBack, wgUpdate, updateCounter)
+			super.Go(func() { UpdateExecWorker(super, objectInformation, done, chUpdateBack, wgUpdate, updateCounter) })
 
 			go RuntimeUpdateWorker(super, objectInformation, done, chUpdate, chDelete, wgUpdate, wgDelete)
 
@@ -121,7 +174,11 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 				}
 			}
 
+			wgInitialSync.Add(2)
+
 			go func() {
+				defer wgInitialSync.Done()
+
 				benchmarc := utils.NewBenchmark()
 				wgInsert.Add(len(insert))
 
@@ -142,6 +199,8 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 			}()
 
 			go func() {
+				defer wgInitialSync.Done()
+
 				benchmarc := utils.NewBenchmark()
 				wgDelete.Add(len(delete))
 
@@ -162,7 +221,11 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 			}()
 
 			if objectInformation.HasChecksum {
+				wgInitialSync.Add(1)
+
 				go func() {
+					defer wgInitialSync.Done()
+
 					benchmarc := utils.NewBenchmark()
 					wgUpdate.Add(len(update))
 
@@ -182,10 +245,14 @@ func Operator(super *supervisor.Supervisor, chHA chan int, objectInformation *co
 					}
 				}()
 			}
+
+			go func(done chan struct{}) {
+				if !waitOrKill(wgInitialSync, done) {
+					setInitialSyncDone(objectInformation.ObjectType, true)
+				}
+			}(done)
 		}
 	}
-
-	return nil
 }
 
 // GetDelta takes the ObjectInformation (host, service, checkcommand, etc.) and fetches the ids from MySQL and Redis. It
@@ -279,43 +346,57 @@ func InsertPrepWorker(super *supervisor.Supervisor, objectInformation *configobj
 	}
 }
 
-// InsertExecWorker gets decoded connection.Row objects from the JsonDecodePool and inserts them into MySQL
+// reportBulkError forwards err to the supervisor. Writes rejected by the fence are no fatal error,
+// they just mean that another instance took over and the sync is going to be stopped.
+func reportBulkError(super *supervisor.Supervisor, objectInformation *configobject.ObjectInformation, err error) {
+	if err == connection.ErrFenced {
+		log.WithFields(log.Fields{
+			"type": objectInformation.ObjectType,
+		}).Info("Not responsible anymore, write rejected")
+		return
+	}
+
+	super.ChErr <- err
+}
+
+// InsertExecWorker gets decoded connection.Row objects from the JsonDecodePool and inserts them into MySQL until done is closed.
+// The inserts are run via super.Go, so that a shutdown waits for them.
 func InsertExecWorker(super *supervisor.Supervisor, objectInformation *configobject.ObjectInformation, done chan struct{}, chInsertBack <-chan []connection.Row, wg *sync.WaitGroup) {
-	for rows := range chInsertBack {
+	for {
+		var rows []connection.Row
+
 		select {
-		case _, ok := <-done:
-			if !ok {
-				return
-			}
-		default:
+		case <-done:
+			return
+		case rows = <-chInsertBack:
 		}
 
-		go func(rows []connection.Row) {
-			super.ChErr <- super.Dbw.SqlBulkInsert(rows, objectInformation.BulkInsertStmt)
+		super.Go(func() {
+			reportBulkError(super, objectInformation, super.Dbw.SqlBulkInsert(rows, objectInformation.BulkInsertStmt))
 			rowLen := len(rows)
 			wg.Add(-rowLen)
 			ConfigSyncInsertsTotal.WithLabelValues(objectInformation.ObjectType).Add(float64(rowLen))
-		}(rows)
+		})
 	}
 }
 
-// DeleteExecWorker deletes IDs(chDelete) from MySQL
+// DeleteExecWorker deletes IDs(chDelete) from MySQL until done is closed, via super.Go like InsertExecWorker.
 func DeleteExecWorker(super *supervisor.Supervisor, objectInformation *configobject.ObjectInformation, done chan struct{}, chDelete <-chan []string, wg *sync.WaitGroup) {
-	for keys := range chDelete {
+	for {
+		var keys []string
+
 		select {
-		case _, ok := <-done:
-			if !ok {
-				return
-			}
-		default:
+		case <-done:
+			return
+		case keys = <-chDelete:
 		}
 
-		go func(keys []string) {
-			super.ChErr <- super.Dbw.SqlBulkDelete(keys, objectInformation.BulkDeleteStmt)
+		super.Go(func() {
+			reportBulkError(super, objectInformation, super.Dbw.SqlBulkDelete(keys, objectInformation.BulkDeleteStmt))
 			rowLen := len(keys)
 			wg.Add(-rowLen)
 			ConfigSyncDeletesTotal.WithLabelValues(objectInformation.ObjectType).Add(float64(rowLen))
-		}(keys)
+		})
 	}
 }
 
@@ -329,14 +410,12 @@ func UpdateCompWorker(super *supervisor.Supervisor, objectInformation *configobj
 				continue
 			}
 
-			//TODO: Check if this can be done better (json should not be processed in this func)
-			redisChecksums := &Checksums{}
-			err := json.Unmarshal([]byte(chunk.Checksums[i].(string)), redisChecksums)
+			isChanged, err := propertiesChanged(chunk.Checksums[i].(string), mysqlChecksums[key])
 			if err != nil {
 				super.ChErr <- err
 			}
 
-			if redisChecksums.PropertiesChecksum != mysqlChecksums[key]["properties_checksum"] {
+			if isChanged {
 				changed = append(changed, key)
 			} else {
 				wg.Done()
@@ -409,24 +488,25 @@ func UpdatePrepWorker(super *supervisor.Supervisor, objectInformation *configobj
 	}
 }
 
-// UpdateExecWorker gets decoded connection.Row objects from the JsonDecodePool and updates them in MySQL
+// UpdateExecWorker gets decoded connection.Row objects from the JsonDecodePool and updates them in MySQL until done is closed,
+// via super.Go like InsertExecWorker.
 func UpdateExecWorker(super *supervisor.Supervisor, objectInformation *configobject.ObjectInformation, done chan struct{}, chUpdateBack <-chan []connection.Row, wg *sync.WaitGroup, updateCounter *uint32) {
-	for rows := range chUpdateBack {
+	for {
+		var rows []connection.Row
+
 		select {
-		case _, ok := <-done:
-			if !ok {
-				return
-			}
-		default:
+		case <-done:
+			return
+		case rows = <-chUpdateBack:
 		}
 
-		go func(rows []connection.Row) {
-			super.ChErr <- super.Dbw.SqlBulkUpdate(rows, objectInformation.BulkUpdateStmt)
+		super.Go(func() {
+			reportBulkError(super, objectInformation, super.Dbw.SqlBulkUpdate(rows, objectInformation.BulkUpdateStmt))
 			rowLen := len(rows)
 			wg.Add(-rowLen)
 			atomic.AddUint32(updateCounter, uint32(rowLen))
 			ConfigSyncUpdatesTotal.WithLabelValues(objectInformation.ObjectType).Add(float64(rowLen))
-		}(rows)
+		})
 	}
 }
 
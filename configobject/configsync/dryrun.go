@@ -0,0 +1,244 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package configsync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Icinga/icingadb/configobject"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/utils"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// dryRunChunkSize is the number of objects fetched from Redis at once by DryRun.
+const dryRunChunkSize = 500
+
+// TypeDelta is what the config sync would change of an object type.
+type TypeDelta struct {
+	ObjectType string `json:"object_type"`
+	Insert     int    `json:"insert"`
+	Update     int    `json:"update"`
+	Delete     int    `json:"delete"`
+	Unchanged  int    `json:"unchanged"`
+	// Inserted, Updated and Deleted are the names of the objects if requested, their IDs if they have no name.
+	// Deleted objects are always identified by ID as their config is gone from Redis.
+	Inserted []string `json:"inserted,omitempty"`
+	Updated  []string `json:"updated,omitempty"`
+	Deleted  []string `json:"deleted,omitempty"`
+}
+
+// DeltaReport is what the config sync would change in an environment.
+type DeltaReport struct {
+	Environment string       `json:"environment"`
+	Types       []*TypeDelta `json:"types"`
+}
+
+// DryRun computes what the config sync of objectInformation would insert, update and delete in the environment envId,
+// like GetDelta and UpdateCompWorker, without writing anything. If withNames is set, the objects are listed.
+func DryRun(rdbw *connection.RDBWrapper, dbw *connection.DBWrapper, envId []byte, objectInformation *configobject.ObjectInformation, withNames bool) (*TypeDelta, error) {
+	redisIds, err := rdbw.HKeys("icinga:config:" + objectInformation.RedisKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlIds, err := dbw.SqlFetchIds(envId, objectInformation.ObjectType, objectInformation.PrimaryMySqlField)
+	if err != nil {
+		return nil, err
+	}
+
+	insert, maybeUpdate, delete := utils.Delta(redisIds, mysqlIds)
+
+	// Like the Operator, only objects with checksums are updated.
+	update := []string{}
+	if objectInformation.HasChecksum && len(maybeUpdate) > 0 {
+		if update, err = changedObjects(rdbw, dbw, objectInformation, maybeUpdate); err != nil {
+			return nil, err
+		}
+	}
+
+	delta := &TypeDelta{
+		ObjectType: objectInformation.ObjectType,
+		Insert:     len(insert),
+		Update:     len(update),
+		Delete:     len(delete),
+		Unchanged:  len(maybeUpdate) - len(update),
+	}
+
+	if withNames {
+		if delta.Inserted, err = objectNames(rdbw, objectInformation, insert); err != nil {
+			return nil, err
+		}
+
+		if delta.Updated, err = objectNames(rdbw, objectInformation, update); err != nil {
+			return nil, err
+		}
+
+		delta.Deleted = delete
+		sort.Strings(delta.Deleted)
+	}
+
+	return delta, nil
+}
+
+// changedObjects returns those of ids whose properties checksum in Redis differs from the one in the database.
+func changedObjects(rdbw *connection.RDBWrapper, dbw *connection.DBWrapper, objectInformation *configobject.ObjectInformation, ids []string) ([]string, error) {
+	mysqlChecksums, err := dbw.SqlFetchChecksums(objectInformation.ObjectType, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, chunk := range chunkIds(ids) {
+		checksums, err := rdbw.HMGet("icinga:checksum:"+objectInformation.RedisKey, chunk...).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, id := range chunk {
+			// UpdateCompWorker skips objects without checksum, too.
+			if checksums[i] == nil {
+				continue
+			}
+
+			isChanged, err := propertiesChanged(checksums[i].(string), mysqlChecksums[id])
+			if err != nil {
+				return nil, fmt.Errorf("checksums of %s %s: %s", objectInformation.ObjectType, id, err.Error())
+			}
+
+			if isChanged {
+				changed = append(changed, id)
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// propertiesChanged returns whether the properties checksum of the Redis checksums JSON redisChecksums
+// differs from the one of mysqlChecksums as returned by connection.DBWrapper.SqlFetchChecksums.
+func propertiesChanged(redisChecksums string, mysqlChecksums map[string]string) (bool, error) {
+	checksums := &Checksums{}
+	if err := json.Unmarshal([]byte(redisChecksums), checksums); err != nil {
+		return true, err
+	}
+
+	return checksums.PropertiesChecksum != mysqlChecksums["properties_checksum"], nil
+}
+
+// objectNames returns the names of the objects ids of objectInformation in Redis, sorted.
+// Objects without a name are represented by their ID.
+func objectNames(rdbw *connection.RDBWrapper, objectInformation *configobject.ObjectInformation, ids []string) ([]string, error) {
+	names := make([]string, 0, len(ids))
+	for _, chunk := range chunkIds(ids) {
+		configs, err := rdbw.HMGet("icinga:config:"+objectInformation.RedisKey, chunk...).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, id := range chunk {
+			names = append(names, objectName(id, configs[i]))
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// objectName returns the name of the Redis config JSON config, id if it has none.
+func objectName(id string, config interface{}) string {
+	raw, ok := config.(string)
+	if !ok {
+		return id
+	}
+
+	var object struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal([]byte(raw), &object); err != nil || object.Name == "" {
+		return id
+	}
+
+	return object.Name
+}
+
+// chunkIds splits ids into chunks of at most dryRunChunkSize.
+func chunkIds(ids []string) [][]string {
+	var chunks [][]string
+	for len(ids) > dryRunChunkSize {
+		chunks = append(chunks, ids[:dryRunChunkSize])
+		ids = ids[dryRunChunkSize:]
+	}
+
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+
+	return chunks
+}
+
+// NewDeltaReport returns an empty report of the environment envId.
+func NewDeltaReport(envId []byte) *DeltaReport {
+	return &DeltaReport{Environment: hex.EncodeToString(envId), Types: []*TypeDelta{}}
+}
+
+// WriteDeltaJSON writes report to w as JSON.
+func WriteDeltaJSON(w io.Writer, report *DeltaReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// WriteDeltaText writes report to w as a table of the object types, followed by the listed objects
+// prefixed with + for insert, ~ for update and - for delete.
+func WriteDeltaText(w io.Writer, report *DeltaReport) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	total := &TypeDelta{}
+
+	fmt.Fprintf(table, "type\tinsert\tupdate\tdelete\tunchanged\n")
+	for _, delta := range report.Types {
+		fmt.Fprintf(
+			table, "%s\t%d\t%d\t%d\t%d\n",
+			delta.ObjectType, delta.Insert, delta.Update, delta.Delete, delta.Unchanged,
+		)
+
+		total.Insert += delta.Insert
+		total.Update += delta.Update
+		total.Delete += delta.Delete
+		total.Unchanged += delta.Unchanged
+	}
+
+	fmt.Fprintf(table, "total\t%d\t%d\t%d\t%d\n", total.Insert, total.Update, total.Delete, total.Unchanged)
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, delta := range report.Types {
+		if len(delta.Inserted)+len(delta.Updated)+len(delta.Deleted) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "\n%s:\n", delta.ObjectType); err != nil {
+			return err
+		}
+
+		for _, objects := range []struct {
+			prefix string
+			names  []string
+		}{{"+", delta.Inserted}, {"~", delta.Updated}, {"-", delta.Deleted}} {
+			for _, name := range objects.names {
+				if _, err := fmt.Fprintf(w, "  %s %s\n", objects.prefix, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
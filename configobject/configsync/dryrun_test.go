@@ -0,0 +1,79 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package configsync
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPropertiesChanged(t *testing.T) {
+	mysqlChecksums := map[string]string{"properties_checksum": "b6e87de3d4f31b3d4d35466171f4088693b46071"}
+
+	changed, err := propertiesChanged(`{"checksum":"b6e87de3d4f31b3d4d35466171f4088693b46071"}`, mysqlChecksums)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = propertiesChanged(`{"checksum":"0000000000000000000000000000000000000000"}`, mysqlChecksums)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = propertiesChanged(`{"checksum":"b6e87de3d4f31b3d4d35466171f4088693b46071"}`, nil)
+	require.NoError(t, err)
+	assert.True(t, changed, "objects missing in the database must be changed")
+
+	_, err = propertiesChanged(`{`, mysqlChecksums)
+	assert.Error(t, err)
+}
+
+func TestObjectName(t *testing.T) {
+	assert.Equal(t, "web-1", objectName("01", `{"name":"web-1","address":"127.0.0.1"}`))
+	assert.Equal(t, "01", objectName("01", `{"host_id":"02"}`))
+	assert.Equal(t, "01", objectName("01", nil))
+	assert.Equal(t, "01", objectName("01", `{`))
+}
+
+func TestChunkIds(t *testing.T) {
+	ids := make([]string, 2*dryRunChunkSize+1)
+
+	chunks := chunkIds(ids)
+	require.Len(t, chunks, 3)
+	assert.Len(t, chunks[0], dryRunChunkSize)
+	assert.Len(t, chunks[2], 1)
+
+	assert.Empty(t, chunkIds(nil))
+}
+
+func TestWriteDeltaText(t *testing.T) {
+	report := NewDeltaReport([]byte{0xca, 0xfe})
+	report.Types = append(report.Types,
+		&TypeDelta{
+			ObjectType: "host", Insert: 1, Update: 1, Delete: 1, Unchanged: 10,
+			Inserted: []string{"web-2"}, Updated: []string{"web-1"}, Deleted: []string{"03"},
+		},
+		&TypeDelta{ObjectType: "hostgroup", Unchanged: 2},
+	)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteDeltaText(buf, report))
+	assert.Equal(t, ""+
+		"type       insert  update  delete  unchanged\n"+
+		"host       1       1       1       10\n"+
+		"hostgroup  0       0       0       2\n"+
+		"total      1       1       1       12\n"+
+		"\n"+
+		"host:\n"+
+		"  + web-2\n"+
+		"  ~ web-1\n"+
+		"  - 03\n",
+		buf.String(),
+	)
+
+	buf.Reset()
+	require.NoError(t, WriteDeltaJSON(buf, report))
+	assert.Contains(t, buf.String(), `"environment": "cafe"`)
+	assert.Contains(t, buf.String(), `"inserted": [`)
+	assert.NotContains(t, buf.String(), `"updated": null`)
+}
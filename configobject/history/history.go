@@ -4,9 +4,14 @@ package history
 
 import (
 	"fmt"
+	"github.com/Icinga/icingadb/config"
 	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/deadletter"
+	"github.com/Icinga/icingadb/eventstream"
+	"github.com/Icinga/icingadb/ha"
 	"github.com/Icinga/icingadb/supervisor"
 	"github.com/Icinga/icingadb/utils"
+	"github.com/Icinga/icingadb/webhook"
 	"github.com/go-redis/redis"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
@@ -28,6 +33,12 @@ var mysqlObservers = func() (mysqlObservers map[string]prometheus.Observer) {
 	return
 }()
 
+// historyFields are the columns of the history table, which every history type writes to.
+var historyFields = []string{
+	"id", "environment_id", "endpoint_id", "object_type", "host_id", "service_id", "notification_history_id",
+	"state_history_id", "downtime_history_id", "comment_history_id", "flapping_history_id", "event_type", "event_time",
+}
+
 var historyCounter = make(map[string]int)
 var historyCounterLock = sync.Mutex{}
 
@@ -49,36 +60,39 @@ func logHistoryCounters() {
 	}
 }
 
-func StartHistoryWorkers(super *supervisor.Supervisor) {
-	workers := []func(supervisor2 *supervisor.Supervisor){
-		notificationHistoryWorker,
-		userNotificationHistoryWorker,
-		stateHistoryWorker,
-		downtimeHistoryWorker,
-		commentHistoryWorker,
-		flappingHistoryWorker,
+// StartHistoryWorkers starts the history workers and the deletion of outdated history,
+// which run while chHA says we're responsible. The workers read the history streams as the given Redis stream consumer.
+func StartHistoryWorkers(super *supervisor.Supervisor, chHA <-chan int, consumer string, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
+	workers := map[string]func(*supervisor.Supervisor, *connection.StreamConsumer, *webhook.Dispatcher, *eventstream.Broker){
+		"notification":     notificationHistoryWorker,
+		"usernotification": userNotificationHistoryWorker,
+		"state":            stateHistoryWorker,
+		"downtime":         downtimeHistoryWorker,
+		"comment":          commentHistoryWorker,
+		"flapping":         flappingHistoryWorker,
 	}
 
-	for workerId := range workers {
-		worker := workers[workerId]
-		go func() {
-			for {
-				worker(super)
-			}
-		}()
+	run := make([]func(), 0, len(workers))
+	for historyType, worker := range workers {
+		worker := worker
+		stream := super.Rdbw.NewStreamConsumer("icinga:history:stream:"+historyType, consumer)
+		run = append(run, func() { worker(super, stream, dispatcher, broker) })
 	}
 
+	run = append(run, retentionWorker(super))
+
+	go ha.RunWhileResponsible(super, chHA, run...)
+
 	go logHistoryCounters()
 }
 
-func notificationHistoryWorker(super *supervisor.Supervisor) {
+func notificationHistoryWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	statements := []string{
-		`REPLACE INTO notification_history (id, environment_id, endpoint_id, object_type, host_id, service_id, notification_id, type,` +
-			"send_time, state, previous_hard_state, author, `text`, users_notified)" +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-		`REPLACE INTO history (id, environment_id, endpoint_id, object_type, host_id, service_id, notification_history_id,` +
-			`state_history_id, downtime_history_id, comment_history_id, flapping_history_id, event_type, event_time)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		super.Dbw.BuildUpsert("notification_history", []string{
+			"id", "environment_id", "endpoint_id", "object_type", "host_id", "service_id", "notification_id", "type",
+			"send_time", "state", "previous_hard_state", "author", "text", "users_notified",
+		}, "id"),
+		super.Dbw.BuildUpsert("history", historyFields, "id"),
 	}
 
 	dataFunctions := []func(values map[string]interface{}) []interface{}{
@@ -126,13 +140,14 @@ func notificationHistoryWorker(super *supervisor.Supervisor) {
 		},
 	}
 
-	historyWorker(super, "notification", statements, dataFunctions, mysqlObservers["notification"])
+	historyWorker(super, stream, "notification", statements, dataFunctions, mysqlObservers["notification"], dispatcher, broker)
 }
 
-func userNotificationHistoryWorker(super *supervisor.Supervisor) {
+func userNotificationHistoryWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	statements := []string{
-		`REPLACE INTO user_notification_history (id, environment_id, notification_history_id, user_id)` +
-			`VALUES (?,?,?,?)`,
+		super.Dbw.BuildUpsert("user_notification_history", []string{
+			"id", "environment_id", "notification_history_id", "user_id",
+		}, "id"),
 	}
 
 	dataFunctions := []func(values map[string]interface{}) []interface{}{
@@ -150,17 +165,17 @@ func userNotificationHistoryWorker(super *supervisor.Supervisor) {
 		},
 	}
 
-	historyWorker(super, "usernotification", statements, dataFunctions, mysqlObservers["usernotification"])
+	historyWorker(super, stream, "usernotification", statements, dataFunctions, mysqlObservers["usernotification"], dispatcher, broker)
 }
 
-func stateHistoryWorker(super *supervisor.Supervisor) {
+func stateHistoryWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	statements := []string{
-		`REPLACE INTO state_history (id, environment_id, endpoint_id, object_type, host_id, service_id, event_time, state_type,` +
-			`soft_state, hard_state, previous_soft_state, previous_hard_state, attempt, output, long_output, max_check_attempts, check_source)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-		`REPLACE INTO history (id, environment_id, endpoint_id, object_type, host_id, service_id, notification_history_id,` +
-			`state_history_id, downtime_history_id, comment_history_id, flapping_history_id, event_type, event_time)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		super.Dbw.BuildUpsert("state_history", []string{
+			"id", "environment_id", "endpoint_id", "object_type", "host_id", "service_id", "event_time", "state_type",
+			"soft_state", "hard_state", "previous_soft_state", "previous_hard_state", "attempt", "output", "long_output",
+			"max_check_attempts", "check_source",
+		}, "id"),
+		super.Dbw.BuildUpsert("history", historyFields, "id"),
 	}
 
 	dataFunctions := []func(values map[string]interface{}) []interface{}{
@@ -217,17 +232,17 @@ func stateHistoryWorker(super *supervisor.Supervisor) {
 		},
 	}
 
-	historyWorker(super, "state", statements, dataFunctions, mysqlObservers["state"])
+	historyWorker(super, stream, "state", statements, dataFunctions, mysqlObservers["state"], dispatcher, broker)
 }
 
-func downtimeHistoryWorker(super *supervisor.Supervisor) {
+func downtimeHistoryWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	statements := []string{
-		`REPLACE INTO downtime_history (downtime_id, environment_id, endpoint_id, triggered_by_id, object_type, host_id, service_id, entry_time,` +
-			`author, comment, is_flexible, flexible_duration, scheduled_start_time, scheduled_end_time, start_time, end_time, has_been_cancelled, trigger_time, cancel_time)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-		`REPLACE INTO history (id, environment_id, endpoint_id, object_type, host_id, service_id, notification_history_id,` +
-			`state_history_id, downtime_history_id, comment_history_id, flapping_history_id, event_type, event_time)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		super.Dbw.BuildUpsert("downtime_history", []string{
+			"downtime_id", "environment_id", "endpoint_id", "triggered_by_id", "object_type", "host_id", "service_id",
+			"entry_time", "author", "comment", "is_flexible", "flexible_duration", "scheduled_start_time",
+			"scheduled_end_time", "start_time", "end_time", "has_been_cancelled", "trigger_time", "cancel_time",
+		}, "downtime_id"),
+		super.Dbw.BuildUpsert("history", historyFields, "id"),
 	}
 
 	dataFunctions := []func(values map[string]interface{}) []interface{}{
@@ -293,17 +308,16 @@ func downtimeHistoryWorker(super *supervisor.Supervisor) {
 		},
 	}
 
-	historyWorker(super, "downtime", statements, dataFunctions, mysqlObservers["downtime"])
+	historyWorker(super, stream, "downtime", statements, dataFunctions, mysqlObservers["downtime"], dispatcher, broker)
 }
 
-func commentHistoryWorker(super *supervisor.Supervisor) {
+func commentHistoryWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	statements := []string{
-		`REPLACE INTO comment_history (comment_id, environment_id, endpoint_id, object_type, host_id, service_id, entry_time, author,` +
-			`comment, entry_type, is_persistent, is_sticky, expire_time, remove_time, has_been_removed)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-		`REPLACE INTO history (id, environment_id, endpoint_id, object_type, host_id, service_id, notification_history_id,` +
-			`state_history_id, downtime_history_id, comment_history_id, flapping_history_id, event_type, event_time)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		super.Dbw.BuildUpsert("comment_history", []string{
+			"comment_id", "environment_id", "endpoint_id", "object_type", "host_id", "service_id", "entry_time", "author",
+			"comment", "entry_type", "is_persistent", "is_sticky", "expire_time", "remove_time", "has_been_removed",
+		}, "comment_id"),
+		super.Dbw.BuildUpsert("history", historyFields, "id"),
 	}
 
 	dataFunctions := []func(values map[string]interface{}) []interface{}{
@@ -364,17 +378,16 @@ func commentHistoryWorker(super *supervisor.Supervisor) {
 		},
 	}
 
-	historyWorker(super, "comment", statements, dataFunctions, mysqlObservers["comment"])
+	historyWorker(super, stream, "comment", statements, dataFunctions, mysqlObservers["comment"], dispatcher, broker)
 }
 
-func flappingHistoryWorker(super *supervisor.Supervisor) {
+func flappingHistoryWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	statements := []string{
-		`REPLACE INTO flapping_history (id, environment_id, endpoint_id, object_type, host_id, service_id, event_time,` +
-			`percent_state_change, flapping_threshold_low, flapping_threshold_high)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?)`,
-		`REPLACE INTO history (id, environment_id, endpoint_id, object_type, host_id, service_id, notification_history_id,` +
-			`state_history_id, downtime_history_id, comment_history_id, flapping_history_id, event_type, event_time)` +
-			`VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		super.Dbw.BuildUpsert("flapping_history", []string{
+			"id", "environment_id", "endpoint_id", "object_type", "host_id", "service_id", "event_time",
+			"percent_state_change", "flapping_threshold_low", "flapping_threshold_high",
+		}, "id"),
+		super.Dbw.BuildUpsert("history", historyFields, "id"),
 	}
 
 	dataFunctions := []func(values map[string]interface{}) []interface{}{
@@ -427,24 +440,23 @@ func flappingHistoryWorker(super *supervisor.Supervisor) {
 		},
 	}
 
-	historyWorker(super, "flapping", statements, dataFunctions, mysqlObservers["flapping"])
+	historyWorker(super, stream, "flapping", statements, dataFunctions, mysqlObservers["flapping"], dispatcher, broker)
 }
 
-func historyWorker(super *supervisor.Supervisor, historyType string, preparedStatements []string, dataFunctions []func(map[string]interface{}) []interface{}, observer prometheus.Observer) {
+func historyWorker(super *supervisor.Supervisor, stream *connection.StreamConsumer, historyType string, preparedStatements []string, dataFunctions []func(map[string]interface{}) []interface{}, observer prometheus.Observer, dispatcher *webhook.Dispatcher, broker *eventstream.Broker) {
 	if super.EnvId == nil {
 		log.Debug(historyType + "History: Waiting for EnvId to be set")
 		time.Sleep(time.Second)
 		return
 	}
 
-	result := super.Rdbw.XRead(&redis.XReadArgs{Block: 0, Count: 1000, Streams: []string{"icinga:history:stream:" + historyType, "0"}})
-	streams, err := result.Result()
+	// Don't block forever, so that a shutdown is noticed in time.
+	entries, err := stream.Read(1000, time.Second)
 	if err != nil {
 		super.ChErr <- err
 		return
 	}
 
-	entries := streams[0].Messages
 	if len(entries) == 0 {
 		return
 	}
@@ -457,27 +469,41 @@ func historyWorker(super *supervisor.Supervisor, historyType string, preparedSta
 		storedEntryIds = append(storedEntryIds, state.ID)
 	}
 
-	for {
-		errTx := super.Dbw.SqlTransaction(false, true, false, func(tx connection.DbTransaction) error {
-			for i, state := range entries {
-				for statementIndex, statement := range preparedStatements {
-					_, errExec := super.Dbw.SqlExecTx(
-						tx,
-						observer,
-						statement,
-						dataFunctions[statementIndex](state.Values)...,
-					)
+	// Convert the entries before the transaction, so that an entry with unexpected values doesn't stop the others.
+	rows := make([]historyRow, 0, len(entries))
+	for _, entry := range entries {
+		row := historyRow{entry: entry, data: make([][]interface{}, len(dataFunctions))}
+		err := deadletter.Catch(func() {
+			for i, dataFunction := range dataFunctions {
+				row.data[i] = dataFunction(entry.Values)
+			}
+		})
 
-					if errExec != nil {
-						log.WithFields(log.Fields{
-							"context": historyType + "History",
-							"values":  state.Values,
-						}).Error(errExec)
+		if err != nil {
+			if !sendDeadLetter(super, historyType, entry, err) {
+				return
+			}
 
-						entries = removeEntryFromEntriesSlice(entries, i)
+			brokenEntries++
+			continue
+		}
+
+		rows = append(rows, row)
+	}
 
-						brokenEntries++
+	for {
+		failed := -1
+		errTx := super.Dbw.SqlTransaction(false, true, false, func(tx connection.DbTransaction) error {
+			if err := super.Dbw.VerifyFence(tx); err != nil {
+				return err
+			}
 
+			failed = -1
+			for i, row := range rows {
+				for statementIndex, statement := range preparedStatements {
+					_, errExec := super.Dbw.SqlExecTx(tx, observer, statement, row.data[statementIndex]...)
+					if errExec != nil {
+						failed = i
 						return errExec
 					}
 				}
@@ -486,17 +512,46 @@ func historyWorker(super *supervisor.Supervisor, historyType string, preparedSta
 			return nil
 		})
 
-		if errTx != nil {
+		if errTx == connection.ErrFenced {
+			// Another instance took over, it will sync the stream instead.
 			log.WithFields(log.Fields{
 				"context": historyType + "History",
-			}).Error(errTx)
-		} else {
+			}).Info("Not responsible anymore, leaving entries pending")
+			time.Sleep(time.Second)
+			return
+		}
+
+		if errTx == nil {
 			break
 		}
+
+		if failed < 0 {
+			// Not caused by a particular entry, e.g. a lost connection. Don't retry right away.
+			log.WithFields(log.Fields{
+				"context": historyType + "History",
+			}).Error(errTx)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// Retry the others without the entry which failed.
+		if !sendDeadLetter(super, historyType, rows[failed].entry, errTx) {
+			return
+		}
+
+		rows = append(rows[:failed], rows[failed+1:]...)
+		brokenEntries++
 	}
 
-	//Delete synced entries from redis stream
-	super.Rdbw.XDel("icinga:history:stream:"+historyType, storedEntryIds...)
+	if len(preparedStatements) > 1 {
+		publishEvents(dispatcher, broker, rows)
+	}
+
+	//Acknowledge and delete synced entries from redis stream
+	if err := stream.Ack(storedEntryIds...); err != nil {
+		super.ChErr <- err
+		return
+	}
 
 	count := len(storedEntryIds) - brokenEntries
 
@@ -508,8 +563,38 @@ func historyWorker(super *supervisor.Supervisor, historyType string, preparedSta
 	log.Debugf("%d %s history entries broken", brokenEntries, historyType)
 }
 
-// removeEntryFromEntriesSlice removes one redis.XMessage at given index from given slice and returns the resulting slice.
-func removeEntryFromEntriesSlice(s []redis.XMessage, i int) []redis.XMessage {
-	s[len(s)-1], s[i] = s[i], s[len(s)-1]
-	return s[:len(s)-1]
+// historyRow is a history stream entry converted to the arguments of the history statements.
+type historyRow struct {
+	entry redis.XMessage
+	data  [][]interface{}
+}
+
+// publishEvents posts the synced rows to the webhooks and the event stream as events,
+// by the values written to the history table.
+func publishEvents(dispatcher *webhook.Dispatcher, broker *eventstream.Broker, rows []historyRow) {
+	for _, row := range rows {
+		history := row.data[len(row.data)-1]
+		eventType, _ := history[11].(string)
+		eventTimeMs, _ := history[12].(string)
+		ms, _ := strconv.ParseInt(eventTimeMs, 10, 64)
+
+		eventTime := time.Unix(0, ms*int64(time.Millisecond))
+
+		dispatcher.Dispatch(eventType, eventTime, row.entry.Values)
+		broker.PublishHistory(eventType, eventTime, row.entry.Values)
+	}
+}
+
+// sendDeadLetter moves entry of the historyType stream to the dead-letter stream.
+// It returns false if that failed, after reporting it to super.
+func sendDeadLetter(super *supervisor.Supervisor, historyType string, entry redis.XMessage, err error) bool {
+	errSend := deadletter.Send(
+		super.Rdbw, config.GetIcingadbInfo().DeadLetterStream, "icinga:history:stream:"+historyType, entry, err,
+	)
+	if errSend != nil {
+		super.ChErr <- errSend
+		return false
+	}
+
+	return true
 }
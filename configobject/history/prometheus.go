@@ -0,0 +1,16 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package history
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var HistoryRetentionDeletedTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "history_retention_deleted_total",
+		Help: "Outdated history entries deleted per history type",
+	},
+	[]string{"type"},
+)
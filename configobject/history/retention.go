@@ -0,0 +1,166 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package history
+
+import (
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// retentionTable describes how to find the outdated rows of a history type.
+type retentionTable struct {
+	// table is the *_history table.
+	table string
+	// idColumn is the primary key of table.
+	idColumn string
+	// historyColumn is the column of the history table referencing idColumn.
+	historyColumn string
+	// timeColumn is when a row is over. Rows with NULL, e.g. comments not removed yet, are kept.
+	timeColumn string
+	// dependents are tables with a column referencing idColumn, whose rows are deleted as well.
+	dependents map[string]string
+}
+
+// retentionTables are the history types which can be pruned. User notifications go with their notification.
+var retentionTables = map[string]retentionTable{
+	"state": {
+		table: "state_history", idColumn: "id", historyColumn: "state_history_id", timeColumn: "event_time",
+	},
+	"notification": {
+		table: "notification_history", idColumn: "id", historyColumn: "notification_history_id", timeColumn: "send_time",
+		dependents: map[string]string{"user_notification_history": "notification_history_id"},
+	},
+	"downtime": {
+		table: "downtime_history", idColumn: "downtime_id", historyColumn: "downtime_history_id",
+		timeColumn: "COALESCE(cancel_time, end_time)",
+	},
+	"comment": {
+		table: "comment_history", idColumn: "comment_id", historyColumn: "comment_history_id", timeColumn: "remove_time",
+	},
+	"flapping": {
+		table: "flapping_history", idColumn: "id", historyColumn: "flapping_history_id", timeColumn: "event_time",
+	},
+}
+
+var retentionObservers = struct {
+	selectOutdated prometheus.Observer
+	delete         prometheus.Observer
+}{
+	connection.DbIoSeconds.WithLabelValues("mysql", "select outdated history"),
+	connection.DbIoSeconds.WithLabelValues("mysql", "delete outdated history"),
+}
+
+// retentionWorker returns a worker deleting outdated history every config.HistoryRetentionInfo.Interval.
+// It's meant to be run by ha.RunWhileResponsible, so it returns quickly unless there's something to do.
+func retentionWorker(super *supervisor.Supervisor) func() {
+	var lastRun time.Time
+
+	return func() {
+		info := config.GetHistoryRetentionInfo()
+		if super.EnvId == nil || time.Since(lastRun) < info.Interval {
+			time.Sleep(time.Second)
+			return
+		}
+
+		lastRun = time.Now()
+
+		for historyType, table := range retentionTables {
+			days := info.Days(historyType)
+			if days == 0 {
+				continue
+			}
+
+			cutoff := time.Now().AddDate(0, 0, -days)
+			if err := pruneHistory(super, historyType, table, cutoff, info.BatchSize); err != nil {
+				if err != connection.ErrFenced {
+					log.WithFields(log.Fields{
+						"context": "HistoryRetention",
+						"type":    historyType,
+					}).Error(err)
+				}
+
+				return
+			}
+		}
+	}
+}
+
+// pruneHistory deletes the rows of table over before cutoff in batches of batchSize
+// together with the rows referencing them, until there are none left or super is shutting down.
+func pruneHistory(super *supervisor.Supervisor, historyType string, table retentionTable, cutoff time.Time, batchSize int) error {
+	// The history times are milliseconds since the epoch.
+	cutoffMs := cutoff.UnixNano() / int64(time.Millisecond)
+	deleted := 0
+
+	for !super.ShuttingDown() {
+		rows, err := super.Dbw.SqlFetchAll(
+			retentionObservers.selectOutdated,
+			fmt.Sprintf(
+				"SELECT %s FROM %s WHERE environment_id = ? AND %s < ? LIMIT %d",
+				table.idColumn, table.table, table.timeColumn, batchSize,
+			),
+			super.EnvId, cutoffMs,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row[0])
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+		queries := []string{
+			fmt.Sprintf("DELETE FROM history WHERE %s IN (%s)", table.historyColumn, placeholders),
+		}
+		for dependent, column := range table.dependents {
+			queries = append(queries, fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", dependent, column, placeholders))
+		}
+		queries = append(queries, fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", table.table, table.idColumn, placeholders))
+
+		err = super.Dbw.SqlTransaction(false, true, false, func(tx connection.DbTransaction) error {
+			if err := super.Dbw.VerifyFence(tx); err != nil {
+				return err
+			}
+
+			for _, query := range queries {
+				if _, err := super.Dbw.SqlExecTxQuiet(tx, retentionObservers.delete, query, ids...); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		deleted += len(ids)
+		HistoryRetentionDeletedTotal.WithLabelValues(historyType).Add(float64(len(ids)))
+
+		if len(ids) < batchSize {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		log.WithFields(log.Fields{
+			"context": "HistoryRetention",
+			"type":    historyType,
+			"before":  cutoff,
+		}).Infof("Deleted %d outdated %s history entries", deleted, historyType)
+	}
+
+	return nil
+}
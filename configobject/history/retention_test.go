@@ -0,0 +1,66 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package history
+
+import (
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/schema"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var retentionTestObserver = connection.DbIoSeconds.WithLabelValues("mysql", "test")
+
+func TestPruneHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dialect, err := connection.GetDialect("sqlite")
+	require.NoError(t, err)
+
+	dbw, err := connection.NewDBWrapperWithDialect(dialect, filepath.Join(dir, "icingadb.db"), 4)
+	require.NoError(t, err)
+
+	dbw.WaitForConnection()
+	require.NoError(t, schema.Migrate(dbw))
+
+	super := &supervisor.Supervisor{Dbw: dbw, EnvId: []byte("env")}
+	now := time.Now()
+
+	for i, eventTime := range []time.Time{now.AddDate(0, 0, -3), now.AddDate(0, 0, -2), now} {
+		id := []byte{byte(i)}
+		eventTimeMs := eventTime.UnixNano() / int64(time.Millisecond)
+
+		_, err := dbw.SqlExec(
+			retentionTestObserver,
+			"INSERT INTO state_history (id, environment_id, object_type, host_id, event_time, state_type, soft_state, "+
+				"hard_state, previous_soft_state, previous_hard_state, attempt, max_check_attempts) "+
+				"VALUES (?, ?, 'host', ?, ?, 'hard', 0, 0, 0, 0, 1, 1)",
+			id, super.EnvId, id, eventTimeMs,
+		)
+		require.NoError(t, err)
+
+		_, err = dbw.SqlExec(
+			retentionTestObserver,
+			"INSERT INTO history (id, environment_id, object_type, host_id, state_history_id, event_type, event_time) "+
+				"VALUES (?, ?, 'host', ?, ?, 'state_change', ?)",
+			id, super.EnvId, id, id, eventTimeMs,
+		)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, pruneHistory(super, "state", retentionTables["state"], now.AddDate(0, 0, -1), 1))
+
+	for _, table := range []string{"state_history", "history"} {
+		rows, err := dbw.SqlFetchAll(retentionTestObserver, "SELECT id FROM "+table)
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{[]byte{2}}}, rows, "only the recent %s must be kept", table)
+	}
+}
@@ -0,0 +1,90 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package names resolves the IDs of hosts and services to their names, for consumers outside of the database.
+package names
+
+import (
+	"errors"
+	"github.com/Icinga/icingadb/connection"
+	"sync"
+	"time"
+)
+
+// ErrUnknown is returned by Resolver.Resolve for objects not (yet) in the database.
+var ErrUnknown = errors.New("unknown object")
+
+// cacheTTL is how long resolved names are cached. Renames are rare, so this only limits how long they go unnoticed.
+const cacheTTL = 5 * time.Minute
+
+// maxCacheSize is the number of cached names after which the cache is reset early.
+const maxCacheSize = 100000
+
+var mysqlObserver = connection.DbIoSeconds.WithLabelValues("mysql", "select object names")
+
+type objectNames struct {
+	host    string
+	service string
+}
+
+// Resolver resolves and caches the names of hosts and services. It's safe for concurrent use.
+type Resolver struct {
+	dbw *connection.DBWrapper
+
+	mu          sync.Mutex
+	cache       map[string]objectNames
+	cachedSince time.Time
+}
+
+// NewResolver returns a Resolver looking up names in dbw.
+func NewResolver(dbw *connection.DBWrapper) *Resolver {
+	return &Resolver{dbw: dbw}
+}
+
+// Resolve returns the name of the host with the given ID if objectType is "host",
+// or that of the service with the given ID and its host if objectType is "service".
+func (r *Resolver) Resolve(objectType string, id []byte) (host string, service string, err error) {
+	key := objectType + string(id)
+
+	r.mu.Lock()
+	if time.Since(r.cachedSince) > cacheTTL || len(r.cache) >= maxCacheSize {
+		r.cache = map[string]objectNames{}
+		r.cachedSince = time.Now()
+	}
+
+	cached, ok := r.cache[key]
+	r.mu.Unlock()
+
+	if ok {
+		return cached.host, cached.service, nil
+	}
+
+	var query string
+	switch objectType {
+	case "host":
+		query = "SELECT name FROM host WHERE id = ?"
+	case "service":
+		query = "SELECT h.name, s.name FROM service s JOIN host h ON h.id = s.host_id WHERE s.id = ?"
+	default:
+		return "", "", ErrUnknown
+	}
+
+	rows, err := r.dbw.SqlFetchAllQuiet(mysqlObserver, query, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(rows) == 0 {
+		return "", "", ErrUnknown
+	}
+
+	host, _ = rows[0][0].(string)
+	if objectType == "service" {
+		service, _ = rows[0][1].(string)
+	}
+
+	r.mu.Lock()
+	r.cache[key] = objectNames{host: host, service: service}
+	r.mu.Unlock()
+
+	return host, service, nil
+}
@@ -67,8 +67,8 @@ func init() {
 		PrimaryMySqlField: "id",
 		Factory:           NewIconImage,
 		HasChecksum:       false,
-		BulkInsertStmt:    connection.NewBulkInsertStmt(name, Fields),
+		BulkInsertStmt:    connection.NewBulkInsertStmt(name, Fields, "environment_id", "id"),
 		BulkDeleteStmt:    connection.NewBulkDeleteStmt(name, "id"),
-		BulkUpdateStmt:    connection.NewBulkUpdateStmt(name, Fields),
+		BulkUpdateStmt:    connection.NewBulkUpdateStmt(name, Fields, "environment_id", "id"),
 	}
 }
@@ -14,3 +14,11 @@ var StateSyncsTotal = promauto.NewCounterVec(
 	},
 	[]string{"objecttype"},
 )
+
+var PerfdataParseErrorsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "perfdata_parse_errors_total",
+		Help: "States with invalid performance data per object type, the valid metrics are synced anyway",
+	},
+	[]string{"objecttype"},
+)
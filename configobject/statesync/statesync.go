@@ -4,14 +4,23 @@ package statesync
 
 import (
 	"encoding/hex"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
 	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/deadletter"
+	"github.com/Icinga/icingadb/eventstream"
+	"github.com/Icinga/icingadb/ha"
+	"github.com/Icinga/icingadb/perfdata"
 	"github.com/Icinga/icingadb/supervisor"
+	"github.com/Icinga/icingadb/tsdb"
 	"github.com/Icinga/icingadb/utils"
 	"github.com/go-redis/redis"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"strconv"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // syncCounter counts on how many host/service states have synced since the last logSyncCounters().
@@ -28,19 +37,43 @@ var mysqlObservers = func() (mysqlObservers map[string]prometheus.Observer) {
 	return
 }()
 
-// StartStateSync starts the sync goroutines for hosts and services.
-func StartStateSync(super *supervisor.Supervisor) {
-	go func() {
-		for {
-			syncStates(super, "host")
-		}
-	}()
+var perfdataObservers = func() (perfdataObservers map[string]prometheus.Observer) {
+	perfdataObservers = map[string]prometheus.Observer{}
 
-	go func() {
-		for {
-			syncStates(super, "service")
-		}
-	}()
+	for _, objectType := range [2]string{"host", "service"} {
+		perfdataObservers[objectType] = connection.DbIoSeconds.WithLabelValues("mysql", "replace into "+objectType+"_perfdata")
+	}
+
+	return
+}()
+
+// stateFields are the columns of the {host,service}_state tables following the object id.
+var stateFields = []string{
+	"environment_id", "state_type", "soft_state", "hard_state", "previous_hard_state", "attempt", "severity", "output",
+	"long_output", "performance_data", "check_commandline", "is_problem", "is_handled", "is_reachable", "is_flapping",
+	"is_acknowledged", "acknowledgement_comment_id", "in_downtime", "execution_time", "latency", "timeout",
+	"check_source", "last_update", "last_state_change", "next_check", "next_update",
+}
+
+// perfdataFields are the columns of the {host,service}_perfdata tables following the object id.
+var perfdataFields = []string{"environment_id", "label", "value", "unit", "warn", "crit", "min", "max"}
+
+// maxPerfdataLabelLength is the length of the label column.
+const maxPerfdataLabelLength = 255
+
+// StartStateSync starts the sync goroutines for hosts and services. They run while chHA
+// (see ha.ListenerTypeResponsibility) says we're responsible and stop after their current batch otherwise.
+// They read the state streams as the given Redis stream consumer, pass the perfdata of synced states to exporter
+// and publish the synced states to broker.
+func StartStateSync(super *supervisor.Supervisor, chHA <-chan int, consumer string, exporter *tsdb.Exporter, broker *eventstream.Broker) {
+	hosts := super.Rdbw.NewStreamConsumer("icinga:state:stream:host", consumer)
+	services := super.Rdbw.NewStreamConsumer("icinga:state:stream:service", consumer)
+
+	go ha.RunWhileResponsible(
+		super, chHA,
+		func() { syncStates(super, "host", hosts, exporter, broker) },
+		func() { syncStates(super, "service", services, exporter, broker) },
+	)
 
 	go logSyncCounters()
 }
@@ -62,21 +95,20 @@ func logSyncCounters() {
 }
 
 // syncStates tries to sync the states of given object type every second.
-func syncStates(super *supervisor.Supervisor, objectType string) {
+func syncStates(super *supervisor.Supervisor, objectType string, stream *connection.StreamConsumer, exporter *tsdb.Exporter, broker *eventstream.Broker) {
 	if super.EnvId == nil {
 		log.Debug("StateSync: Waiting for EnvId to be set")
 		time.Sleep(time.Second)
 		return
 	}
 
-	result := super.Rdbw.XRead(&redis.XReadArgs{Block: 0, Count: 1000, Streams: []string{"icinga:state:stream:" + objectType, "0"}})
-	streams, err := result.Result()
+	// Don't block forever, so that a shutdown is noticed in time.
+	states, err := stream.Read(1000, time.Second)
 	if err != nil {
 		super.ChErr <- err
 		return
 	}
 
-	states := streams[0].Messages
 	if len(states) == 0 {
 		return
 	}
@@ -89,81 +121,106 @@ func syncStates(super *supervisor.Supervisor, objectType string) {
 		storedStateIds = append(storedStateIds, state.ID)
 	}
 
+	statement := super.Dbw.BuildUpsert(objectType+"_state", append([]string{objectType + "_id"}, stateFields...), objectType+"_id")
+	deletePerfdata := fmt.Sprintf("DELETE FROM %s_perfdata WHERE %s_id = ?", objectType, objectType)
+	insertPerfdata := super.Dbw.BuildUpsert(
+		objectType+"_perfdata", append([]string{objectType + "_id"}, perfdataFields...), objectType+"_id", "label",
+	)
+
+	// Convert the states before the transaction, so that a state with unexpected values doesn't stop the others.
+	rows := make([]stateRow, 0, len(states))
+	for _, state := range states {
+		var row stateRow
+		var err error
+		if errCatch := deadletter.Catch(func() { row, err = newStateRow(super, objectType, state) }); errCatch != nil {
+			err = errCatch
+		}
+
+		if err != nil {
+			if !sendDeadLetter(super, objectType, state, err) {
+				return
+			}
+
+			brokenStates++
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
 	for {
+		failed := -1
 		errTx := super.Dbw.SqlTransaction(false, true, false, func(tx connection.DbTransaction) error {
-			for i, state := range states {
-				values := state.Values
-				id, _ := hex.DecodeString(values["id"].(string))
+			if err := super.Dbw.VerifyFence(tx); err != nil {
+				return err
+			}
 
-				var acknowledgementCommentId []byte
-				if values["acknowledgement_comment_id"] != nil {
-					acknowledgementCommentId, _ = hex.DecodeString(values["acknowledgement_comment_id"].(string))
+			failed = -1
+			for i, row := range rows {
+				if _, errExec := super.Dbw.SqlExecTx(tx, mysqlObservers[objectType], statement, row.data...); errExec != nil {
+					failed = i
+					return errExec
 				}
 
-				_, errExec := super.Dbw.SqlExecTx(
-					tx,
-					mysqlObservers[objectType],
-					`REPLACE INTO `+objectType+`_state (`+objectType+`_id, environment_id, state_type, soft_state, hard_state, previous_hard_state, attempt, severity, output, long_output, performance_data,`+
-						`check_commandline, is_problem, is_handled, is_reachable, is_flapping, is_acknowledged, acknowledgement_comment_id,`+
-						`in_downtime, execution_time, latency, timeout, check_source, last_update, last_state_change, next_check, next_update) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-					id,
-					super.EnvId,
-					redisStateTypeToDBStateType(values["state_type"]),
-					values["state"],
-					values["hard_state"],
-					values["previous_hard_state"],
-					values["check_attempt"],
-					redisIntToDBInt(values["severity"]),
-					values["output"],
-					values["long_output"],
-					values["performance_data"],
-					values["commandline"],
-					utils.JSONBooleanToDBBoolean(values["is_problem"]),
-					utils.JSONBooleanToDBBoolean(values["is_handled"]),
-					utils.JSONBooleanToDBBoolean(values["is_reachable"]),
-					utils.JSONBooleanToDBBoolean(values["is_flapping"]),
-					utils.JSONBooleanToDBBoolean(values["is_acknowledged"]),
-					acknowledgementCommentId,
-					utils.JSONBooleanToDBBoolean(values["in_downtime"]),
-					values["execution_time"],
-					redisIntToDBInt(values["latency"]),
-					redisIntToDBInt(values["check_timeout"]),
-					values["check_source"],
-					values["last_update"],
-					values["last_state_change"],
-					values["next_check"],
-					values["next_update"],
-				)
-
-				if errExec != nil {
-					log.WithFields(log.Fields{
-						"context":    "StateSync",
-						"objectType": objectType,
-						"state":      values,
-					}).Error(errExec)
-
-					states = removeStateFromStatesSlice(states, i)
-
-					brokenStates++
-
+				// The perfdata of the previous state is replaced as a whole, as labels may come and go.
+				if _, errExec := super.Dbw.SqlExecTxQuiet(tx, perfdataObservers[objectType], deletePerfdata, row.data[0]); errExec != nil {
+					failed = i
 					return errExec
 				}
+
+				for _, metric := range row.perfdata {
+					if _, errExec := super.Dbw.SqlExecTxQuiet(tx, perfdataObservers[objectType], insertPerfdata, metric...); errExec != nil {
+						failed = i
+						return errExec
+					}
+				}
 			}
 
 			return nil
 		})
 
-		if errTx != nil {
+		if errTx == connection.ErrFenced {
+			// Another instance took over, it will sync the stream instead.
 			log.WithFields(log.Fields{
 				"context": "StateSync",
-			}).Error(errTx)
-		} else {
+			}).Info("Not responsible anymore, leaving entries pending")
+			time.Sleep(time.Second)
+			return
+		}
+
+		if errTx == nil {
 			break
 		}
+
+		if failed < 0 {
+			// Not caused by a particular state, e.g. a lost connection. Don't retry right away.
+			log.WithFields(log.Fields{
+				"context": "StateSync",
+			}).Error(errTx)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// Retry the others without the state which failed.
+		if !sendDeadLetter(super, objectType, rows[failed].state, errTx) {
+			return
+		}
+
+		rows = append(rows[:failed], rows[failed+1:]...)
+		brokenStates++
 	}
 
-	//Delete synced states from redis stream
-	super.Rdbw.XDel("icinga:state:stream:"+objectType, storedStateIds...)
+	for _, row := range rows {
+		id := row.data[0].([]byte)
+		exporter.Export(objectType, id, row.metrics, row.checkTime)
+		broker.PublishState(objectType, id, row.state, row.checkTime)
+	}
+
+	//Acknowledge and delete synced states from redis stream
+	if err := stream.Ack(storedStateIds...); err != nil {
+		super.ChErr <- err
+		return
+	}
 
 	log.Debugf("%d %s state synced", len(storedStateIds)-brokenStates, objectType)
 	log.Debugf("%d %s state broken", brokenStates, objectType)
@@ -173,10 +230,158 @@ func syncStates(super *supervisor.Supervisor, objectType string) {
 	StateSyncsTotal.WithLabelValues(objectType).Add(float64(len(storedStateIds)))
 }
 
-// removeStateFromStatesSlice removes one redis.XMessage at given index from given slice and returns the resulting slice.
-func removeStateFromStatesSlice(s []redis.XMessage, i int) []redis.XMessage {
-	s[len(s)-1], s[i] = s[i], s[len(s)-1]
-	return s[:len(s)-1]
+// stateRow is a state stream entry converted to the arguments of the upsert statement
+// and those of the insert statement for each perfdata metric.
+type stateRow struct {
+	state    redis.XMessage
+	data     []interface{}
+	perfdata [][]interface{}
+	// metrics and checkTime are exported and published after the state has been synced.
+	metrics   []perfdata.Metric
+	checkTime time.Time
+}
+
+// newStateRow converts state. It returns an error on malformed IDs and panics on other unexpected values.
+func newStateRow(super *supervisor.Supervisor, objectType string, state redis.XMessage) (stateRow, error) {
+	values := state.Values
+	id, err := hex.DecodeString(values["id"].(string))
+	if err != nil {
+		return stateRow{}, fmt.Errorf("invalid id: %s", err.Error())
+	}
+
+	var acknowledgementCommentId []byte
+	if values["acknowledgement_comment_id"] != nil {
+		acknowledgementCommentId, err = hex.DecodeString(values["acknowledgement_comment_id"].(string))
+		if err != nil {
+			return stateRow{}, fmt.Errorf("invalid acknowledgement_comment_id: %s", err.Error())
+		}
+	}
+
+	metrics := parsePerfdata(objectType, id, values["performance_data"])
+	perfdataRows := make([][]interface{}, 0, len(metrics))
+	for _, metric := range metrics {
+		perfdataRows = append(perfdataRows, newPerfdataRow(super, id, metric))
+	}
+
+	// The time of the check result the perfdata is from.
+	checkTime := time.Now()
+	if lastUpdate, ok := values["last_update"].(string); ok {
+		if ms, err := strconv.ParseInt(lastUpdate, 10, 64); err == nil {
+			checkTime = time.Unix(0, ms*int64(time.Millisecond))
+		}
+	}
+
+	return stateRow{state: state, metrics: metrics, checkTime: checkTime, perfdata: perfdataRows, data: []interface{}{
+		id,
+		super.EnvId,
+		redisStateTypeToDBStateType(values["state_type"]),
+		values["state"],
+		values["hard_state"],
+		values["previous_hard_state"],
+		values["check_attempt"],
+		redisIntToDBInt(values["severity"]),
+		values["output"],
+		values["long_output"],
+		values["performance_data"],
+		values["commandline"],
+		utils.JSONBooleanToDBBoolean(values["is_problem"]),
+		utils.JSONBooleanToDBBoolean(values["is_handled"]),
+		utils.JSONBooleanToDBBoolean(values["is_reachable"]),
+		utils.JSONBooleanToDBBoolean(values["is_flapping"]),
+		utils.JSONBooleanToDBBoolean(values["is_acknowledged"]),
+		acknowledgementCommentId,
+		utils.JSONBooleanToDBBoolean(values["in_downtime"]),
+		values["execution_time"],
+		redisIntToDBInt(values["latency"]),
+		redisIntToDBInt(values["check_timeout"]),
+		values["check_source"],
+		values["last_update"],
+		values["last_state_change"],
+		values["next_check"],
+		values["next_update"],
+	}}, nil
+}
+
+// parsePerfdata parses the performance data of the object with the given ID. Invalid metrics are skipped,
+// so that they don't cost the state. If a label occurs multiple times, the last one wins.
+func parsePerfdata(objectType string, id []byte, value interface{}) []perfdata.Metric {
+	text, _ := value.(string)
+	if text == "" {
+		return nil
+	}
+
+	parsed, err := perfdata.Parse(text)
+	if err != nil {
+		PerfdataParseErrorsTotal.WithLabelValues(objectType).Inc()
+		log.WithFields(log.Fields{
+			"context": "StateSync",
+			"id":      hex.EncodeToString(id),
+		}).Debug(err)
+	}
+
+	index := map[string]int{}
+	var metrics []perfdata.Metric
+
+	for _, metric := range parsed {
+		if utf8.RuneCountInString(metric.Label) > maxPerfdataLabelLength {
+			PerfdataParseErrorsTotal.WithLabelValues(objectType).Inc()
+			continue
+		}
+
+		if i, ok := index[metric.Label]; ok {
+			metrics[i] = metric
+		} else {
+			index[metric.Label] = len(metrics)
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return metrics
+}
+
+// newPerfdataRow converts metric of the object with the given ID to the arguments of the perfdata insert statement.
+func newPerfdataRow(super *supervisor.Supervisor, id []byte, metric perfdata.Metric) []interface{} {
+	return []interface{}{
+		id,
+		super.EnvId,
+		metric.Label,
+		nullableFloat(metric.Value),
+		metric.Unit,
+		nullableString(metric.Warn),
+		nullableString(metric.Crit),
+		nullableFloat(metric.Min),
+		nullableFloat(metric.Max),
+	}
+}
+
+func nullableFloat(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
+
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+// sendDeadLetter moves state of the objectType stream to the dead-letter stream.
+// It returns false if that failed, after reporting it to super.
+func sendDeadLetter(super *supervisor.Supervisor, objectType string, state redis.XMessage, err error) bool {
+	errSend := deadletter.Send(
+		super.Rdbw, config.GetIcingadbInfo().DeadLetterStream, "icinga:state:stream:"+objectType, state, err,
+	)
+	if errSend != nil {
+		super.ChErr <- errSend
+		return false
+	}
+
+	return true
 }
 
 // redisStateTypeToDBStateType converts a Icinga state type(0 for soft, 1 for hard) we got from Redis into a DB state type(soft, hard).
@@ -0,0 +1,260 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dialect hides the differences between the SQL backends supported by the DBWrapper.
+// All queries are written with ? placeholders and double quoted identifiers.
+type Dialect interface {
+	// Name returns the database type as used in the config, e.g. "mysql".
+	Name() string
+	// Open creates a new database handle for the given DSN.
+	Open(dsn string, maxOpenConns int) (*sql.DB, error)
+	// Rebind converts the ? placeholders of a query into the backend's placeholder syntax.
+	Rebind(query string) string
+	// Upsert returns a statement format with one %s for the VALUES list, which inserts fields into table and
+	// replaces rows conflicting on keys. Without keys, the first field is the key.
+	Upsert(table string, fields []string, keys []string) string
+	// IsSerializationFailure returns whether the given error signals a serialization failure or deadlock.
+	IsSerializationFailure(err error) bool
+	// IsUndefinedTable returns whether the given error signals that a queried table doesn't exist.
+	IsUndefinedTable(err error) bool
+	// ForShare returns the clause to append to a SELECT for locking the selected rows against concurrent updates
+	// until the end of the transaction.
+	ForShare() string
+}
+
+var dialects = map[string]Dialect{
+	"mysql":  &mysqlDialect{},
+	"pgsql":  &pgsqlDialect{},
+	"sqlite": &sqliteDialect{},
+}
+
+// MysqlDialect is the default dialect, used if a DBWrapper has none set.
+var MysqlDialect = dialects["mysql"]
+
+// GetDialect returns the dialect registered for the given database type.
+func GetDialect(dbType string) (Dialect, error) {
+	if dialect, ok := dialects[dbType]; ok {
+		return dialect, nil
+	}
+
+	types := make([]string, 0, len(dialects))
+	for typ := range dialects {
+		types = append(types, typ)
+	}
+
+	sort.Strings(types)
+
+	return nil, fmt.Errorf("unknown database type %s, expected one of %s", dbType, strings.Join(types, ", "))
+}
+
+// QuoteIdentifier quotes a table or column name. All dialects use ANSI quotes.
+func QuoteIdentifier(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+func quoteIdentifiers(identifiers []string) string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = QuoteIdentifier(identifier)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (d *mysqlDialect) Open(dsn string, maxOpenConns int) (*sql.DB, error) {
+	return mkMysql("mysql", dsn, maxOpenConns)
+}
+
+func (d *mysqlDialect) Rebind(query string) string {
+	return query
+}
+
+func (d *mysqlDialect) Upsert(table string, fields []string, keys []string) string {
+	return fmt.Sprintf("REPLACE INTO %s (%s) VALUES %s", QuoteIdentifier(table), quoteIdentifiers(fields), "%s")
+}
+
+func (d *mysqlDialect) IsSerializationFailure(err error) bool {
+	return isSerializationFailure(err)
+}
+
+// IsUndefinedTable checks for ER_NO_SUCH_TABLE.
+func (d *mysqlDialect) IsUndefinedTable(e error) bool {
+	err, ok := e.(*mysql.MySQLError)
+	return ok && err.Number == 1146
+}
+
+// ForShare uses the syntax which is understood by MySQL 5 as well as 8.
+func (d *mysqlDialect) ForShare() string {
+	return " LOCK IN SHARE MODE"
+}
+
+type pgsqlDialect struct{}
+
+func (d *pgsqlDialect) Name() string {
+	return "pgsql"
+}
+
+func (d *pgsqlDialect) Open(dsn string, maxOpenConns int) (*sql.DB, error) {
+	db, errConn := sql.Open("postgres", dsn)
+	if errConn != nil {
+		return nil, errConn
+	}
+
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(0)
+
+	return db, nil
+}
+
+// Rebind replaces ? with $1, $2, ... while leaving quoted strings and identifiers alone.
+func (d *pgsqlDialect) Rebind(query string) string {
+	var quote rune
+	n := 0
+	res := strings.Builder{}
+	res.Grow(len(query) + 16)
+
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			res.WriteByte('$')
+			res.WriteString(strconv.Itoa(n))
+			continue
+		}
+
+		res.WriteRune(r)
+	}
+
+	return res.String()
+}
+
+func (d *pgsqlDialect) Upsert(table string, fields []string, keys []string) string {
+	if len(keys) == 0 {
+		// ON CONFLICT () is invalid, the tables' primary key comes first.
+		keys = fields[:1]
+	}
+
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+
+	var updates []string
+	for _, field := range fields {
+		if !isKey[field] {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", QuoteIdentifier(field), QuoteIdentifier(field)))
+		}
+	}
+
+	action := "NOTHING"
+	if len(updates) > 0 {
+		action = "UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s ON CONFLICT (%s) DO %s",
+		QuoteIdentifier(table), quoteIdentifiers(fields), "%s", quoteIdentifiers(keys), action,
+	)
+}
+
+// IsSerializationFailure checks for serialization_failure and deadlock_detected.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+func (d *pgsqlDialect) IsSerializationFailure(e error) bool {
+	if err, ok := e.(*pq.Error); ok {
+		switch err.Code {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsUndefinedTable checks for undefined_table.
+func (d *pgsqlDialect) IsUndefinedTable(e error) bool {
+	err, ok := e.(*pq.Error)
+	return ok && err.Code == "42P01"
+}
+
+func (d *pgsqlDialect) ForShare() string {
+	return " FOR SHARE"
+}
+
+type sqliteDialect struct{}
+
+func (d *sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+// Open opens the SQLite database file given as DSN. Write transactions lock the database immediately and
+// concurrent writers wait for each other instead of failing right away.
+func (d *sqliteDialect) Open(dsn string, maxOpenConns int) (*sql.DB, error) {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+
+	db, errConn := sql.Open("sqlite3", "file:"+dsn+sep+"_busy_timeout=10000&_journal_mode=WAL&_txlock=immediate")
+	if errConn != nil {
+		return nil, errConn
+	}
+
+	db.SetMaxOpenConns(maxOpenConns)
+
+	return db, nil
+}
+
+func (d *sqliteDialect) Rebind(query string) string {
+	return query
+}
+
+func (d *sqliteDialect) Upsert(table string, fields []string, keys []string) string {
+	return fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES %s", QuoteIdentifier(table), quoteIdentifiers(fields), "%s")
+}
+
+// IsSerializationFailure checks for a busy or locked database, which happens if the busy timeout is exceeded.
+func (d *sqliteDialect) IsSerializationFailure(e error) bool {
+	if err, ok := e.(sqlite3.Error); ok {
+		switch err.Code {
+		case sqlite3.ErrBusy, sqlite3.ErrLocked:
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsUndefinedTable checks for the generic SQLite error with the message of a missing table.
+func (d *sqliteDialect) IsUndefinedTable(e error) bool {
+	err, ok := e.(sqlite3.Error)
+	return ok && err.Code == sqlite3.ErrError && strings.HasPrefix(err.Error(), "no such table")
+}
+
+// ForShare returns nothing, as transactions lock the whole database against other writers from the beginning anyway.
+func (d *sqliteDialect) ForShare() string {
+	return ""
+}
@@ -0,0 +1,100 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetDialect(t *testing.T) {
+	dialect, err := GetDialect("mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", dialect.Name())
+
+	dialect, err = GetDialect("pgsql")
+	require.NoError(t, err)
+	assert.Equal(t, "pgsql", dialect.Name())
+
+	_, err = GetDialect("oracle")
+	assert.Error(t, err)
+}
+
+func TestDialect_Rebind(t *testing.T) {
+	mysql, _ := GetDialect("mysql")
+	pgsql, _ := GetDialect("pgsql")
+
+	query := `SELECT "a?" FROM t WHERE x = ? AND y = '?' AND z IN (?, ?)`
+
+	assert.Equal(t, query, mysql.Rebind(query))
+	assert.Equal(t, `SELECT "a?" FROM t WHERE x = $1 AND y = '?' AND z IN ($2, $3)`, pgsql.Rebind(query))
+}
+
+func TestDialect_Upsert(t *testing.T) {
+	mysql, _ := GetDialect("mysql")
+	pgsql, _ := GetDialect("pgsql")
+
+	assert.Equal(
+		t,
+		`REPLACE INTO "host" ("id", "name") VALUES %s`,
+		mysql.Upsert("host", []string{"id", "name"}, []string{"id"}),
+	)
+
+	assert.Equal(
+		t,
+		`INSERT INTO "host" ("id", "name") VALUES %s ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`,
+		pgsql.Upsert("host", []string{"id", "name"}, []string{"id"}),
+	)
+
+	assert.Equal(
+		t,
+		`INSERT INTO "user" ("environment_id", "id") VALUES %s ON CONFLICT ("environment_id", "id") DO NOTHING`,
+		pgsql.Upsert("user", []string{"environment_id", "id"}, []string{"environment_id", "id"}),
+	)
+}
+
+func TestDBWrapper_BuildUpsert(t *testing.T) {
+	pgsql, _ := GetDialect("pgsql")
+	dbw := &DBWrapper{Dialect: pgsql}
+
+	// The state statement of statesync.
+	assert.Equal(
+		t,
+		`INSERT INTO "host_state" ("host_id", "environment_id", "soft_state") VALUES (?,?,?) `+
+			`ON CONFLICT ("host_id") DO UPDATE SET "environment_id" = EXCLUDED."environment_id", `+
+			`"soft_state" = EXCLUDED."soft_state"`,
+		dbw.BuildUpsert("host_state", []string{"host_id", "environment_id", "soft_state"}, "host_id"),
+	)
+
+	assert.Equal(
+		t,
+		dbw.BuildUpsert("host_state", []string{"host_id", "environment_id", "soft_state"}, "host_id"),
+		dbw.BuildUpsert("host_state", []string{"host_id", "environment_id", "soft_state"}),
+		"the first field must be the key if none is given",
+	)
+}
+
+func TestDialect_IsSerializationFailure(t *testing.T) {
+	pgsql, _ := GetDialect("pgsql")
+
+	assert.True(t, pgsql.IsSerializationFailure(&pq.Error{Code: "40001"}))
+	assert.True(t, pgsql.IsSerializationFailure(&pq.Error{Code: "40P01"}))
+	assert.False(t, pgsql.IsSerializationFailure(&pq.Error{Code: "23505"}))
+	assert.False(t, pgsql.IsSerializationFailure(errors.New("40001")))
+}
+
+func TestDialect_IsUndefinedTable(t *testing.T) {
+	mysqlDialect, _ := GetDialect("mysql")
+	pgsql, _ := GetDialect("pgsql")
+	sqlite, _ := GetDialect("sqlite")
+
+	assert.True(t, mysqlDialect.IsUndefinedTable(&mysql.MySQLError{Number: 1146}))
+	assert.False(t, mysqlDialect.IsUndefinedTable(&mysql.MySQLError{Number: 1142}), "missing privileges")
+	assert.True(t, pgsql.IsUndefinedTable(&pq.Error{Code: "42P01"}))
+	assert.False(t, pgsql.IsUndefinedTable(&pq.Error{Code: "42501"}), "missing privileges")
+	assert.False(t, sqlite.IsUndefinedTable(errors.New("no such table: icingadb_schema")))
+}
@@ -0,0 +1,96 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+// ErrFenced is returned by writes which have been rejected, because another instance took over.
+var ErrFenced = errors.New("instance is not responsible anymore, write rejected by fencing token")
+
+var fenceObserver = DbIoSeconds.WithLabelValues("mysql", "select fencing_token from icingadb_instance")
+
+// Fence holds the fencing token of the current responsibility term of this instance, as stored in icingadb_instance.
+// Every takeover increments the token, so writes verifying it within their transaction are rejected once
+// another instance has taken over, even if this one didn't notice yet.
+type Fence struct {
+	mu          sync.RWMutex
+	environment []byte
+	instance    []byte
+	token       int64
+}
+
+// Set starts a responsibility term of instance for environment with token.
+func (f *Fence) Set(environment []byte, instance []byte, token int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.environment = environment
+	f.instance = instance
+	f.token = token
+}
+
+// Clear ends the current responsibility term, all further writes are rejected.
+func (f *Fence) Clear() {
+	f.Set(nil, nil, 0)
+}
+
+func (f *Fence) get() ([]byte, []byte, int64) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.environment, f.instance, f.token
+}
+
+// VerifyFence returns ErrFenced unless the fencing token in the database is still ours.
+// The icingadb_instance row is locked until tx ends, so that no takeover can happen in between.
+// Without a Fence, every write is allowed.
+func (dbw *DBWrapper) VerifyFence(tx DbTransaction) error {
+	if dbw.Fence == nil {
+		return nil
+	}
+
+	environment, instance, token := dbw.Fence.get()
+	if token == 0 {
+		return ErrFenced
+	}
+
+	rows, err := dbw.SqlFetchAllTxQuiet(
+		tx, fenceObserver,
+		"SELECT fencing_token FROM icingadb_instance WHERE environment_id = ? AND id = ?"+dbw.dialect().ForShare(),
+		environment, instance,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(rows) != 1 || rows[0][0].(int64) != token {
+		return ErrFenced
+	}
+
+	return nil
+}
+
+// sqlFencedExec executes query within a transaction verifying the Fence, if any.
+func (dbw *DBWrapper) sqlFencedExec(opObserver prometheus.Observer, query string, args ...interface{}) error {
+	if dbw.Fence == nil {
+		_, err := dbw.WithRetry(func() (sql.Result, error) {
+			return dbw.SqlExec(opObserver, query, args...)
+		})
+
+		return err
+	}
+
+	return dbw.SqlTransaction(false, true, false, func(tx DbTransaction) error {
+		if err := dbw.VerifyFence(tx); err != nil {
+			return err
+		}
+
+		_, err := dbw.SqlExecTx(tx, opObserver, query, args...)
+		return err
+	})
+}
@@ -0,0 +1,54 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"crypto/sha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDBWrapper_VerifyFence(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	upgrade, err := ioutil.ReadFile("../etc/schema/sqlite/upgrades/2.sql")
+	require.NoError(t, err)
+
+	_, err = dbw.Db.Exec(string(upgrade))
+	require.NoError(t, err)
+
+	envId := sha1.Sum([]byte("derp"))
+	instanceId := sha1.Sum([]byte("horst"))
+
+	_, err = dbw.SqlExec(
+		mysqlTestObserver,
+		"INSERT INTO icingadb_instance(id, environment_id, heartbeat, responsible, fencing_token) VALUES (?, ?, 1, 'y', 1)",
+		instanceId[:], envId[:],
+	)
+	require.NoError(t, err)
+
+	write := func() error {
+		return dbw.sqlFencedExec(mysqlTestObserver, "UPDATE icingadb_instance SET heartbeat = heartbeat + 1")
+	}
+
+	assert.NoError(t, write(), "writes without fence must be allowed")
+
+	dbw.Fence = &Fence{}
+	assert.Equal(t, ErrFenced, write(), "writes without responsibility term must be rejected")
+
+	dbw.Fence.Set(envId[:], instanceId[:], 1)
+	assert.NoError(t, write())
+
+	// Another instance takes over.
+	_, err = dbw.SqlExec(mysqlTestObserver, "UPDATE icingadb_instance SET fencing_token = fencing_token + 1")
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrFenced, write(), "writes with an outdated fencing token must be rejected")
+
+	rows, err := dbw.SqlFetchAll(mysqlTestObserver, "SELECT heartbeat FROM icingadb_instance")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(3)}}, rows)
+}
@@ -77,15 +77,21 @@ type DbTransaction interface {
 	Rollback() error
 }
 
+// NewDBWrapper connects to a MySQL database.
 func NewDBWrapper(dbDsn string, maxOpenConns int) (*DBWrapper, error) {
-	log.Info("Connecting to MySQL")
-	db, err := mkMysql("mysql", dbDsn, maxOpenConns)
+	return NewDBWrapperWithDialect(MysqlDialect, dbDsn, maxOpenConns)
+}
+
+// NewDBWrapperWithDialect connects to a database of the given dialect.
+func NewDBWrapperWithDialect(dialect Dialect, dbDsn string, maxOpenConns int) (*DBWrapper, error) {
+	log.WithFields(log.Fields{"type": dialect.Name()}).Info("Connecting to database")
+	db, err := dialect.Open(dbDsn, maxOpenConns)
 
 	if err != nil {
 		return nil, err
 	}
 
-	dbw := DBWrapper{Db: db, ConnectedAtomic: new(uint32), ConnectionLostCounterAtomic: new(uint32)}
+	dbw := DBWrapper{Db: db, Dialect: dialect, ConnectedAtomic: new(uint32), ConnectionLostCounterAtomic: new(uint32)}
 	dbw.ConnectionUpCondition = sync.NewCond(&sync.Mutex{})
 
 	err = dbw.Db.Ping()
@@ -109,9 +115,32 @@ func NewDBWrapper(dbDsn string, maxOpenConns int) (*DBWrapper, error) {
 // DBWrapper is a database wrapper including helper functions.
 type DBWrapper struct {
 	Db                          DbClient
+	Dialect                     Dialect
 	ConnectedAtomic             *uint32 //uint32 to be able to use atomic operations
 	ConnectionUpCondition       *sync.Cond
 	ConnectionLostCounterAtomic *uint32 //uint32 to be able to use atomic operations
+	Fence                       *Fence  // if set, verified by all bulk writes
+}
+
+// SetMaxOpenConns changes the maximum number of open connections, if supported by the underlying client.
+func (dbw *DBWrapper) SetMaxOpenConns(maxOpenConns int) {
+	if db, ok := dbw.Db.(interface{ SetMaxOpenConns(int) }); ok {
+		db.SetMaxOpenConns(maxOpenConns)
+	}
+}
+
+// dialect returns the Dialect of this DBWrapper, MySQL if none is set.
+func (dbw *DBWrapper) dialect() Dialect {
+	if dbw.Dialect == nil {
+		return MysqlDialect
+	}
+
+	return dbw.Dialect
+}
+
+// BuildUpsert returns a single row statement which inserts fields into table and replaces rows conflicting on keys.
+func (dbw *DBWrapper) BuildUpsert(table string, fields []string, keys ...string) string {
+	return fmt.Sprintf(dbw.dialect().Upsert(table, fields, keys), MakePlaceholderList(len(fields)))
 }
 
 func (dbw *DBWrapper) IsConnected() bool {
@@ -200,7 +229,7 @@ func (dbw *DBWrapper) WithRetry(f func() (sql.Result, error)) (sql.Result, error
 		res, err := f()
 
 		if err != nil {
-			if isRetryableError(err) {
+			if isRetryableError(err) || dbw.dialect().IsSerializationFailure(err) {
 				continue
 			} else {
 				return nil, err
@@ -213,6 +242,7 @@ func (dbw *DBWrapper) WithRetry(f func() (sql.Result, error)) (sql.Result, error
 
 func (dbw *DBWrapper) SqlQuery(query string, args ...interface{}) (*sql.Rows, error) {
 	DbOperationsQuery.Inc()
+	query = dbw.dialect().Rebind(query)
 	for {
 		if !dbw.IsConnected() {
 			dbw.WaitForConnection()
@@ -382,6 +412,7 @@ func (dbw *DBWrapper) SqlFetchAllTxQuiet(tx DbTransaction, queryObserver prometh
 
 // sqlExecInternal is a wrapper around sql.Exec() for auto-logging.
 func (dbw *DBWrapper) sqlExecInternal(db DbClientOrTransaction, opObserver prometheus.Observer, sql string, quiet bool, args ...interface{}) (sql.Result, error) {
+	sql = dbw.dialect().Rebind(sql)
 	for {
 		if !dbw.IsConnected() {
 			dbw.WaitForConnection()
@@ -423,6 +454,7 @@ func (dbw *DBWrapper) sqlExecInternal(db DbClientOrTransaction, opObserver prome
 
 // sqlFetchAllInternal is a wrapper around Db.SqlQuery() for auto-logging.
 func (dbw *DBWrapper) sqlFetchAllInternal(db DbClientOrTransaction, queryObserver prometheus.Observer, query string, quiet bool, args ...interface{}) ([][]interface{}, error) {
+	query = dbw.dialect().Rebind(query)
 	for {
 		if !dbw.IsConnected() {
 			dbw.WaitForConnection()
@@ -554,7 +586,7 @@ func (dbw DBWrapper) SqlTransaction(concurrencySafety bool, retryOnConnectionFai
 
 		if errTx != nil {
 			//TODO: Do this only for concurrencySafety = true, once we figure out the serialization errors.
-			if isSerializationFailure(errTx) {
+			if dbw.dialect().IsSerializationFailure(errTx) {
 				if !quiet {
 					log.WithFields(log.Fields{
 						"context": "sql",
@@ -564,6 +596,10 @@ func (dbw DBWrapper) SqlTransaction(concurrencySafety bool, retryOnConnectionFai
 				continue
 			}
 
+			if errTx == ErrFenced {
+				return errTx
+			}
+
 			if dbw.isConnectionError(errTx) {
 				if retryOnConnectionFailure {
 					continue
@@ -608,7 +644,8 @@ func (dbw *DBWrapper) SqlFetchIds(envId []byte, table string, field string) ([]s
 		}
 
 		rows, err := dbw.SqlQuery(
-			fmt.Sprintf("SELECT %s FROM %s WHERE environment_id=(X'%s') AND NOT %s=?", field, table, utils.DecodeChecksum(envId), field),
+			fmt.Sprintf("SELECT %s FROM %s WHERE environment_id=? AND NOT %s=?", QuoteIdentifier(field), QuoteIdentifier(table), QuoteIdentifier(field)),
+			envId,
 			[]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
 		)
 
@@ -649,8 +686,15 @@ func (dbw *DBWrapper) SqlFetchChecksums(table string, ids []string) (map[string]
 	//TODO: Don't do this hardcoded - Chunksize
 	for bulk := range utils.ChunkKeys(done, ids, 1000) {
 		//TODO: This should be done in parallel
-		query := fmt.Sprintf("SELECT id, properties_checksum FROM %s WHERE id IN (X'%s')", table, strings.Join(bulk, "', X'"))
-		rows, err := dbw.SqlQuery(query)
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(bulk)), ", ")
+		values := make([]interface{}, len(bulk))
+
+		for i, id := range bulk {
+			values[i] = utils.EncodeChecksum(id)
+		}
+
+		query := fmt.Sprintf("SELECT id, properties_checksum FROM %s WHERE id IN (%s)", QuoteIdentifier(table), placeholders)
+		rows, err := dbw.SqlQuery(query, values...)
 
 		if err != nil {
 			if dbw.isConnectionError(err) {
@@ -713,17 +757,9 @@ func (dbw *DBWrapper) SqlBulkInsert(rows []Row, stmt *BulkInsertStmt) error {
 		}
 	}
 
-	query := fmt.Sprintf(stmt.Format, strings.Join(placeholders, ", "))
-
-	_, err := dbw.WithRetry(func() (result sql.Result, e error) {
-		return dbw.SqlExec(mysqlObservers.bulkInsert, query, values...)
-	})
-
-	if err != nil {
-		return err
-	}
+	query := fmt.Sprintf(stmt.Format(dbw.dialect()), strings.Join(placeholders, ", "))
 
-	return nil
+	return dbw.sqlFencedExec(mysqlObservers.bulkInsert, query, values...)
 }
 
 func (dbw *DBWrapper) SqlBulkDelete(keys []string, stmt *BulkDeleteStmt) error {
@@ -746,10 +782,7 @@ func (dbw *DBWrapper) SqlBulkDelete(keys []string, stmt *BulkDeleteStmt) error {
 		}
 		query := fmt.Sprintf(stmt.Format, placeholders)
 
-		_, err := dbw.WithRetry(func() (result sql.Result, e error) {
-			return dbw.SqlExec(mysqlObservers.bulkDelete, query, values...)
-		})
-		if err != nil {
+		if err := dbw.sqlFencedExec(mysqlObservers.bulkDelete, query, values...); err != nil {
 			return err
 		}
 	}
@@ -777,14 +810,7 @@ func (dbw *DBWrapper) SqlBulkUpdate(rows []Row, stmt *BulkUpdateStmt) error {
 		}
 	}
 
-	query := fmt.Sprintf(stmt.Format, strings.Join(placeholders, ", "))
+	query := fmt.Sprintf(stmt.Format(dbw.dialect()), strings.Join(placeholders, ", "))
 
-	_, err := dbw.WithRetry(func() (result sql.Result, e error) {
-		return dbw.SqlExec(mysqlObservers.bulkUpdate, query, values...)
-	})
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return dbw.sqlFencedExec(mysqlObservers.bulkUpdate, query, values...)
 }
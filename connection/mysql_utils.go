@@ -159,6 +159,9 @@ var dbTypeBridgeFactories = map[string]func() dbTypeBridge{
 	"FLOAT": func() dbTypeBridge {
 		return &dbFloatBridge{}
 	},
+	"DOUBLE": func() dbTypeBridge {
+		return &dbFloatBridge{}
+	},
 	"CHAR": func() dbTypeBridge {
 		return &dbStringBridge{}
 	},
@@ -172,6 +175,32 @@ var dbTypeBridgeFactories = map[string]func() dbTypeBridge{
 		return &dbBytesBridge{}
 	},
 
+	// PostgreSQL
+	"INT2": func() dbTypeBridge {
+		return &dbIntBridge{}
+	},
+	"INT4": func() dbTypeBridge {
+		return &dbIntBridge{}
+	},
+	"INT8": func() dbTypeBridge {
+		return &dbIntBridge{}
+	},
+	"FLOAT4": func() dbTypeBridge {
+		return &dbFloatBridge{}
+	},
+	"FLOAT8": func() dbTypeBridge {
+		return &dbFloatBridge{}
+	},
+	"NUMERIC": func() dbTypeBridge {
+		return &dbFloatBridge{}
+	},
+	"BPCHAR": func() dbTypeBridge {
+		return &dbStringBridge{}
+	},
+	"BYTEA": func() dbTypeBridge {
+		return &dbBytesBridge{}
+	},
+
 	// SQLite
 	"INTEGER": func() dbTypeBridge {
 		return &dbIntBridge{}
@@ -372,18 +401,25 @@ func isRetryableError(err error) bool {
 }
 
 type BulkInsertStmt struct {
-	Format      string
+	Table       string
 	Fields      []string
+	PrimaryKey  []string
 	Placeholder string
 	NumField    int
 }
 
-func NewBulkInsertStmt(table string, fields []string) *BulkInsertStmt {
+// NewBulkInsertStmt creates a statement inserting fields into table. The primary key defaults to the first field.
+func NewBulkInsertStmt(table string, fields []string, primaryKey ...string) *BulkInsertStmt {
 	numField := len(fields)
 	placeholder := fmt.Sprintf("(%s)", strings.TrimSuffix(strings.Repeat("?, ", numField), ", "))
+	if len(primaryKey) == 0 {
+		primaryKey = fields[:1]
+	}
+
 	stmt := BulkInsertStmt{
-		Format:      fmt.Sprintf("REPLACE INTO %s (%s) VALUES %s", table, strings.Join(fields, ", "), "%s"),
+		Table:       table,
 		Fields:      fields,
+		PrimaryKey:  primaryKey,
 		Placeholder: placeholder,
 		NumField:    numField,
 	}
@@ -391,31 +427,43 @@ func NewBulkInsertStmt(table string, fields []string) *BulkInsertStmt {
 	return &stmt
 }
 
+// Format returns the statement format for the given dialect, with one %s for the placeholders.
+func (stmt *BulkInsertStmt) Format(dialect Dialect) string {
+	return dialect.Upsert(stmt.Table, stmt.Fields, stmt.PrimaryKey)
+}
+
 type BulkDeleteStmt struct {
 	Format string
 }
 
 func NewBulkDeleteStmt(table string, primaryKey string) *BulkDeleteStmt {
 	stmt := BulkDeleteStmt{
-		Format: fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", table, primaryKey, "%s"),
+		Format: fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", QuoteIdentifier(table), QuoteIdentifier(primaryKey), "%s"),
 	}
 
 	return &stmt
 }
 
 type BulkUpdateStmt struct {
-	Format      string
+	Table       string
 	Fields      []string
+	PrimaryKey  []string
 	Placeholder string
 	NumField    int
 }
 
-func NewBulkUpdateStmt(table string, fields []string) *BulkUpdateStmt {
+// NewBulkUpdateStmt creates a statement replacing fields in table. The primary key defaults to the first field.
+func NewBulkUpdateStmt(table string, fields []string, primaryKey ...string) *BulkUpdateStmt {
 	numField := len(fields)
 	placeholder := fmt.Sprintf("(%s)", strings.TrimSuffix(strings.Repeat("?, ", numField), ", "))
+	if len(primaryKey) == 0 {
+		primaryKey = fields[:1]
+	}
+
 	stmt := BulkUpdateStmt{
-		Format:      fmt.Sprintf("REPLACE INTO %s (%s) VALUES %s", table, strings.Join(fields, ", "), "%s"),
+		Table:       table,
 		Fields:      fields,
+		PrimaryKey:  primaryKey,
 		Placeholder: placeholder,
 		NumField:    numField,
 	}
@@ -423,6 +471,11 @@ func NewBulkUpdateStmt(table string, fields []string) *BulkUpdateStmt {
 	return &stmt
 }
 
+// Format returns the statement format for the given dialect, with one %s for the placeholders.
+func (stmt *BulkUpdateStmt) Format(dialect Dialect) string {
+	return dialect.Upsert(stmt.Table, stmt.Fields, stmt.PrimaryKey)
+}
+
 type Row interface {
 	InsertValues() []interface{}
 	UpdateValues() []interface{}
@@ -69,3 +69,19 @@ var DbBulkDeletes = promauto.NewCounter(prometheus.CounterOpts{
 	Name: "db_bulk_deletes",
 	Help: "Database bulk deletes since startup",
 })
+
+var RedisStreamPendingEntries = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "redis_stream_pending_entries",
+		Help: "Entries of a Redis stream delivered to IcingaDB, but not acknowledged yet",
+	},
+	[]string{"stream"},
+)
+
+var RedisStreamClaimedEntries = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "redis_stream_claimed_entries_total",
+		Help: "Pending Redis stream entries claimed from other IcingaDB instances since startup",
+	},
+	[]string{"stream"},
+)
@@ -3,6 +3,7 @@
 package connection
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/Icinga/icingadb/utils"
 	"github.com/go-redis/redis"
@@ -75,6 +76,19 @@ type RedisClient interface {
 	Publish(channel string, message interface{}) *redis.IntCmd
 	XRead(a *redis.XReadArgs) *redis.XStreamSliceCmd
 	XDel(stream string, ids ...string) *redis.IntCmd
+	XAdd(a *redis.XAddArgs) *redis.StringCmd
+	XRangeN(stream, start, stop string, count int64) *redis.XMessageSliceCmd
+	XGroupCreateMkStream(stream, group, start string) *redis.StatusCmd
+	XGroupDelConsumer(stream, group, consumer string) *redis.IntCmd
+	XReadGroup(a *redis.XReadGroupArgs) *redis.XStreamSliceCmd
+	XAck(stream, group string, ids ...string) *redis.IntCmd
+	XPending(stream, group string) *redis.XPendingCmd
+	XPendingExt(a *redis.XPendingExtArgs) *redis.XPendingExtCmd
+	XClaim(a *redis.XClaimArgs) *redis.XMessageSliceCmd
+	ZAdd(key string, members ...redis.Z) *redis.IntCmd
+	ZRangeByScore(key string, opt redis.ZRangeBy) *redis.StringSliceCmd
+	ZRem(key string, members ...interface{}) *redis.IntCmd
+	ZCard(key string) *redis.IntCmd
 	HKeys(key string) *redis.StringSliceCmd
 	HMGet(key string, fields ...string) *redis.SliceCmd
 	HGetAll(key string) *redis.StringStringMapCmd
@@ -106,24 +120,61 @@ func (rdbw *RDBWrapper) CompareAndSetConnected(connected bool) (swapped bool) {
 	}
 }
 
+// RedisOptions configures the connection of a RDBWrapper.
+type RedisOptions struct {
+	// Address is either host:port or the path of a unix socket.
+	Address string
+	// User is the ACL user name (Redis 6+). If empty, AUTH is sent with the password only.
+	User      string
+	Password  string
+	PoolSize  int
+	TLSConfig *tls.Config
+	// MasterName enables Sentinel mode. The master's address is then looked up via SentinelAddrs
+	// and Address is ignored.
+	MasterName       string
+	SentinelAddrs    []string
+	SentinelPassword string
+}
+
 func NewRDBWrapper(address string, poolSize int) *RDBWrapper {
+	return NewRDBWrapperWithOptions(&RedisOptions{Address: address, PoolSize: poolSize})
+}
+
+func NewRDBWrapperWithOptions(options *RedisOptions) *RDBWrapper {
 	log.Info("Connecting to Redis")
 
 	// TODO: remove this in favor of https://github.com/go-redis/redis/pull/1165
 	var net string
-	if strings.HasPrefix(address, "/") {
+	if strings.HasPrefix(options.Address, "/") {
 		net = "unix"
 	}
 
-	rdb := redis.NewClient(&redis.Options{
+	redisOptions := &redis.Options{
 		Network:      net,
-		Addr:         address,
+		Addr:         options.Address,
 		DialTimeout:  time.Minute / 2,
 		ReadTimeout:  time.Minute,
 		WriteTimeout: time.Minute,
 		PoolTimeout:  time.Minute,
-		PoolSize:     poolSize,
-	})
+		PoolSize:     options.PoolSize,
+		TLSConfig:    options.TLSConfig,
+	}
+
+	if options.User == "" {
+		redisOptions.Password = options.Password
+	} else {
+		// The client only knows AUTH <password>, so authenticate ACL users on our own.
+		redisOptions.OnConnect = func(conn *redis.Conn) error {
+			return conn.Do("auth", options.User, options.Password).Err()
+		}
+	}
+
+	var rdb RedisClient
+	if options.MasterName == "" {
+		rdb = redis.NewClient(redisOptions)
+	} else {
+		rdb = newFailoverClient(options, redisOptions)
+	}
 
 	rdbw := RDBWrapper{
 		Rdb: rdb, ConnectedAtomic: new(uint32),
@@ -197,6 +248,21 @@ func (rdbw *RDBWrapper) CheckConnection(isTicker bool) bool {
 	}
 }
 
+// masterSwitches returns how often the client switched to another master, always 0 without Sentinel.
+func (rdbw *RDBWrapper) masterSwitches() uint32 {
+	if switcher, ok := rdbw.Rdb.(masterSwitcher); ok {
+		return switcher.MasterSwitches()
+	}
+
+	return 0
+}
+
+// hasSwitchedMaster returns whether the master has been switched since masterSwitches returned switches.
+// Commands which failed in the meantime may be retried as they were sent to the old master.
+func (rdbw *RDBWrapper) hasSwitchedMaster(switches uint32) bool {
+	return rdbw.masterSwitches() != switches
+}
+
 func (rdbw *RDBWrapper) WaitForConnection() {
 	rdbw.ConnectionUpCondition.L.Lock()
 	rdbw.ConnectionUpCondition.Wait()
@@ -211,11 +277,12 @@ func (rdbw *RDBWrapper) Publish(channel string, message interface{}) *redis.IntC
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		cmd := rdbw.Rdb.Publish(channel, message)
 		_, err := cmd.Result()
 
 		if err != nil {
-			if !rdbw.CheckConnection(false) {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -232,11 +299,13 @@ func (rdbw *RDBWrapper) XRead(args *redis.XReadArgs) *redis.XStreamSliceCmd {
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		cmd := rdbw.Rdb.XRead(args)
 		_, err := cmd.Result()
 
-		if err != nil {
-			if !rdbw.CheckConnection(false) {
+		// A blocking read which timed out is no connection problem.
+		if err != nil && err != redis.Nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -253,11 +322,299 @@ func (rdbw *RDBWrapper) XDel(stream string, ids ...string) *redis.IntCmd {
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		cmd := rdbw.Rdb.XDel(stream, ids...)
 		_, err := cmd.Result()
 
 		if err != nil {
-			if !rdbw.CheckConnection(false) {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XAdd is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XAdd(args *redis.XAddArgs) *redis.StringCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XAdd(args)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XRangeN is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XRangeN(stream, start, stop string, count int64) *redis.XMessageSliceCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XRangeN(stream, start, stop, count)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XGroupCreateMkStream is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XGroupCreateMkStream(stream, group, start string) *redis.StatusCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XGroupCreateMkStream(stream, group, start)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XGroupDelConsumer is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XGroupDelConsumer(stream, group, consumer string) *redis.IntCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XGroupDelConsumer(stream, group, consumer)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XReadGroup is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XReadGroup(args *redis.XReadGroupArgs) *redis.XStreamSliceCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XReadGroup(args)
+		_, err := cmd.Result()
+
+		// A blocking read which timed out is no connection problem.
+		if err != nil && err != redis.Nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XAck is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XAck(stream, group string, ids ...string) *redis.IntCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XAck(stream, group, ids...)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XPending is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XPending(stream, group string) *redis.XPendingCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XPending(stream, group)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XPendingExt is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XPendingExt(args *redis.XPendingExtArgs) *redis.XPendingExtCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XPendingExt(args)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// XClaim is a wrapper for connection handling.
+func (rdbw *RDBWrapper) XClaim(args *redis.XClaimArgs) *redis.XMessageSliceCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.XClaim(args)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// ZAdd is a wrapper for connection handling.
+func (rdbw *RDBWrapper) ZAdd(key string, members ...redis.Z) *redis.IntCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.ZAdd(key, members...)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// ZRangeByScore is a wrapper for connection handling.
+func (rdbw *RDBWrapper) ZRangeByScore(key string, opt redis.ZRangeBy) *redis.StringSliceCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.ZRangeByScore(key, opt)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// ZRem is a wrapper for connection handling.
+func (rdbw *RDBWrapper) ZRem(key string, members ...interface{}) *redis.IntCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.ZRem(key, members...)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
+				continue
+			}
+		}
+
+		return cmd
+	}
+}
+
+// ZCard is a wrapper for connection handling.
+func (rdbw *RDBWrapper) ZCard(key string) *redis.IntCmd {
+	for {
+		if !rdbw.IsConnected() {
+			rdbw.WaitForConnection()
+			continue
+		}
+
+		switches := rdbw.masterSwitches()
+		cmd := rdbw.Rdb.ZCard(key)
+		_, err := cmd.Result()
+
+		if err != nil {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -274,11 +631,12 @@ func (rdbw *RDBWrapper) HKeys(key string) *redis.StringSliceCmd {
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		cmd := rdbw.Rdb.HKeys(key)
 		_, err := cmd.Result()
 
 		if err != nil {
-			if !rdbw.CheckConnection(false) {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -294,11 +652,12 @@ func (rdbw *RDBWrapper) HMGet(key string, fields ...string) *redis.SliceCmd {
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		cmd := rdbw.Rdb.HMGet(key, fields...)
 		_, err := cmd.Result()
 
 		if err != nil {
-			if !rdbw.CheckConnection(false) {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -315,11 +674,12 @@ func (rdbw *RDBWrapper) HGetAll(key string) *redis.StringStringMapCmd {
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		benchmarc := utils.NewBenchmark()
 		res := rdbw.Rdb.HGetAll(key)
 
 		if _, err := res.Result(); err != nil {
-			if !rdbw.CheckConnection(false) {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -347,11 +707,12 @@ func (rdbw *RDBWrapper) TxPipelined(fn func(pipeliner redis.Pipeliner) error) ([
 			continue
 		}
 
+		switches := rdbw.masterSwitches()
 		benchmarc := utils.NewBenchmark()
 		cmd, err := rdbw.Rdb.TxPipelined(fn)
 
 		if err != nil {
-			if !rdbw.CheckConnection(false) {
+			if !rdbw.CheckConnection(false) || rdbw.hasSwitchedMaster(switches) {
 				continue
 			}
 		}
@@ -376,10 +737,8 @@ func (rdbw *RDBWrapper) Pipeline() PipelinerWrapper {
 	return plw
 }
 
-func (rdbw *RDBWrapper) Subscribe() PubSubWrapper {
-	ps := rdbw.Rdb.Subscribe()
-	psw := PubSubWrapper{ps: ps, rdbw: rdbw}
-	return psw
+func (rdbw *RDBWrapper) Subscribe() *PubSubWrapper {
+	return newPubSubWrapper(rdbw)
 }
 
 type ConfigChunk struct {
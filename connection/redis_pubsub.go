@@ -4,26 +4,86 @@ package connection
 
 import (
 	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+	"sync"
 )
 
+// PubSubWrapper is a redis.PubSub wrapper including connection handling.
+// It remembers its channels, so that it can subscribe them again after a master switch.
 type PubSubWrapper struct {
-	ps   *redis.PubSub
 	rdbw *RDBWrapper
+
+	mu       sync.Mutex
+	ps       *redis.PubSub
+	switches uint32
+	channels []string
+	done     chan struct{}
+	closed   bool
+}
+
+func newPubSubWrapper(rdbw *RDBWrapper) *PubSubWrapper {
+	return &PubSubWrapper{
+		rdbw:     rdbw,
+		ps:       rdbw.Rdb.Subscribe(),
+		switches: rdbw.masterSwitches(),
+		done:     make(chan struct{}),
+	}
+}
+
+// current returns the underlying PubSub and the number of master switches it was created after.
+func (psw *PubSubWrapper) current() (*redis.PubSub, uint32) {
+	psw.mu.Lock()
+	defer psw.mu.Unlock()
+
+	return psw.ps, psw.switches
+}
+
+// resubscribe replaces the PubSub of the old master (since switches) with one subscribed on the current master.
+func (psw *PubSubWrapper) resubscribe(switches uint32) {
+	psw.mu.Lock()
+	defer psw.mu.Unlock()
+
+	if psw.closed || psw.switches != switches {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"context":  "redis",
+		"channels": psw.channels,
+	}).Info("Resubscribing after Redis master switch")
+
+	old := psw.ps
+	psw.switches = psw.rdbw.masterSwitches()
+	psw.ps = psw.rdbw.Rdb.Subscribe(psw.channels...)
+
+	go func() {
+		_ = old.Close()
+	}()
 }
 
 func (psw *PubSubWrapper) Subscribe(channels ...string) error {
+	psw.mu.Lock()
+	psw.channels = append(psw.channels, channels...)
+	psw.mu.Unlock()
+
 	for {
 		if !psw.rdbw.IsConnected() {
 			psw.rdbw.WaitForConnection()
 			continue
 		}
 
-		err := psw.ps.Subscribe(channels...)
+		ps, switches := psw.current()
+		err := ps.Subscribe(channels...)
 
 		if err != nil {
 			if !psw.rdbw.CheckConnection(false) {
 				continue
 			}
+
+			if psw.rdbw.hasSwitchedMaster(switches) {
+				psw.resubscribe(switches)
+				return nil
+			}
 		}
 
 		return err
@@ -37,12 +97,18 @@ func (psw *PubSubWrapper) ReceiveMessage() (*redis.Message, error) {
 			continue
 		}
 
-		msg, err := psw.ps.ReceiveMessage()
+		ps, switches := psw.current()
+		msg, err := ps.ReceiveMessage()
 
 		if err != nil {
 			if !psw.rdbw.CheckConnection(false) {
 				continue
 			}
+
+			if psw.rdbw.hasSwitchedMaster(switches) {
+				psw.resubscribe(switches)
+				continue
+			}
 		}
 
 		return msg, err
@@ -50,21 +116,61 @@ func (psw *PubSubWrapper) ReceiveMessage() (*redis.Message, error) {
 }
 
 func (psw *PubSubWrapper) Channel() <-chan *redis.Message {
-	return psw.ps.Channel()
+	return psw.ChannelSize(100)
 }
 
+// ChannelSize returns a Go channel for concurrently receiving messages, which survives master switches.
 func (psw *PubSubWrapper) ChannelSize(size int) <-chan *redis.Message {
-	return psw.ps.ChannelSize(size)
+	ch := make(chan *redis.Message, size)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			ps, switches := psw.current()
+
+			// The channel of a PubSub is closed once the PubSub or its client gets closed.
+			for msg := range ps.ChannelSize(size) {
+				select {
+				case ch <- msg:
+				case <-psw.done:
+					return
+				}
+			}
+
+			select {
+			case <-psw.done:
+				return
+			default:
+			}
+
+			if !psw.rdbw.IsConnected() {
+				psw.rdbw.WaitForConnection()
+			}
+
+			psw.resubscribe(switches)
+		}
+	}()
+
+	return ch
 }
 
 func (psw *PubSubWrapper) Close() error {
+	psw.mu.Lock()
+	if !psw.closed {
+		psw.closed = true
+		close(psw.done)
+	}
+	psw.mu.Unlock()
+
 	for {
 		if !psw.rdbw.IsConnected() {
 			psw.rdbw.WaitForConnection()
 			continue
 		}
 
-		err := psw.ps.Close()
+		ps, _ := psw.current()
+		err := ps.Close()
 
 		if err != nil {
 			if !psw.rdbw.CheckConnection(false) {
@@ -0,0 +1,303 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// masterSwitcher is implemented by RedisClients which may replace their connections to another Redis server.
+type masterSwitcher interface {
+	// MasterSwitches returns how often the client switched to another master so far.
+	MasterSwitches() uint32
+}
+
+// failoverClient is a RedisClient which asks Redis Sentinel for the current master and watches for failovers.
+// On a master switch, the underlying client is replaced and the old one is closed.
+type failoverClient struct {
+	masterName       string
+	sentinelAddrs    []string
+	sentinelPassword string
+	tlsConfig        *tls.Config
+	options          redis.Options
+
+	mu         sync.RWMutex
+	client     *redis.Client
+	masterAddr string
+
+	switchesAtomic uint32
+}
+
+func newFailoverClient(options *RedisOptions, redisOptions *redis.Options) *failoverClient {
+	fc := &failoverClient{
+		masterName:       options.MasterName,
+		sentinelAddrs:    options.SentinelAddrs,
+		sentinelPassword: options.SentinelPassword,
+		tlsConfig:        options.TLSConfig,
+		options:          *redisOptions,
+	}
+
+	fc.options.Addr = options.MasterName
+	fc.options.TLSConfig = nil
+	fc.options.Dialer = fc.dial
+	fc.client = fc.newClient()
+
+	go fc.watch()
+
+	return fc
+}
+
+func (fc *failoverClient) newClient() *redis.Client {
+	options := fc.options
+	return redis.NewClient(&options)
+}
+
+func (fc *failoverClient) current() *redis.Client {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	return fc.client
+}
+
+func (fc *failoverClient) MasterSwitches() uint32 {
+	return atomic.LoadUint32(&fc.switchesAtomic)
+}
+
+// dial connects to the current master, which is looked up via the sentinels if not known yet.
+func (fc *failoverClient) dial() (net.Conn, error) {
+	fc.mu.RLock()
+	addr := fc.masterAddr
+	fc.mu.RUnlock()
+
+	if addr == "" {
+		var err error
+		if addr, err = fc.queryMasterAddr(); err != nil {
+			return nil, err
+		}
+
+		fc.setMasterAddr(addr)
+	}
+
+	dialer := &net.Dialer{Timeout: fc.options.DialTimeout, KeepAlive: 5 * time.Minute}
+	if fc.tlsConfig == nil {
+		return dialer.Dial("tcp", addr)
+	}
+
+	return tls.DialWithDialer(dialer, "tcp", addr, fc.tlsConfig)
+}
+
+func (fc *failoverClient) newSentinelClient(addr string) *redis.SentinelClient {
+	return redis.NewSentinelClient(&redis.Options{
+		Addr:         addr,
+		Password:     fc.sentinelPassword,
+		DialTimeout:  fc.options.DialTimeout,
+		ReadTimeout:  fc.options.ReadTimeout,
+		WriteTimeout: fc.options.WriteTimeout,
+		PoolSize:     1,
+		TLSConfig:    fc.tlsConfig,
+	})
+}
+
+// queryMasterAddr asks the sentinels one after another for the address of the master.
+func (fc *failoverClient) queryMasterAddr() (string, error) {
+	for _, sentinelAddr := range fc.sentinelAddrs {
+		sentinel := fc.newSentinelClient(sentinelAddr)
+		addr, err := sentinel.GetMasterAddrByName(fc.masterName).Result()
+		_ = sentinel.Close()
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"context":  "redis",
+				"sentinel": sentinelAddr,
+				"error":    err,
+			}).Warn("Can't get Redis master address from sentinel")
+			continue
+		}
+
+		return net.JoinHostPort(addr[0], addr[1]), nil
+	}
+
+	return "", errors.New("all Redis sentinels are unreachable")
+}
+
+// setMasterAddr remembers the address of the master. If it has changed, the client is replaced,
+// so that no connection to the old master is used anymore.
+func (fc *failoverClient) setMasterAddr(addr string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.masterAddr == addr {
+		return
+	}
+
+	previous := fc.masterAddr
+	fc.masterAddr = addr
+
+	if previous == "" {
+		log.WithFields(log.Fields{"context": "redis", "master": addr}).Info("Got Redis master from sentinel")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"context":  "redis",
+		"master":   addr,
+		"previous": previous,
+	}).Warn("Redis master switched")
+
+	old := fc.client
+	fc.client = fc.newClient()
+	atomic.AddUint32(&fc.switchesAtomic, 1)
+
+	go func() {
+		_ = old.Close()
+	}()
+}
+
+// watch subscribes to the master switch notifications of the first reachable sentinel.
+func (fc *failoverClient) watch() {
+	for {
+		for _, sentinelAddr := range fc.sentinelAddrs {
+			fc.watchSentinel(sentinelAddr)
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+func (fc *failoverClient) watchSentinel(sentinelAddr string) {
+	sentinel := fc.newSentinelClient(sentinelAddr)
+	defer sentinel.Close()
+
+	pubsub := sentinel.Subscribe()
+	defer pubsub.Close()
+
+	if err := pubsub.Subscribe("+switch-master"); err != nil {
+		return
+	}
+
+	// Notifications may have been missed while not subscribed.
+	addr, err := sentinel.GetMasterAddrByName(fc.masterName).Result()
+	if err != nil {
+		return
+	}
+
+	fc.setMasterAddr(net.JoinHostPort(addr[0], addr[1]))
+
+	for {
+		msg, err := pubsub.ReceiveMessage()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"context":  "redis",
+				"sentinel": sentinelAddr,
+				"error":    err,
+			}).Warn("Lost connection to Redis sentinel")
+			return
+		}
+
+		// <master name> <old ip> <old port> <new ip> <new port>
+		parts := strings.Split(msg.Payload, " ")
+		if len(parts) == 5 && parts[0] == fc.masterName {
+			fc.setMasterAddr(net.JoinHostPort(parts[3], parts[4]))
+		}
+	}
+}
+
+func (fc *failoverClient) Ping() *redis.StatusCmd {
+	return fc.current().Ping()
+}
+
+func (fc *failoverClient) Publish(channel string, message interface{}) *redis.IntCmd {
+	return fc.current().Publish(channel, message)
+}
+
+func (fc *failoverClient) XRead(a *redis.XReadArgs) *redis.XStreamSliceCmd {
+	return fc.current().XRead(a)
+}
+
+func (fc *failoverClient) XDel(stream string, ids ...string) *redis.IntCmd {
+	return fc.current().XDel(stream, ids...)
+}
+
+func (fc *failoverClient) XAdd(a *redis.XAddArgs) *redis.StringCmd {
+	return fc.current().XAdd(a)
+}
+
+func (fc *failoverClient) XRangeN(stream, start, stop string, count int64) *redis.XMessageSliceCmd {
+	return fc.current().XRangeN(stream, start, stop, count)
+}
+
+func (fc *failoverClient) XGroupCreateMkStream(stream, group, start string) *redis.StatusCmd {
+	return fc.current().XGroupCreateMkStream(stream, group, start)
+}
+
+func (fc *failoverClient) XGroupDelConsumer(stream, group, consumer string) *redis.IntCmd {
+	return fc.current().XGroupDelConsumer(stream, group, consumer)
+}
+
+func (fc *failoverClient) XReadGroup(a *redis.XReadGroupArgs) *redis.XStreamSliceCmd {
+	return fc.current().XReadGroup(a)
+}
+
+func (fc *failoverClient) XAck(stream, group string, ids ...string) *redis.IntCmd {
+	return fc.current().XAck(stream, group, ids...)
+}
+
+func (fc *failoverClient) XPending(stream, group string) *redis.XPendingCmd {
+	return fc.current().XPending(stream, group)
+}
+
+func (fc *failoverClient) XPendingExt(a *redis.XPendingExtArgs) *redis.XPendingExtCmd {
+	return fc.current().XPendingExt(a)
+}
+
+func (fc *failoverClient) XClaim(a *redis.XClaimArgs) *redis.XMessageSliceCmd {
+	return fc.current().XClaim(a)
+}
+
+func (fc *failoverClient) ZAdd(key string, members ...redis.Z) *redis.IntCmd {
+	return fc.current().ZAdd(key, members...)
+}
+
+func (fc *failoverClient) ZRangeByScore(key string, opt redis.ZRangeBy) *redis.StringSliceCmd {
+	return fc.current().ZRangeByScore(key, opt)
+}
+
+func (fc *failoverClient) ZRem(key string, members ...interface{}) *redis.IntCmd {
+	return fc.current().ZRem(key, members...)
+}
+
+func (fc *failoverClient) ZCard(key string) *redis.IntCmd {
+	return fc.current().ZCard(key)
+}
+
+func (fc *failoverClient) HKeys(key string) *redis.StringSliceCmd {
+	return fc.current().HKeys(key)
+}
+
+func (fc *failoverClient) HMGet(key string, fields ...string) *redis.SliceCmd {
+	return fc.current().HMGet(key, fields...)
+}
+
+func (fc *failoverClient) HGetAll(key string) *redis.StringStringMapCmd {
+	return fc.current().HGetAll(key)
+}
+
+func (fc *failoverClient) TxPipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return fc.current().TxPipelined(fn)
+}
+
+func (fc *failoverClient) Pipeline() redis.Pipeliner {
+	return fc.current().Pipeline()
+}
+
+func (fc *failoverClient) Subscribe(channels ...string) *redis.PubSub {
+	return fc.current().Subscribe(channels...)
+}
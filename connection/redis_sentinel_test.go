@@ -0,0 +1,36 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFailoverClient_SetMasterAddr(t *testing.T) {
+	fc := &failoverClient{masterName: "icinga", options: redis.Options{Addr: "icinga"}}
+	fc.client = fc.newClient()
+
+	rdbw := NewTestRDBW(fc)
+	switches := rdbw.masterSwitches()
+	client := fc.current()
+
+	fc.setMasterAddr("10.0.0.1:6379")
+	assert.False(t, rdbw.hasSwitchedMaster(switches), "the initial master is no switch")
+	assert.Same(t, client, fc.current())
+
+	fc.setMasterAddr("10.0.0.1:6379")
+	assert.False(t, rdbw.hasSwitchedMaster(switches), "the same master is no switch")
+
+	fc.setMasterAddr("10.0.0.2:6379")
+	assert.True(t, rdbw.hasSwitchedMaster(switches))
+	assert.False(t, client == fc.current(), "the client of the old master must be replaced")
+}
+
+func TestRDBWrapper_MasterSwitches(t *testing.T) {
+	rdbw := NewTestRDBW(nil)
+
+	assert.Equal(t, uint32(0), rdbw.masterSwitches())
+	assert.False(t, rdbw.hasSwitchedMaster(0))
+}
@@ -0,0 +1,201 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// StreamGroup is the Redis consumer group all IcingaDB instances read the state and history streams as.
+const StreamGroup = "icingadb"
+
+// claimMinIdle is how long an entry has to be pending at another consumer before we claim it.
+// The responsible instance of a crashed one takes over only after its heartbeat is outdated anyway.
+const claimMinIdle = 10 * time.Second
+
+// StreamConsumer reads a Redis stream as a consumer of StreamGroup. Entries stay pending until acknowledged,
+// so that they are read again if IcingaDB dies before. Entries pending at other consumers,
+// e.g. a previously responsible instance, are claimed once they are idle for long enough.
+type StreamConsumer struct {
+	rdbw     *RDBWrapper
+	stream   string
+	consumer string
+
+	// grouped is whether the consumer group is known to exist.
+	grouped bool
+	// ownPending is whether our own pending entries have to be read first.
+	ownPending bool
+	// unacked is how many entries of the last Read haven't been acknowledged.
+	unacked int
+}
+
+// NewStreamConsumer returns a StreamConsumer reading stream as consumer.
+func (rdbw *RDBWrapper) NewStreamConsumer(stream string, consumer string) *StreamConsumer {
+	return &StreamConsumer{rdbw: rdbw, stream: stream, consumer: consumer, ownPending: true}
+}
+
+// Read returns up to count entries, blocking for up to block if there are none.
+// Pending entries (ours and claimed ones) are returned before new ones.
+func (sc *StreamConsumer) Read(count int64, block time.Duration) ([]redis.XMessage, error) {
+	if !sc.grouped {
+		if err := sc.createGroup(); err != nil {
+			return nil, err
+		}
+
+		sc.grouped = true
+		sc.ownPending = true
+	}
+
+	claimed, err := sc.claim(count)
+	if err != nil {
+		return nil, sc.groupError(err)
+	}
+
+	if claimed || sc.unacked > 0 {
+		sc.ownPending = true
+	}
+
+	if sc.ownPending {
+		// Reading from 0 returns the entries delivered to us, but not acknowledged yet.
+		messages, err := sc.readGroup("0", count, -1)
+		if err != nil || len(messages) > 0 {
+			return messages, err
+		}
+
+		sc.ownPending = false
+	}
+
+	return sc.readGroup(">", count, block)
+}
+
+// readGroup reads up to count entries after id as our consumer.
+func (sc *StreamConsumer) readGroup(id string, count int64, block time.Duration) ([]redis.XMessage, error) {
+	streams, err := sc.rdbw.XReadGroup(&redis.XReadGroupArgs{
+		Group:    StreamGroup,
+		Consumer: sc.consumer,
+		Streams:  []string{sc.stream, id},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+
+		return nil, sc.groupError(err)
+	}
+
+	sc.unacked = len(streams[0].Messages)
+
+	return streams[0].Messages, nil
+}
+
+// Ack acknowledges the given entries and removes them from the stream.
+// As IcingaDB is the only reader, nobody else needs them anymore.
+func (sc *StreamConsumer) Ack(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := sc.rdbw.XAck(sc.stream, StreamGroup, ids...).Err(); err != nil {
+		return sc.groupError(err)
+	}
+
+	sc.unacked -= len(ids)
+
+	return sc.rdbw.XDel(sc.stream, ids...).Err()
+}
+
+// createGroup creates the consumer group, reading the stream from its beginning. It's fine if it already exists.
+func (sc *StreamConsumer) createGroup() error {
+	err := sc.rdbw.XGroupCreateMkStream(sc.stream, StreamGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	return nil
+}
+
+// groupError makes the next Read create the consumer group again if it has vanished, e.g. by a FLUSHALL.
+func (sc *StreamConsumer) groupError(err error) error {
+	if strings.HasPrefix(err.Error(), "NOGROUP") {
+		sc.grouped = false
+	}
+
+	return err
+}
+
+// claim takes over up to count idle entries pending at other consumers and returns whether there were any.
+// Consumers left without pending entries are removed.
+func (sc *StreamConsumer) claim(count int64) (bool, error) {
+	pending, err := sc.rdbw.XPending(sc.stream, StreamGroup).Result()
+	if err != nil {
+		return false, err
+	}
+
+	RedisStreamPendingEntries.WithLabelValues(sc.stream).Set(float64(pending.Count))
+
+	claimedAny := false
+
+	for consumer := range pending.Consumers {
+		if consumer == sc.consumer {
+			continue
+		}
+
+		entries, err := sc.rdbw.XPendingExt(&redis.XPendingExtArgs{
+			Stream:   sc.stream,
+			Group:    StreamGroup,
+			Start:    "-",
+			End:      "+",
+			Count:    count,
+			Consumer: consumer,
+		}).Result()
+		if err != nil {
+			return claimedAny, err
+		}
+
+		var ids []string
+		for _, entry := range entries {
+			if entry.Idle >= claimMinIdle {
+				ids = append(ids, entry.Id)
+			}
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		claimed, err := sc.rdbw.XClaim(&redis.XClaimArgs{
+			Stream:   sc.stream,
+			Group:    StreamGroup,
+			Consumer: sc.consumer,
+			MinIdle:  claimMinIdle,
+			Messages: ids,
+		}).Result()
+		if err != nil {
+			return claimedAny, err
+		}
+
+		if len(claimed) > 0 {
+			claimedAny = true
+			RedisStreamClaimedEntries.WithLabelValues(sc.stream).Add(float64(len(claimed)))
+
+			log.WithFields(log.Fields{
+				"context":  "redis",
+				"stream":   sc.stream,
+				"consumer": consumer,
+				"count":    len(claimed),
+			}).Info("Claimed pending stream entries of other consumer")
+		}
+
+		if len(ids) == len(entries) && int64(len(entries)) < count {
+			// All of its entries are ours now.
+			_ = sc.rdbw.XGroupDelConsumer(sc.stream, StreamGroup, consumer).Err()
+		}
+	}
+
+	return claimedAny, nil
+}
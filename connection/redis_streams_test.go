@@ -0,0 +1,46 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"github.com/Icinga/icingadb/config/testbackends"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStreamConsumer(t *testing.T) {
+	rdb := testbackends.RedisTestClient
+	stream := "icingadb:test:stream"
+	require.NoError(t, rdb.Del(stream).Err())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, rdb.XAdd(&redis.XAddArgs{Stream: stream, Values: map[string]interface{}{"i": i}}).Err())
+	}
+
+	rdbw := NewRDBWrapper(testbackends.RedisTestAddr, 64)
+	consumer := rdbw.NewStreamConsumer(stream, "icingadb-test")
+
+	entries, err := consumer.Read(2, -1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Unacknowledged entries have to be read again.
+	again, err := consumer.Read(2, -1)
+	require.NoError(t, err)
+	assert.Equal(t, entries, again)
+
+	require.NoError(t, consumer.Ack(entries[0].ID, entries[1].ID))
+
+	entries, err = consumer.Read(2, -1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	require.NoError(t, consumer.Ack(entries[0].ID))
+
+	entries, err = consumer.Read(2, -1)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.Equal(t, int64(0), rdb.XLen(stream).Val(), "acknowledged entries must be removed")
+}
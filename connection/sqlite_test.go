@@ -0,0 +1,80 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package connection
+
+import (
+	"crypto/sha1"
+	"github.com/Icinga/icingadb/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSqliteTestDBW(t *testing.T) (*DBWrapper, func()) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+
+	dialect, err := GetDialect("sqlite")
+	require.NoError(t, err)
+
+	dbw, err := NewDBWrapperWithDialect(dialect, filepath.Join(dir, "icingadb.db"), 4)
+	require.NoError(t, err)
+
+	schema, err := ioutil.ReadFile("../etc/schema/sqlite/sqlite.schema.sql")
+	require.NoError(t, err)
+
+	_, err = dbw.Db.Exec(string(schema))
+	require.NoError(t, err)
+
+	dbw.checkConnection(false)
+
+	return dbw, func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestDBWrapper_Sqlite(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	sum := func(s string) []byte {
+		hash := sha1.Sum([]byte(s))
+		return hash[:]
+	}
+
+	envId := sum("derp")
+	horst := sum("horst")
+	peter := sum("peter")
+
+	for _, id := range [][]byte{horst, peter} {
+		_, err := dbw.SqlExec(
+			mysqlTestObserver,
+			dbw.BuildUpsert("icingadb_instance", []string{"id", "environment_id", "heartbeat", "responsible"}, "id"),
+			id, envId, 1, "n",
+		)
+		assert.NoError(t, err)
+	}
+
+	// Upserting an existing row must replace it.
+	_, err := dbw.SqlExec(
+		mysqlTestObserver,
+		dbw.BuildUpsert("icingadb_instance", []string{"id", "environment_id", "heartbeat", "responsible"}, "id"),
+		horst, envId, 2, "y",
+	)
+	assert.NoError(t, err)
+
+	rows, err := dbw.SqlFetchAll(
+		mysqlTestObserver,
+		"SELECT heartbeat, responsible FROM icingadb_instance WHERE id = ?",
+		horst,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(2), "y"}}, rows)
+
+	ids, err := dbw.SqlFetchIds(envId, "icingadb_instance", "id")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{utils.DecodeChecksum(horst), utils.DecodeChecksum(peter)}, ids)
+}
@@ -0,0 +1,147 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/deadletter"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+// deadLetter lists (default) or replays the entries of the dead-letter stream. It returns the exit code.
+func deadLetter(path string, args []string) int {
+	flags := flag.NewFlagSet("dead-letter", flag.ExitOnError)
+	asJson := flags.Bool("json", false, "list the entries as JSON, one per line, including their values")
+	count := flags.Int64("count", 100, "list at most this many entries")
+	all := flags.Bool("all", false, "replay all entries")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dead-letter [options] [list | replay [-all] [id ...]]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if err := config.ParseConfig(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, err.Error())
+		return 1
+	}
+
+	stream := config.GetIcingadbInfo().DeadLetterStream
+	if stream == "" {
+		fmt.Fprintf(os.Stderr, "%s: no dead_letter_stream configured\n", path)
+		return 1
+	}
+
+	// The connection wrapper logs its attempts, only the outcome is of interest here.
+	log.SetLevel(log.FatalLevel)
+
+	redisConn, err := connectRedis(config.GetRedisInfo())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "redis: %s\n", err.Error())
+		return 1
+	}
+
+	if err := redisConn.Rdb.Ping().Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "redis: %s\n", err.Error())
+		return 1
+	}
+
+	switch flags.Arg(0) {
+	case "", "list":
+		err = listDeadLetters(redisConn, stream, *count, *asJson)
+	case "replay":
+		// The flags may also follow the sub-command.
+		_ = flags.Parse(flags.Args()[1:])
+		err = replayDeadLetters(redisConn, stream, flags.Args(), *all)
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func listDeadLetters(rdbw *connection.RDBWrapper, stream string, count int64, asJson bool) error {
+	entries, err := deadletter.List(rdbw, stream, "-", count)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, entry := range entries {
+		if asJson {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Printf(
+			"%s\t%s\t%s %s\t%s\n",
+			entry.ID, entry.Time.Format(time.RFC3339), entry.Stream, entry.OriginalID, entry.Error,
+		)
+	}
+
+	return nil
+}
+
+// replayDeadLetters replays the entries with the given IDs or, with all, every entry.
+func replayDeadLetters(rdbw *connection.RDBWrapper, stream string, ids []string, all bool) error {
+	if all == (len(ids) > 0) {
+		return fmt.Errorf("either -all or entry IDs are required")
+	}
+
+	var entries []deadletter.Entry
+	if all {
+		// Entries dead-lettered meanwhile are left for the next replay.
+		for start := "-"; ; {
+			batch, err := deadletter.List(rdbw, stream, start, 1000)
+			if err != nil {
+				return err
+			}
+
+			// The range includes start, which has been fetched already.
+			if len(entries) > 0 && len(batch) > 0 && batch[0].ID == start {
+				batch = batch[1:]
+			}
+
+			if len(batch) == 0 {
+				break
+			}
+
+			entries = append(entries, batch...)
+			start = batch[len(batch)-1].ID
+		}
+	} else {
+		for _, id := range ids {
+			batch, err := deadletter.List(rdbw, stream, id, 1)
+			if err != nil {
+				return err
+			}
+
+			if len(batch) == 0 || batch[0].ID != id {
+				return fmt.Errorf("no dead-letter entry %s", id)
+			}
+
+			entries = append(entries, batch[0])
+		}
+	}
+
+	if err := deadletter.Replay(rdbw, stream, entries); err != nil {
+		return err
+	}
+
+	fmt.Printf("Replayed %d entries\n", len(entries))
+
+	return nil
+}
@@ -0,0 +1,152 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package deadletter moves Redis stream entries which can't be synced to a dead-letter stream,
+// so that they can be inspected and replayed once the cause is fixed.
+package deadletter
+
+import (
+	"fmt"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// Fields added to the values of a dead-lettered entry. Their prefix avoids clashes with the original fields.
+const (
+	fieldPrefix = "icingadb_dead_letter_"
+	fieldStream = fieldPrefix + "stream"
+	fieldId     = fieldPrefix + "id"
+	fieldError  = fieldPrefix + "error"
+	fieldTime   = fieldPrefix + "time"
+)
+
+// Entry is an entry of the dead-letter stream.
+type Entry struct {
+	// ID is the ID of the entry in the dead-letter stream.
+	ID string `json:"id"`
+	// Stream is the stream the entry has been read from.
+	Stream string `json:"stream"`
+	// OriginalID is the ID the entry had in Stream.
+	OriginalID string `json:"original_id"`
+	// Error is why the entry couldn't be synced.
+	Error string `json:"error"`
+	// Time is when the entry has been dead-lettered.
+	Time time.Time `json:"time"`
+	// Values are the original values of the entry.
+	Values map[string]interface{} `json:"values"`
+}
+
+// Send moves msg of stream to the dead-letter stream together with err. If deadLetterStream is empty,
+// the entry is only logged. The caller is still responsible for removing msg from stream.
+func Send(rdbw *connection.RDBWrapper, deadLetterStream string, stream string, msg redis.XMessage, err error) error {
+	log.WithFields(log.Fields{
+		"context": "DeadLetter",
+		"stream":  stream,
+		"id":      msg.ID,
+		"values":  msg.Values,
+	}).Error(err)
+
+	if deadLetterStream == "" {
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(msg.Values)+4)
+	for key, value := range msg.Values {
+		values[key] = value
+	}
+
+	values[fieldStream] = stream
+	values[fieldId] = msg.ID
+	values[fieldError] = err.Error()
+	values[fieldTime] = time.Now().Unix()
+
+	if errAdd := rdbw.XAdd(&redis.XAddArgs{Stream: deadLetterStream, Values: values}).Err(); errAdd != nil {
+		return errAdd
+	}
+
+	EntriesTotal.WithLabelValues(stream).Inc()
+
+	return nil
+}
+
+// Catch calls fn and returns the panic it caused, if any, as error.
+// The conversion of stream entries panics on unexpected values.
+func Catch(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+// List returns up to count entries of the dead-letter stream, starting at the ID start ("-" for the first one).
+func List(rdbw *connection.RDBWrapper, deadLetterStream string, start string, count int64) ([]Entry, error) {
+	messages, err := rdbw.XRangeN(deadLetterStream, start, "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(messages))
+	for _, msg := range messages {
+		entries = append(entries, parseEntry(msg))
+	}
+
+	return entries, nil
+}
+
+// Replay adds the original values of the given dead-letter entries to their streams again
+// and removes them from the dead-letter stream.
+func Replay(rdbw *connection.RDBWrapper, deadLetterStream string, entries []Entry) error {
+	for _, entry := range entries {
+		if entry.Stream == "" {
+			return fmt.Errorf("dead-letter entry %s has no stream", entry.ID)
+		}
+
+		if err := rdbw.XAdd(&redis.XAddArgs{Stream: entry.Stream, Values: entry.Values}).Err(); err != nil {
+			return err
+		}
+
+		if err := rdbw.XDel(deadLetterStream, entry.ID).Err(); err != nil {
+			return err
+		}
+
+		ReplayedTotal.WithLabelValues(entry.Stream).Inc()
+	}
+
+	return nil
+}
+
+// parseEntry splits msg into the original values and the dead-letter fields.
+func parseEntry(msg redis.XMessage) Entry {
+	entry := Entry{ID: msg.ID, Values: make(map[string]interface{}, len(msg.Values))}
+
+	for key, value := range msg.Values {
+		if !strings.HasPrefix(key, fieldPrefix) {
+			entry.Values[key] = value
+			continue
+		}
+
+		str, _ := value.(string)
+		switch key {
+		case fieldStream:
+			entry.Stream = str
+		case fieldId:
+			entry.OriginalID = str
+		case fieldError:
+			entry.Error = str
+		case fieldTime:
+			var unix int64
+			if _, err := fmt.Sscan(str, &unix); err == nil {
+				entry.Time = time.Unix(unix, 0)
+			}
+		}
+	}
+
+	return entry
+}
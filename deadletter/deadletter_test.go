@@ -0,0 +1,41 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package deadletter
+
+import (
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCatch(t *testing.T) {
+	assert.NoError(t, Catch(func() {}))
+
+	err := Catch(func() { uuid.MustParse("not a uuid") })
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a uuid")
+}
+
+func TestParseEntry(t *testing.T) {
+	entry := parseEntry(redis.XMessage{
+		ID: "2-0",
+		Values: map[string]interface{}{
+			"id":        "not a uuid",
+			fieldStream: "icinga:history:stream:state",
+			fieldId:     "1-0",
+			fieldError:  "invalid UUID length: 10",
+			fieldTime:   "1577836800",
+		},
+	})
+
+	assert.Equal(t, Entry{
+		ID:         "2-0",
+		Stream:     "icinga:history:stream:state",
+		OriginalID: "1-0",
+		Error:      "invalid UUID length: 10",
+		Time:       time.Unix(1577836800, 0),
+		Values:     map[string]interface{}{"id": "not a uuid"},
+	}, entry)
+}
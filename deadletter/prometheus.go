@@ -0,0 +1,24 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package deadletter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var EntriesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "dead_letter_entries_total",
+		Help: "Stream entries moved to the dead-letter stream per source stream",
+	},
+	[]string{"stream"},
+)
+
+var ReplayedTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "dead_letter_replayed_total",
+		Help: "Dead-letter entries added to their source stream again per source stream",
+	},
+	[]string{"stream"},
+)
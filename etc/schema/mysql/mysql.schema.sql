@@ -927,4 +927,14 @@ CREATE TABLE history (
   event_time bigint(20) unsigned NOT NULL,
 
   PRIMARY KEY (id)
-) ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;
\ No newline at end of file
+) ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;
+
+CREATE TABLE icingadb_schema (
+  id int(10) unsigned NOT NULL AUTO_INCREMENT,
+  version smallint(5) unsigned NOT NULL,
+  timestamp bigint(20) unsigned NOT NULL COMMENT '*nix timestamp in milliseconds',
+
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (1, UNIX_TIMESTAMP() * 1000);
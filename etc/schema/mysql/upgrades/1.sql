@@ -0,0 +1,13 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Starts tracking the schema version of databases set up before icingadb_schema existed.
+
+CREATE TABLE icingadb_schema (
+  id int(10) unsigned NOT NULL AUTO_INCREMENT,
+  version smallint(5) unsigned NOT NULL,
+  timestamp bigint(20) unsigned NOT NULL COMMENT '*nix timestamp in milliseconds',
+
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (1, UNIX_TIMESTAMP() * 1000);
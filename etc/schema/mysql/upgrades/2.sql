@@ -0,0 +1,12 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Adds the fencing token to icingadb_instance and allows only one row per environment, so that a takeover can be
+-- done as compare-and-swap. The rows are recreated by the running instances with their next heartbeat.
+
+DELETE FROM icingadb_instance;
+
+ALTER TABLE icingadb_instance
+  ADD COLUMN fencing_token bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'incremented on every takeover',
+  ADD UNIQUE KEY idx_icingadb_instance_environment_id (environment_id);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (2, UNIX_TIMESTAMP() * 1000);
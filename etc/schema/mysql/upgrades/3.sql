@@ -0,0 +1,35 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Adds the tables with the parsed performance data of the current host and service states, one row per label.
+
+CREATE TABLE host_perfdata (
+  host_id binary(20) NOT NULL COMMENT 'host.id',
+  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',
+  label varchar(255) NOT NULL,
+
+  value double DEFAULT NULL COMMENT 'NULL if unknown (U)',
+  unit varchar(255) NOT NULL,
+  warn varchar(255) DEFAULT NULL COMMENT 'threshold range',
+  crit varchar(255) DEFAULT NULL COMMENT 'threshold range',
+  min double DEFAULT NULL,
+  max double DEFAULT NULL,
+
+  PRIMARY KEY (host_id, label)
+) ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;
+
+CREATE TABLE service_perfdata (
+  service_id binary(20) NOT NULL COMMENT 'service.id',
+  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',
+  label varchar(255) NOT NULL,
+
+  value double DEFAULT NULL COMMENT 'NULL if unknown (U)',
+  unit varchar(255) NOT NULL,
+  warn varchar(255) DEFAULT NULL COMMENT 'threshold range',
+  crit varchar(255) DEFAULT NULL COMMENT 'threshold range',
+  min double DEFAULT NULL,
+  max double DEFAULT NULL,
+
+  PRIMARY KEY (service_id, label)
+) ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (3, UNIX_TIMESTAMP() * 1000);
@@ -0,0 +1,937 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+CREATE TABLE host (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  customvars_checksum bytea NOT NULL, -- sha1(host.vars)
+  groups_checksum bytea NOT NULL, -- sha1(hostgroup.name + hostgroup.name ...)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+
+  address varchar(255) NOT NULL,
+  address6 varchar(255) NOT NULL,
+  address_bin bytea DEFAULT NULL,
+  address6_bin bytea DEFAULT NULL,
+
+  checkcommand varchar(255) NOT NULL, -- checkcommand.name
+  checkcommand_id bytea NOT NULL, -- checkcommand.id
+
+  max_check_attempts bigint NOT NULL,
+
+  check_timeperiod varchar(255) NOT NULL, -- timeperiod.name
+  check_timeperiod_id bytea DEFAULT NULL, -- timeperiod.id
+
+  check_timeout bigint DEFAULT NULL,
+  check_interval bigint NOT NULL,
+  check_retry_interval bigint NOT NULL,
+
+  active_checks_enabled varchar(1) NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),
+  passive_checks_enabled varchar(1) NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),
+  event_handler_enabled varchar(1) NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),
+  notifications_enabled varchar(1) NOT NULL CHECK (notifications_enabled IN ('y', 'n')),
+
+  flapping_enabled varchar(1) NOT NULL CHECK (flapping_enabled IN ('y', 'n')),
+  flapping_threshold_low real NOT NULL,
+  flapping_threshold_high real NOT NULL,
+
+  perfdata_enabled varchar(1) NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),
+
+  eventcommand varchar(255) NOT NULL, -- eventcommand.name
+  eventcommand_id bytea DEFAULT NULL, -- eventcommand.id
+
+  is_volatile varchar(1) NOT NULL CHECK (is_volatile IN ('y', 'n')),
+
+  action_url_id bytea DEFAULT NULL, -- action_url.id
+  notes_url_id bytea DEFAULT NULL, -- notes_url.id
+  notes text NOT NULL,
+  icon_image_id bytea DEFAULT NULL, -- icon_image.id
+  icon_image_alt varchar(32) NOT NULL,
+
+  zone varchar(255) NOT NULL, -- zone.name
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  command_endpoint varchar(255) NOT NULL, -- endpoint.name
+  command_endpoint_id bytea DEFAULT NULL, -- endpoint.id
+
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_action_url_checksum ON host (action_url_id); -- cleanup
+CREATE INDEX idx_notes_url_checksum ON host (notes_url_id); -- cleanup
+CREATE INDEX idx_icon_image_checksum ON host (icon_image_id); -- cleanup
+
+CREATE TABLE hostgroup (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  customvars_checksum bytea NOT NULL, -- sha1(hostgroup.vars)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE hostgroup_member (
+  id bytea NOT NULL, -- sha1(environment.name + host_id + hostgroup_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  host_id bytea NOT NULL, -- host.id
+  hostgroup_id bytea NOT NULL, -- hostgroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE host_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + host_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  host_id bytea NOT NULL, -- host.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE hostgroup_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + hostgroup_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  hostgroup_id bytea NOT NULL, -- hostgroup.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE host_state (
+  host_id bytea NOT NULL, -- host.id
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+
+  state_type varchar(4) NOT NULL CHECK (state_type IN ('hard', 'soft')),
+  soft_state smallint NOT NULL,
+  hard_state smallint NOT NULL,
+  previous_hard_state smallint NOT NULL,
+  attempt smallint NOT NULL,
+  severity integer NOT NULL,
+
+  output text DEFAULT NULL,
+  long_output text DEFAULT NULL,
+  performance_data text DEFAULT NULL,
+  check_commandline text DEFAULT NULL,
+
+  is_problem varchar(1) NOT NULL CHECK (is_problem IN ('y', 'n')),
+  is_handled varchar(1) NOT NULL CHECK (is_handled IN ('y', 'n')),
+  is_reachable varchar(1) NOT NULL CHECK (is_reachable IN ('y', 'n')),
+  is_flapping varchar(1) NOT NULL CHECK (is_flapping IN ('y', 'n')),
+
+  is_acknowledged varchar(6) NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),
+  acknowledgement_comment_id bytea DEFAULT NULL, -- comment.id
+
+  in_downtime varchar(1) NOT NULL CHECK (in_downtime IN ('y', 'n')),
+
+  execution_time bigint DEFAULT NULL,
+  latency bigint DEFAULT NULL,
+  timeout bigint DEFAULT NULL,
+  check_source text DEFAULT NULL,
+
+  last_update bigint NOT NULL,
+  last_state_change bigint NOT NULL,
+  next_check bigint NOT NULL,
+  next_update bigint NOT NULL,
+
+  PRIMARY KEY (host_id)
+);
+
+CREATE TABLE service (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  customvars_checksum bytea NOT NULL, -- sha1(service.vars)
+  groups_checksum bytea NOT NULL, -- sha1(servicegroup.name + servicegroup.name ...)
+  host_id bytea NOT NULL, -- sha1(host.id)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+
+  checkcommand varchar(255) NOT NULL, -- checkcommand.name
+  checkcommand_id bytea NOT NULL, -- checkcommand.id
+
+  max_check_attempts bigint NOT NULL,
+
+  check_timeperiod varchar(255) NOT NULL, -- timeperiod.name
+  check_timeperiod_id bytea DEFAULT NULL, -- timeperiod.id
+
+  check_timeout bigint DEFAULT NULL,
+  check_interval bigint NOT NULL,
+  check_retry_interval bigint NOT NULL,
+
+  active_checks_enabled varchar(1) NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),
+  passive_checks_enabled varchar(1) NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),
+  event_handler_enabled varchar(1) NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),
+  notifications_enabled varchar(1) NOT NULL CHECK (notifications_enabled IN ('y', 'n')),
+
+  flapping_enabled varchar(1) NOT NULL CHECK (flapping_enabled IN ('y', 'n')),
+  flapping_threshold_low real NOT NULL,
+  flapping_threshold_high real NOT NULL,
+
+  perfdata_enabled varchar(1) NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),
+
+  eventcommand varchar(255) NOT NULL, -- eventcommand.name
+  eventcommand_id bytea DEFAULT NULL, -- eventcommand.id
+
+  is_volatile varchar(1) NOT NULL CHECK (is_volatile IN ('y', 'n')),
+
+  action_url_id bytea DEFAULT NULL, -- action_url.id
+  notes_url_id bytea DEFAULT NULL, -- notes_url.id
+  notes text NOT NULL,
+  icon_image_id bytea DEFAULT NULL, -- icon_image.id
+  icon_image_alt varchar(32) NOT NULL,
+
+  zone varchar(255) NOT NULL, -- zone.name
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  command_endpoint varchar(255) NOT NULL, -- endpoint.name
+  command_endpoint_id bytea DEFAULT NULL, -- endpoint.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE servicegroup (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  customvars_checksum bytea NOT NULL, -- sha1(servicegroup.vars)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE servicegroup_member (
+  id bytea NOT NULL, -- sha1(environment.name + servicegroup_id + service_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  service_id bytea NOT NULL, -- service.id
+  servicegroup_id bytea NOT NULL, -- servicegroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE service_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + service_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  service_id bytea NOT NULL, -- service.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE servicegroup_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + servicegroup_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  servicegroup_id bytea NOT NULL, -- servicegroup.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE service_state (
+  service_id bytea NOT NULL, -- service.id
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+
+  state_type varchar(4) NOT NULL CHECK (state_type IN ('hard', 'soft')),
+  soft_state smallint NOT NULL,
+  hard_state smallint NOT NULL,
+  previous_hard_state smallint NOT NULL,
+  attempt smallint NOT NULL,
+  severity integer NOT NULL,
+
+  output text DEFAULT NULL,
+  long_output text DEFAULT NULL,
+  performance_data text DEFAULT NULL,
+  check_commandline text DEFAULT NULL,
+
+  is_problem varchar(1) NOT NULL CHECK (is_problem IN ('y', 'n')),
+  is_handled varchar(1) NOT NULL CHECK (is_handled IN ('y', 'n')),
+  is_reachable varchar(1) NOT NULL CHECK (is_reachable IN ('y', 'n')),
+  is_flapping varchar(1) NOT NULL CHECK (is_flapping IN ('y', 'n')),
+
+  is_acknowledged varchar(6) NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),
+  acknowledgement_comment_id bytea DEFAULT NULL, -- comment.id
+
+  in_downtime varchar(1) NOT NULL CHECK (in_downtime IN ('y', 'n')),
+
+  execution_time bigint DEFAULT NULL,
+  latency bigint DEFAULT NULL,
+  timeout bigint DEFAULT NULL,
+  check_source text DEFAULT NULL,
+
+  last_update bigint NOT NULL,
+  last_state_change bigint NOT NULL,
+  next_check bigint NOT NULL,
+  next_update bigint NOT NULL,
+
+  PRIMARY KEY (service_id)
+);
+
+CREATE TABLE endpoint (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL,
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+
+  zone_id bytea NOT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE environment (
+  id bytea NOT NULL, -- sha1(name)
+  name varchar(255) NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE icingadb_instance (
+  id bytea NOT NULL, -- UUIDv4
+  environment_id bytea NOT NULL, -- environment.id
+  heartbeat bigint NOT NULL, -- *nix timestamp
+  responsible varchar(1) NOT NULL CHECK (responsible IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand (
+  id bytea NOT NULL, -- sha1(environment.name + type + name)
+  environment_id bytea NOT NULL, -- env.id
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  command text NOT NULL,
+  timeout bigint NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand_argument (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + argument_key)
+  environment_id bytea NOT NULL, -- env.id
+  command_id bytea NOT NULL, -- command.id
+  argument_key varchar(64) NOT NULL,
+
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  argument_value text DEFAULT NULL,
+  argument_order smallint DEFAULT NULL,
+  description text DEFAULT NULL,
+  argument_key_override varchar(64) DEFAULT NULL,
+  repeat_key varchar(1) NOT NULL CHECK (repeat_key IN ('y', 'n')),
+  required varchar(1) NOT NULL CHECK (required IN ('y', 'n')),
+  set_if varchar(255) DEFAULT NULL,
+  skip_key varchar(1) NOT NULL CHECK (skip_key IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand_envvar (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + envvar_key)
+  environment_id bytea NOT NULL, -- env.id
+  command_id bytea NOT NULL, -- command.id
+  envvar_key varchar(64) NOT NULL,
+
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  envvar_value text NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+
+  command_id bytea NOT NULL, -- command.id
+  customvar_id bytea NOT NULL, -- customvar.id
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand (
+  id bytea NOT NULL, -- sha1(environment.name + type + name)
+  environment_id bytea NOT NULL, -- env.id
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  command text NOT NULL,
+  timeout integer NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand_argument (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + argument_key)
+  environment_id bytea NOT NULL, -- env.id
+  command_id bytea NOT NULL, -- command.id
+  argument_key varchar(64) NOT NULL,
+
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  argument_value text DEFAULT NULL,
+  argument_order smallint DEFAULT NULL,
+  description text DEFAULT NULL,
+  argument_key_override varchar(64) DEFAULT NULL,
+  repeat_key varchar(1) NOT NULL CHECK (repeat_key IN ('y', 'n')),
+  required varchar(1) NOT NULL CHECK (required IN ('y', 'n')),
+  set_if varchar(255) DEFAULT NULL,
+  skip_key varchar(1) NOT NULL CHECK (skip_key IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand_envvar (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + envvar_key)
+  environment_id bytea NOT NULL, -- env.id
+  command_id bytea NOT NULL, -- command.id
+  envvar_key varchar(64) NOT NULL,
+
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  envvar_value text NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  command_id bytea NOT NULL, -- command.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand (
+  id bytea NOT NULL, -- sha1(environment.name + type + name)
+  environment_id bytea NOT NULL, -- env.id
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  command text NOT NULL,
+  timeout integer NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand_argument (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + argument_key)
+  environment_id bytea NOT NULL, -- env.id
+  command_id bytea NOT NULL, -- command.id
+  argument_key varchar(64) NOT NULL,
+
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  argument_value text DEFAULT NULL,
+  argument_order smallint DEFAULT NULL,
+  description text DEFAULT NULL,
+  argument_key_override varchar(64) DEFAULT NULL,
+  repeat_key varchar(1) NOT NULL CHECK (repeat_key IN ('y', 'n')),
+  required varchar(1) NOT NULL CHECK (required IN ('y', 'n')),
+  set_if varchar(255) DEFAULT NULL,
+  skip_key varchar(1) NOT NULL CHECK (skip_key IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand_envvar (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + envvar_key)
+  environment_id bytea NOT NULL, -- env.id
+  command_id bytea NOT NULL, -- command.id
+  envvar_key varchar(64) NOT NULL,
+
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  envvar_value text NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + command_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  command_id bytea NOT NULL, -- command.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE comment (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- environment.id
+
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea DEFAULT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL,
+  name varchar(255) NOT NULL,
+
+  author varchar(255) NOT NULL,
+  "text" text NOT NULL,
+  entry_type varchar(7) NOT NULL CHECK (entry_type IN ('comment', 'ack')),
+  entry_time bigint NOT NULL,
+  is_persistent varchar(1) NOT NULL CHECK (is_persistent IN ('y', 'n')),
+  is_sticky varchar(1) NOT NULL CHECK (is_sticky IN ('y', 'n')),
+  expire_time bigint DEFAULT NULL,
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE downtime (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- environment.id
+
+  triggered_by_id bytea DEFAULT NULL, -- downtime.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea DEFAULT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  name varchar(255) NOT NULL,
+
+  author varchar(255) NOT NULL,
+  comment text NOT NULL,
+  entry_time bigint NOT NULL,
+  scheduled_start_time bigint NOT NULL,
+  scheduled_end_time bigint NOT NULL,
+  flexible_duration bigint NOT NULL,
+  is_flexible varchar(1) NOT NULL CHECK (is_flexible IN ('y', 'n')),
+
+  is_in_effect varchar(1) NOT NULL CHECK (is_in_effect IN ('y', 'n')),
+  start_time bigint DEFAULT NULL, -- Time when the host went into a problem state during the downtimes timeframe
+  end_time bigint DEFAULT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + flexible_duration otherwise
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL,
+  customvars_checksum bytea NOT NULL, -- sha1(notification.vars)
+  users_checksum bytea NOT NULL,
+  usergroups_checksum bytea NOT NULL,
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+  command_id bytea NOT NULL, -- command.id
+
+  times_begin bigint DEFAULT NULL,
+  times_end bigint DEFAULT NULL,
+  notification_interval bigint NOT NULL,
+  timeperiod_id bytea DEFAULT NULL, -- timeperiod.id
+
+  states smallint NOT NULL,
+  types integer NOT NULL,
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification_user (
+  id bytea NOT NULL, -- sha1(environment.name + notification_id + user_id)
+  environment_id bytea NOT NULL, -- environment.id
+  notification_id bytea NOT NULL, -- notification.id
+  user_id bytea NOT NULL, -- user.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification_usergroup (
+  id bytea NOT NULL, -- sha1(environment.name + notification_id + usergroup_id)
+  environment_id bytea NOT NULL, -- environment.id
+  notification_id bytea NOT NULL, -- notification.id
+  usergroup_id bytea NOT NULL, -- usergroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + notification_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  notification_id bytea NOT NULL, -- notification.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE icon_image (
+  id bytea NOT NULL, -- sha1(icon_image)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  icon_image text NOT NULL,
+
+  PRIMARY KEY (environment_id, id)
+);
+CREATE INDEX idx_icon_image ON icon_image USING hash (icon_image);
+
+CREATE TABLE action_url (
+  id bytea NOT NULL, -- sha1(action_url)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  action_url text NOT NULL,
+
+  PRIMARY KEY (environment_id, id)
+);
+CREATE INDEX idx_action_url ON action_url USING hash (action_url);
+
+CREATE TABLE notes_url (
+  id bytea NOT NULL, -- sha1(notes_url)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  notes_url text NOT NULL,
+
+  PRIMARY KEY (environment_id, id)
+);
+CREATE INDEX idx_notes_url ON notes_url USING hash (notes_url);
+
+CREATE TABLE timeperiod (
+  id bytea NOT NULL, -- sha1(env.name + name)
+  environment_id bytea NOT NULL, -- env.id
+
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+  prefer_includes varchar(1) NOT NULL CHECK (prefer_includes IN ('y', 'n')),
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_range (
+  id bytea NOT NULL, -- sha1(environment.name + range_id + timeperiod_id)
+  environment_id bytea NOT NULL, -- env.id
+  timeperiod_id bytea NOT NULL, -- timeperiod.id
+  range_key varchar(255) NOT NULL,
+
+  range_value varchar(255) NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_override_include (
+  id bytea NOT NULL, -- sha1(environment.name + include_id + timeperiod_id)
+  environment_id bytea NOT NULL, -- env.id
+  timeperiod_id bytea NOT NULL, -- timeperiod.id
+  override_id bytea NOT NULL, -- timeperiod.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_override_exclude (
+  id bytea NOT NULL, -- sha1(environment.name + exclude_id + timeperiod_id)
+  environment_id bytea NOT NULL, -- env.id
+  timeperiod_id bytea NOT NULL, -- timeperiod.id
+  override_id bytea NOT NULL, -- timeperiod.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + timeperiod_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  timeperiod_id bytea NOT NULL, -- timeperiod.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE customvar (
+  id bytea NOT NULL, -- sha1(environment.name + name + value)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+
+  name varchar(255) NOT NULL,
+  value text NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE customvar_flat (
+  id bytea NOT NULL, -- sha1(environment.name + flatname + flatvalue)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  customvar_id bytea NOT NULL, -- sha1(customvar.id)
+  flatname_checksum bytea NOT NULL, -- sha1(flatname after conversion)
+
+  flatname varchar(512) NOT NULL, -- Path converted with `.` and `[ ]`
+  flatvalue text NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE "user" (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  customvars_checksum bytea NOT NULL, -- sha1(user.vars)
+  groups_checksum bytea NOT NULL, -- sha1(usergroup.name + userroup.name ...)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+
+  email varchar(255) NOT NULL,
+  pager varchar(255) NOT NULL,
+
+  notifications_enabled varchar(1) NOT NULL CHECK (notifications_enabled IN ('y', 'n')),
+
+  timeperiod_id bytea DEFAULT NULL, -- timeperiod.id
+
+  states smallint NOT NULL,
+  types integer NOT NULL,
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE usergroup (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  customvars_checksum bytea NOT NULL, -- sha1(usergroup.vars)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+  display_name varchar(255) NOT NULL,
+
+  zone_id bytea DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE usergroup_member (
+  id bytea NOT NULL, -- sha1(environment.name + usergroup_id + user_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  user_id bytea NOT NULL, -- user.id
+  usergroup_id bytea NOT NULL, -- usergroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE user_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + user_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  user_id bytea NOT NULL, -- user.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE usergroup_customvar (
+  id bytea NOT NULL, -- sha1(environment.name + usergroup_id + customvar_id)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  usergroup_id bytea NOT NULL, -- usergroup.id
+  customvar_id bytea NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE zone (
+  id bytea NOT NULL, -- sha1(environment.name + name)
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  name_checksum bytea NOT NULL, -- sha1(name)
+  properties_checksum bytea NOT NULL, -- sha1(all properties)
+  parents_checksum bytea NOT NULL, -- sha1(all parents checksums)
+
+  name varchar(255) NOT NULL,
+  name_ci varchar(255) NOT NULL,
+
+  is_global varchar(1) NOT NULL CHECK (is_global IN ('y', 'n')),
+  parent_id bytea DEFAULT NULL, -- zone.id
+
+  depth smallint NOT NULL,
+
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_parent_id ON zone (parent_id);
+CREATE UNIQUE INDEX idx_environment_id_id ON zone (environment_id,id);
+
+CREATE TABLE notification_history (
+  id bytea NOT NULL, -- UUID
+  environment_id bytea NOT NULL, -- environment.id
+  endpoint_id bytea DEFAULT NULL, -- endpoint.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+  notification_id bytea NOT NULL, -- notification.id
+
+  type varchar(16) NOT NULL CHECK (type IN ('downtime_start', 'downtime_end', 'downtime_removed', 'custom', 'acknowledgement', 'problem', 'recovery', 'flapping_start', 'flapping_end')),
+  send_time bigint NOT NULL,
+  state smallint NOT NULL,
+  previous_hard_state smallint NOT NULL,
+  author text NOT NULL,
+  "text" text NOT NULL,
+  users_notified integer NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE user_notification_history (
+  id bytea NOT NULL, -- UUID
+  environment_id bytea NOT NULL, -- environment.id
+  notification_history_id bytea NOT NULL, -- UUID notification_history.id
+  user_id bytea NOT NULL, -- user.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE state_history (
+  id bytea NOT NULL, -- UUID
+  environment_id bytea NOT NULL, -- environment.id
+  endpoint_id bytea DEFAULT NULL, -- endpoint.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+
+  event_time bigint NOT NULL,
+  state_type varchar(4) NOT NULL CHECK (state_type IN ('hard', 'soft')),
+  soft_state smallint NOT NULL,
+  hard_state smallint NOT NULL,
+  previous_soft_state smallint NOT NULL,
+  previous_hard_state smallint NOT NULL,
+  attempt smallint NOT NULL,
+  output text DEFAULT NULL,
+  long_output text DEFAULT NULL,
+  max_check_attempts bigint NOT NULL,
+  check_source text DEFAULT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE downtime_history (
+  downtime_id bytea NOT NULL, -- downtime.id
+  environment_id bytea NOT NULL, -- environment.id
+  endpoint_id bytea DEFAULT NULL, -- endpoint.id
+  triggered_by_id bytea DEFAULT NULL, -- downtime.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+
+  entry_time bigint NOT NULL,
+  author varchar(255) NOT NULL,
+  comment text NOT NULL,
+  is_flexible varchar(1) NOT NULL CHECK (is_flexible IN ('y', 'n')),
+  flexible_duration bigint NOT NULL,
+  scheduled_start_time bigint NOT NULL,
+  scheduled_end_time bigint NOT NULL,
+  start_time bigint NOT NULL, -- Time when the host went into a problem state during the downtimes timeframe
+  end_time bigint NOT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + duration otherwise
+  has_been_cancelled varchar(1) NOT NULL CHECK (has_been_cancelled IN ('y', 'n')),
+  trigger_time bigint NOT NULL,
+  cancel_time bigint DEFAULT NULL,
+
+  PRIMARY KEY (downtime_id)
+);
+
+CREATE TABLE comment_history (
+  comment_id bytea NOT NULL, -- comment.id
+  environment_id bytea NOT NULL, -- environment.id
+  endpoint_id bytea DEFAULT NULL, -- endpoint.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+
+  entry_time bigint NOT NULL,
+  author varchar(255) NOT NULL,
+  comment text NOT NULL,
+  entry_type varchar(7) NOT NULL CHECK (entry_type IN ('comment', 'ack')),
+  is_persistent varchar(1) NOT NULL CHECK (is_persistent IN ('y', 'n')),
+  is_sticky varchar(1) NOT NULL CHECK (is_sticky IN ('y', 'n')),
+  expire_time bigint DEFAULT NULL,
+  remove_time bigint DEFAULT NULL,
+  has_been_removed varchar(1) NOT NULL CHECK (has_been_removed IN ('y', 'n')),
+
+  PRIMARY KEY (comment_id)
+);
+
+CREATE TABLE flapping_history (
+  id bytea NOT NULL, -- UUID
+  environment_id bytea NOT NULL, -- environment.id
+  endpoint_id bytea DEFAULT NULL, -- endpoint.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+
+  event_time bigint NOT NULL,
+  percent_state_change real NOT NULL,
+  flapping_threshold_low real NOT NULL,
+  flapping_threshold_high real NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE history (
+  id bytea NOT NULL, -- notification_history_id, state_history_id, flapping_history_id or UUID
+  environment_id bytea NOT NULL, -- environment.id
+  endpoint_id bytea DEFAULT NULL, -- endpoint.id
+  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id bytea NOT NULL, -- host.id
+  service_id bytea DEFAULT NULL, -- service.id
+  notification_history_id bytea DEFAULT NULL, -- notification_history.id
+  state_history_id bytea DEFAULT NULL, -- state_history.id
+  downtime_history_id bytea DEFAULT NULL, -- downtime_history.downtime_id
+  comment_history_id bytea DEFAULT NULL, -- comment_history.comment_id
+  flapping_history_id bytea DEFAULT NULL, -- flapping_history.id
+
+  event_type varchar(17) NOT NULL CHECK (event_type IN ('notification', 'state_change', 'downtime_schedule', 'downtime_start', 'downtime_end', 'comment_add', 'comment_remove', 'flapping_start', 'flapping_end')),
+  event_time bigint NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE icingadb_schema (
+  id serial NOT NULL,
+  version integer NOT NULL,
+  timestamp bigint NOT NULL, -- *nix timestamp in milliseconds
+
+  PRIMARY KEY (id)
+);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));
@@ -0,0 +1,13 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Starts tracking the schema version of databases set up before icingadb_schema existed.
+
+CREATE TABLE icingadb_schema (
+  id serial NOT NULL,
+  version integer NOT NULL,
+  timestamp bigint NOT NULL, -- *nix timestamp in milliseconds
+
+  PRIMARY KEY (id)
+);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));
@@ -0,0 +1,35 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Adds the tables with the parsed performance data of the current host and service states, one row per label.
+
+CREATE TABLE host_perfdata (
+  host_id bytea NOT NULL, -- host.id
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  label varchar(255) NOT NULL,
+
+  value double precision DEFAULT NULL, -- NULL if unknown (U)
+  unit varchar(255) NOT NULL,
+  warn varchar(255) DEFAULT NULL, -- threshold range
+  crit varchar(255) DEFAULT NULL, -- threshold range
+  min double precision DEFAULT NULL,
+  max double precision DEFAULT NULL,
+
+  PRIMARY KEY (host_id, label)
+);
+
+CREATE TABLE service_perfdata (
+  service_id bytea NOT NULL, -- service.id
+  environment_id bytea NOT NULL, -- sha1(environment.name)
+  label varchar(255) NOT NULL,
+
+  value double precision DEFAULT NULL, -- NULL if unknown (U)
+  unit varchar(255) NOT NULL,
+  warn varchar(255) DEFAULT NULL, -- threshold range
+  crit varchar(255) DEFAULT NULL, -- threshold range
+  min double precision DEFAULT NULL,
+  max double precision DEFAULT NULL,
+
+  PRIMARY KEY (service_id, label)
+);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (3, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));
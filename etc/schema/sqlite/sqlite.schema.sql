@@ -0,0 +1,937 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+CREATE TABLE host (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  customvars_checksum BLOB NOT NULL, -- sha1(host.vars)
+  groups_checksum BLOB NOT NULL, -- sha1(hostgroup.name + hostgroup.name ...)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+
+  address TEXT NOT NULL,
+  address6 TEXT NOT NULL,
+  address_bin BLOB DEFAULT NULL,
+  address6_bin BLOB DEFAULT NULL,
+
+  checkcommand TEXT NOT NULL, -- checkcommand.name
+  checkcommand_id BLOB NOT NULL, -- checkcommand.id
+
+  max_check_attempts INTEGER NOT NULL,
+
+  check_timeperiod TEXT NOT NULL, -- timeperiod.name
+  check_timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id
+
+  check_timeout INTEGER DEFAULT NULL,
+  check_interval INTEGER NOT NULL,
+  check_retry_interval INTEGER NOT NULL,
+
+  active_checks_enabled TEXT NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),
+  passive_checks_enabled TEXT NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),
+  event_handler_enabled TEXT NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),
+  notifications_enabled TEXT NOT NULL CHECK (notifications_enabled IN ('y', 'n')),
+
+  flapping_enabled TEXT NOT NULL CHECK (flapping_enabled IN ('y', 'n')),
+  flapping_threshold_low REAL NOT NULL,
+  flapping_threshold_high REAL NOT NULL,
+
+  perfdata_enabled TEXT NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),
+
+  eventcommand TEXT NOT NULL, -- eventcommand.name
+  eventcommand_id BLOB DEFAULT NULL, -- eventcommand.id
+
+  is_volatile TEXT NOT NULL CHECK (is_volatile IN ('y', 'n')),
+
+  action_url_id BLOB DEFAULT NULL, -- action_url.id
+  notes_url_id BLOB DEFAULT NULL, -- notes_url.id
+  notes TEXT NOT NULL,
+  icon_image_id BLOB DEFAULT NULL, -- icon_image.id
+  icon_image_alt TEXT NOT NULL,
+
+  zone TEXT NOT NULL, -- zone.name
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  command_endpoint TEXT NOT NULL, -- endpoint.name
+  command_endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_action_url_checksum ON host (action_url_id); -- cleanup
+CREATE INDEX idx_notes_url_checksum ON host (notes_url_id); -- cleanup
+CREATE INDEX idx_icon_image_checksum ON host (icon_image_id); -- cleanup
+
+CREATE TABLE hostgroup (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  customvars_checksum BLOB NOT NULL, -- sha1(hostgroup.vars)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE hostgroup_member (
+  id BLOB NOT NULL, -- sha1(environment.name + host_id + hostgroup_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  host_id BLOB NOT NULL, -- host.id
+  hostgroup_id BLOB NOT NULL, -- hostgroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE host_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + host_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  host_id BLOB NOT NULL, -- host.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE hostgroup_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + hostgroup_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  hostgroup_id BLOB NOT NULL, -- hostgroup.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE host_state (
+  host_id BLOB NOT NULL, -- host.id
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+
+  state_type TEXT NOT NULL CHECK (state_type IN ('hard', 'soft')),
+  soft_state INTEGER NOT NULL,
+  hard_state INTEGER NOT NULL,
+  previous_hard_state INTEGER NOT NULL,
+  attempt INTEGER NOT NULL,
+  severity INTEGER NOT NULL,
+
+  output TEXT DEFAULT NULL,
+  long_output TEXT DEFAULT NULL,
+  performance_data TEXT DEFAULT NULL,
+  check_commandline TEXT DEFAULT NULL,
+
+  is_problem TEXT NOT NULL CHECK (is_problem IN ('y', 'n')),
+  is_handled TEXT NOT NULL CHECK (is_handled IN ('y', 'n')),
+  is_reachable TEXT NOT NULL CHECK (is_reachable IN ('y', 'n')),
+  is_flapping TEXT NOT NULL CHECK (is_flapping IN ('y', 'n')),
+
+  is_acknowledged TEXT NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),
+  acknowledgement_comment_id BLOB DEFAULT NULL, -- comment.id
+
+  in_downtime TEXT NOT NULL CHECK (in_downtime IN ('y', 'n')),
+
+  execution_time INTEGER DEFAULT NULL,
+  latency INTEGER DEFAULT NULL,
+  timeout INTEGER DEFAULT NULL,
+  check_source TEXT DEFAULT NULL,
+
+  last_update INTEGER NOT NULL,
+  last_state_change INTEGER NOT NULL,
+  next_check INTEGER NOT NULL,
+  next_update INTEGER NOT NULL,
+
+  PRIMARY KEY (host_id)
+);
+
+CREATE TABLE service (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  customvars_checksum BLOB NOT NULL, -- sha1(service.vars)
+  groups_checksum BLOB NOT NULL, -- sha1(servicegroup.name + servicegroup.name ...)
+  host_id BLOB NOT NULL, -- sha1(host.id)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+
+  checkcommand TEXT NOT NULL, -- checkcommand.name
+  checkcommand_id BLOB NOT NULL, -- checkcommand.id
+
+  max_check_attempts INTEGER NOT NULL,
+
+  check_timeperiod TEXT NOT NULL, -- timeperiod.name
+  check_timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id
+
+  check_timeout INTEGER DEFAULT NULL,
+  check_interval INTEGER NOT NULL,
+  check_retry_interval INTEGER NOT NULL,
+
+  active_checks_enabled TEXT NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),
+  passive_checks_enabled TEXT NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),
+  event_handler_enabled TEXT NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),
+  notifications_enabled TEXT NOT NULL CHECK (notifications_enabled IN ('y', 'n')),
+
+  flapping_enabled TEXT NOT NULL CHECK (flapping_enabled IN ('y', 'n')),
+  flapping_threshold_low REAL NOT NULL,
+  flapping_threshold_high REAL NOT NULL,
+
+  perfdata_enabled TEXT NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),
+
+  eventcommand TEXT NOT NULL, -- eventcommand.name
+  eventcommand_id BLOB DEFAULT NULL, -- eventcommand.id
+
+  is_volatile TEXT NOT NULL CHECK (is_volatile IN ('y', 'n')),
+
+  action_url_id BLOB DEFAULT NULL, -- action_url.id
+  notes_url_id BLOB DEFAULT NULL, -- notes_url.id
+  notes TEXT NOT NULL,
+  icon_image_id BLOB DEFAULT NULL, -- icon_image.id
+  icon_image_alt TEXT NOT NULL,
+
+  zone TEXT NOT NULL, -- zone.name
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  command_endpoint TEXT NOT NULL, -- endpoint.name
+  command_endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE servicegroup (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  customvars_checksum BLOB NOT NULL, -- sha1(servicegroup.vars)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE servicegroup_member (
+  id BLOB NOT NULL, -- sha1(environment.name + servicegroup_id + service_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  service_id BLOB NOT NULL, -- service.id
+  servicegroup_id BLOB NOT NULL, -- servicegroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE service_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + service_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  service_id BLOB NOT NULL, -- service.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE servicegroup_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + servicegroup_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  servicegroup_id BLOB NOT NULL, -- servicegroup.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE service_state (
+  service_id BLOB NOT NULL, -- service.id
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+
+  state_type TEXT NOT NULL CHECK (state_type IN ('hard', 'soft')),
+  soft_state INTEGER NOT NULL,
+  hard_state INTEGER NOT NULL,
+  previous_hard_state INTEGER NOT NULL,
+  attempt INTEGER NOT NULL,
+  severity INTEGER NOT NULL,
+
+  output TEXT DEFAULT NULL,
+  long_output TEXT DEFAULT NULL,
+  performance_data TEXT DEFAULT NULL,
+  check_commandline TEXT DEFAULT NULL,
+
+  is_problem TEXT NOT NULL CHECK (is_problem IN ('y', 'n')),
+  is_handled TEXT NOT NULL CHECK (is_handled IN ('y', 'n')),
+  is_reachable TEXT NOT NULL CHECK (is_reachable IN ('y', 'n')),
+  is_flapping TEXT NOT NULL CHECK (is_flapping IN ('y', 'n')),
+
+  is_acknowledged TEXT NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),
+  acknowledgement_comment_id BLOB DEFAULT NULL, -- comment.id
+
+  in_downtime TEXT NOT NULL CHECK (in_downtime IN ('y', 'n')),
+
+  execution_time INTEGER DEFAULT NULL,
+  latency INTEGER DEFAULT NULL,
+  timeout INTEGER DEFAULT NULL,
+  check_source TEXT DEFAULT NULL,
+
+  last_update INTEGER NOT NULL,
+  last_state_change INTEGER NOT NULL,
+  next_check INTEGER NOT NULL,
+  next_update INTEGER NOT NULL,
+
+  PRIMARY KEY (service_id)
+);
+
+CREATE TABLE endpoint (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL,
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+
+  zone_id BLOB NOT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE environment (
+  id BLOB NOT NULL, -- sha1(name)
+  name TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE icingadb_instance (
+  id BLOB NOT NULL, -- UUIDv4
+  environment_id BLOB NOT NULL, -- environment.id
+  heartbeat INTEGER NOT NULL, -- *nix timestamp
+  responsible TEXT NOT NULL CHECK (responsible IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand (
+  id BLOB NOT NULL, -- sha1(environment.name + type + name)
+  environment_id BLOB NOT NULL, -- env.id
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  command TEXT NOT NULL,
+  timeout INTEGER NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand_argument (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + argument_key)
+  environment_id BLOB NOT NULL, -- env.id
+  command_id BLOB NOT NULL, -- command.id
+  argument_key TEXT NOT NULL,
+
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  argument_value TEXT DEFAULT NULL,
+  argument_order INTEGER DEFAULT NULL,
+  description TEXT DEFAULT NULL,
+  argument_key_override TEXT DEFAULT NULL,
+  repeat_key TEXT NOT NULL CHECK (repeat_key IN ('y', 'n')),
+  required TEXT NOT NULL CHECK (required IN ('y', 'n')),
+  set_if TEXT DEFAULT NULL,
+  skip_key TEXT NOT NULL CHECK (skip_key IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand_envvar (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + envvar_key)
+  environment_id BLOB NOT NULL, -- env.id
+  command_id BLOB NOT NULL, -- command.id
+  envvar_key TEXT NOT NULL,
+
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  envvar_value TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE checkcommand_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+
+  command_id BLOB NOT NULL, -- command.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand (
+  id BLOB NOT NULL, -- sha1(environment.name + type + name)
+  environment_id BLOB NOT NULL, -- env.id
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  command TEXT NOT NULL,
+  timeout INTEGER NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand_argument (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + argument_key)
+  environment_id BLOB NOT NULL, -- env.id
+  command_id BLOB NOT NULL, -- command.id
+  argument_key TEXT NOT NULL,
+
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  argument_value TEXT DEFAULT NULL,
+  argument_order INTEGER DEFAULT NULL,
+  description TEXT DEFAULT NULL,
+  argument_key_override TEXT DEFAULT NULL,
+  repeat_key TEXT NOT NULL CHECK (repeat_key IN ('y', 'n')),
+  required TEXT NOT NULL CHECK (required IN ('y', 'n')),
+  set_if TEXT DEFAULT NULL,
+  skip_key TEXT NOT NULL CHECK (skip_key IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand_envvar (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + envvar_key)
+  environment_id BLOB NOT NULL, -- env.id
+  command_id BLOB NOT NULL, -- command.id
+  envvar_key TEXT NOT NULL,
+
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  envvar_value TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE eventcommand_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  command_id BLOB NOT NULL, -- command.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand (
+  id BLOB NOT NULL, -- sha1(environment.name + type + name)
+  environment_id BLOB NOT NULL, -- env.id
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  command TEXT NOT NULL,
+  timeout INTEGER NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand_argument (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + argument_key)
+  environment_id BLOB NOT NULL, -- env.id
+  command_id BLOB NOT NULL, -- command.id
+  argument_key TEXT NOT NULL,
+
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  argument_value TEXT DEFAULT NULL,
+  argument_order INTEGER DEFAULT NULL,
+  description TEXT DEFAULT NULL,
+  argument_key_override TEXT DEFAULT NULL,
+  repeat_key TEXT NOT NULL CHECK (repeat_key IN ('y', 'n')),
+  required TEXT NOT NULL CHECK (required IN ('y', 'n')),
+  set_if TEXT DEFAULT NULL,
+  skip_key TEXT NOT NULL CHECK (skip_key IN ('y', 'n')),
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand_envvar (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + envvar_key)
+  environment_id BLOB NOT NULL, -- env.id
+  command_id BLOB NOT NULL, -- command.id
+  envvar_key TEXT NOT NULL,
+
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  envvar_value TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notificationcommand_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + command_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  command_id BLOB NOT NULL, -- command.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE comment (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- environment.id
+
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB DEFAULT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL,
+  name TEXT NOT NULL,
+
+  author TEXT NOT NULL,
+  "text" TEXT NOT NULL,
+  entry_type TEXT NOT NULL CHECK (entry_type IN ('comment', 'ack')),
+  entry_time INTEGER NOT NULL,
+  is_persistent TEXT NOT NULL CHECK (is_persistent IN ('y', 'n')),
+  is_sticky TEXT NOT NULL CHECK (is_sticky IN ('y', 'n')),
+  expire_time INTEGER DEFAULT NULL,
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE downtime (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- environment.id
+
+  triggered_by_id BLOB DEFAULT NULL, -- downtime.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB DEFAULT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  name TEXT NOT NULL,
+
+  author TEXT NOT NULL,
+  comment TEXT NOT NULL,
+  entry_time INTEGER NOT NULL,
+  scheduled_start_time INTEGER NOT NULL,
+  scheduled_end_time INTEGER NOT NULL,
+  flexible_duration INTEGER NOT NULL,
+  is_flexible TEXT NOT NULL CHECK (is_flexible IN ('y', 'n')),
+
+  is_in_effect TEXT NOT NULL CHECK (is_in_effect IN ('y', 'n')),
+  start_time INTEGER DEFAULT NULL, -- Time when the host went into a problem state during the downtimes timeframe
+  end_time INTEGER DEFAULT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + flexible_duration otherwise
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL,
+  customvars_checksum BLOB NOT NULL, -- sha1(notification.vars)
+  users_checksum BLOB NOT NULL,
+  usergroups_checksum BLOB NOT NULL,
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+  command_id BLOB NOT NULL, -- command.id
+
+  times_begin INTEGER DEFAULT NULL,
+  times_end INTEGER DEFAULT NULL,
+  notification_interval INTEGER NOT NULL,
+  timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id
+
+  states INTEGER NOT NULL,
+  types INTEGER NOT NULL,
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification_user (
+  id BLOB NOT NULL, -- sha1(environment.name + notification_id + user_id)
+  environment_id BLOB NOT NULL, -- environment.id
+  notification_id BLOB NOT NULL, -- notification.id
+  user_id BLOB NOT NULL, -- user.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification_usergroup (
+  id BLOB NOT NULL, -- sha1(environment.name + notification_id + usergroup_id)
+  environment_id BLOB NOT NULL, -- environment.id
+  notification_id BLOB NOT NULL, -- notification.id
+  usergroup_id BLOB NOT NULL, -- usergroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE notification_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + notification_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  notification_id BLOB NOT NULL, -- notification.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE icon_image (
+  id BLOB NOT NULL, -- sha1(icon_image)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  icon_image TEXT NOT NULL,
+
+  PRIMARY KEY (environment_id, id)
+);
+CREATE INDEX idx_icon_image ON icon_image (icon_image);
+
+CREATE TABLE action_url (
+  id BLOB NOT NULL, -- sha1(action_url)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  action_url TEXT NOT NULL,
+
+  PRIMARY KEY (environment_id, id)
+);
+CREATE INDEX idx_action_url ON action_url (action_url);
+
+CREATE TABLE notes_url (
+  id BLOB NOT NULL, -- sha1(notes_url)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  notes_url TEXT NOT NULL,
+
+  PRIMARY KEY (environment_id, id)
+);
+CREATE INDEX idx_notes_url ON notes_url (notes_url);
+
+CREATE TABLE timeperiod (
+  id BLOB NOT NULL, -- sha1(env.name + name)
+  environment_id BLOB NOT NULL, -- env.id
+
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+  prefer_includes TEXT NOT NULL CHECK (prefer_includes IN ('y', 'n')),
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_range (
+  id BLOB NOT NULL, -- sha1(environment.name + range_id + timeperiod_id)
+  environment_id BLOB NOT NULL, -- env.id
+  timeperiod_id BLOB NOT NULL, -- timeperiod.id
+  range_key TEXT NOT NULL,
+
+  range_value TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_override_include (
+  id BLOB NOT NULL, -- sha1(environment.name + include_id + timeperiod_id)
+  environment_id BLOB NOT NULL, -- env.id
+  timeperiod_id BLOB NOT NULL, -- timeperiod.id
+  override_id BLOB NOT NULL, -- timeperiod.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_override_exclude (
+  id BLOB NOT NULL, -- sha1(environment.name + exclude_id + timeperiod_id)
+  environment_id BLOB NOT NULL, -- env.id
+  timeperiod_id BLOB NOT NULL, -- timeperiod.id
+  override_id BLOB NOT NULL, -- timeperiod.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE timeperiod_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + timeperiod_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  timeperiod_id BLOB NOT NULL, -- timeperiod.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + name + value)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+
+  name TEXT NOT NULL,
+  value TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE customvar_flat (
+  id BLOB NOT NULL, -- sha1(environment.name + flatname + flatvalue)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  customvar_id BLOB NOT NULL, -- sha1(customvar.id)
+  flatname_checksum BLOB NOT NULL, -- sha1(flatname after conversion)
+
+  flatname TEXT NOT NULL, -- Path converted with `.` and `[ ]`
+  flatvalue TEXT NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE "user" (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  customvars_checksum BLOB NOT NULL, -- sha1(user.vars)
+  groups_checksum BLOB NOT NULL, -- sha1(usergroup.name + userroup.name ...)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+
+  email TEXT NOT NULL,
+  pager TEXT NOT NULL,
+
+  notifications_enabled TEXT NOT NULL CHECK (notifications_enabled IN ('y', 'n')),
+
+  timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id
+
+  states INTEGER NOT NULL,
+  types INTEGER NOT NULL,
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE usergroup (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  customvars_checksum BLOB NOT NULL, -- sha1(usergroup.vars)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+  display_name TEXT NOT NULL,
+
+  zone_id BLOB DEFAULT NULL, -- zone.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE usergroup_member (
+  id BLOB NOT NULL, -- sha1(environment.name + usergroup_id + user_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  user_id BLOB NOT NULL, -- user.id
+  usergroup_id BLOB NOT NULL, -- usergroup.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE user_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + user_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  user_id BLOB NOT NULL, -- user.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE usergroup_customvar (
+  id BLOB NOT NULL, -- sha1(environment.name + usergroup_id + customvar_id)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  usergroup_id BLOB NOT NULL, -- usergroup.id
+  customvar_id BLOB NOT NULL, -- customvar.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE zone (
+  id BLOB NOT NULL, -- sha1(environment.name + name)
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  name_checksum BLOB NOT NULL, -- sha1(name)
+  properties_checksum BLOB NOT NULL, -- sha1(all properties)
+  parents_checksum BLOB NOT NULL, -- sha1(all parents checksums)
+
+  name TEXT NOT NULL,
+  name_ci TEXT NOT NULL,
+
+  is_global TEXT NOT NULL CHECK (is_global IN ('y', 'n')),
+  parent_id BLOB DEFAULT NULL, -- zone.id
+
+  depth INTEGER NOT NULL,
+
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_parent_id ON zone (parent_id);
+CREATE UNIQUE INDEX idx_environment_id_id ON zone (environment_id,id);
+
+CREATE TABLE notification_history (
+  id BLOB NOT NULL, -- UUID
+  environment_id BLOB NOT NULL, -- environment.id
+  endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+  notification_id BLOB NOT NULL, -- notification.id
+
+  type TEXT NOT NULL CHECK (type IN ('downtime_start', 'downtime_end', 'downtime_removed', 'custom', 'acknowledgement', 'problem', 'recovery', 'flapping_start', 'flapping_end')),
+  send_time INTEGER NOT NULL,
+  state INTEGER NOT NULL,
+  previous_hard_state INTEGER NOT NULL,
+  author TEXT NOT NULL,
+  "text" TEXT NOT NULL,
+  users_notified INTEGER NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE user_notification_history (
+  id BLOB NOT NULL, -- UUID
+  environment_id BLOB NOT NULL, -- environment.id
+  notification_history_id BLOB NOT NULL, -- UUID notification_history.id
+  user_id BLOB NOT NULL, -- user.id
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE state_history (
+  id BLOB NOT NULL, -- UUID
+  environment_id BLOB NOT NULL, -- environment.id
+  endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+
+  event_time INTEGER NOT NULL,
+  state_type TEXT NOT NULL CHECK (state_type IN ('hard', 'soft')),
+  soft_state INTEGER NOT NULL,
+  hard_state INTEGER NOT NULL,
+  previous_soft_state INTEGER NOT NULL,
+  previous_hard_state INTEGER NOT NULL,
+  attempt INTEGER NOT NULL,
+  output TEXT DEFAULT NULL,
+  long_output TEXT DEFAULT NULL,
+  max_check_attempts INTEGER NOT NULL,
+  check_source TEXT DEFAULT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE downtime_history (
+  downtime_id BLOB NOT NULL, -- downtime.id
+  environment_id BLOB NOT NULL, -- environment.id
+  endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+  triggered_by_id BLOB DEFAULT NULL, -- downtime.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+
+  entry_time INTEGER NOT NULL,
+  author TEXT NOT NULL,
+  comment TEXT NOT NULL,
+  is_flexible TEXT NOT NULL CHECK (is_flexible IN ('y', 'n')),
+  flexible_duration INTEGER NOT NULL,
+  scheduled_start_time INTEGER NOT NULL,
+  scheduled_end_time INTEGER NOT NULL,
+  start_time INTEGER NOT NULL, -- Time when the host went into a problem state during the downtimes timeframe
+  end_time INTEGER NOT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + duration otherwise
+  has_been_cancelled TEXT NOT NULL CHECK (has_been_cancelled IN ('y', 'n')),
+  trigger_time INTEGER NOT NULL,
+  cancel_time INTEGER DEFAULT NULL,
+
+  PRIMARY KEY (downtime_id)
+);
+
+CREATE TABLE comment_history (
+  comment_id BLOB NOT NULL, -- comment.id
+  environment_id BLOB NOT NULL, -- environment.id
+  endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+
+  entry_time INTEGER NOT NULL,
+  author TEXT NOT NULL,
+  comment TEXT NOT NULL,
+  entry_type TEXT NOT NULL CHECK (entry_type IN ('comment', 'ack')),
+  is_persistent TEXT NOT NULL CHECK (is_persistent IN ('y', 'n')),
+  is_sticky TEXT NOT NULL CHECK (is_sticky IN ('y', 'n')),
+  expire_time INTEGER DEFAULT NULL,
+  remove_time INTEGER DEFAULT NULL,
+  has_been_removed TEXT NOT NULL CHECK (has_been_removed IN ('y', 'n')),
+
+  PRIMARY KEY (comment_id)
+);
+
+CREATE TABLE flapping_history (
+  id BLOB NOT NULL, -- UUID
+  environment_id BLOB NOT NULL, -- environment.id
+  endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+
+  event_time INTEGER NOT NULL,
+  percent_state_change REAL NOT NULL,
+  flapping_threshold_low REAL NOT NULL,
+  flapping_threshold_high REAL NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE history (
+  id BLOB NOT NULL, -- notification_history_id, state_history_id, flapping_history_id or UUID
+  environment_id BLOB NOT NULL, -- environment.id
+  endpoint_id BLOB DEFAULT NULL, -- endpoint.id
+  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),
+  host_id BLOB NOT NULL, -- host.id
+  service_id BLOB DEFAULT NULL, -- service.id
+  notification_history_id BLOB DEFAULT NULL, -- notification_history.id
+  state_history_id BLOB DEFAULT NULL, -- state_history.id
+  downtime_history_id BLOB DEFAULT NULL, -- downtime_history.downtime_id
+  comment_history_id BLOB DEFAULT NULL, -- comment_history.comment_id
+  flapping_history_id BLOB DEFAULT NULL, -- flapping_history.id
+
+  event_type TEXT NOT NULL CHECK (event_type IN ('notification', 'state_change', 'downtime_schedule', 'downtime_start', 'downtime_end', 'comment_add', 'comment_remove', 'flapping_start', 'flapping_end')),
+  event_time INTEGER NOT NULL,
+
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE icingadb_schema (
+  id INTEGER NOT NULL,
+  version INTEGER NOT NULL,
+  timestamp INTEGER NOT NULL, -- *nix timestamp in milliseconds
+
+  PRIMARY KEY (id)
+);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(strftime('%s', 'now') AS INTEGER) * 1000);
@@ -0,0 +1,13 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Starts tracking the schema version of databases set up before icingadb_schema existed.
+
+CREATE TABLE icingadb_schema (
+  id INTEGER NOT NULL,
+  version INTEGER NOT NULL,
+  timestamp INTEGER NOT NULL, -- *nix timestamp in milliseconds
+
+  PRIMARY KEY (id)
+);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(strftime('%s', 'now') AS INTEGER) * 1000);
@@ -0,0 +1,35 @@
+-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+--
+-- Adds the tables with the parsed performance data of the current host and service states, one row per label.
+
+CREATE TABLE host_perfdata (
+  host_id BLOB NOT NULL, -- host.id
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  label TEXT NOT NULL,
+
+  value REAL DEFAULT NULL, -- NULL if unknown (U)
+  unit TEXT NOT NULL,
+  warn TEXT DEFAULT NULL, -- threshold range
+  crit TEXT DEFAULT NULL, -- threshold range
+  min REAL DEFAULT NULL,
+  max REAL DEFAULT NULL,
+
+  PRIMARY KEY (host_id, label)
+);
+
+CREATE TABLE service_perfdata (
+  service_id BLOB NOT NULL, -- service.id
+  environment_id BLOB NOT NULL, -- sha1(environment.name)
+  label TEXT NOT NULL,
+
+  value REAL DEFAULT NULL, -- NULL if unknown (U)
+  unit TEXT NOT NULL,
+  warn TEXT DEFAULT NULL, -- threshold range
+  crit TEXT DEFAULT NULL, -- threshold range
+  min REAL DEFAULT NULL,
+  max REAL DEFAULT NULL,
+
+  PRIMARY KEY (service_id, label)
+);
+
+INSERT INTO icingadb_schema (version, timestamp) VALUES (3, CAST(strftime('%s', 'now') AS INTEGER) * 1000);
@@ -0,0 +1,155 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package events converts the entries of the state and history streams into events with the names of their host and
+// service, as posted to webhooks and streamed to subscribers.
+package events
+
+import (
+	"encoding/hex"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// Event is a state update or history event.
+type Event struct {
+	ID string `json:"id"`
+	// Type is "state" for state updates, otherwise the event_type of the history, e.g. "state_change".
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	ObjectType string    `json:"object_type"`
+	Host       string    `json:"host"`
+	Service    string    `json:"service,omitempty"`
+	// Data are the values of the stream entry, e.g. "output" and "hard_state" of a state change.
+	Data map[string]string `json:"data"`
+}
+
+// Resolve returns the names of a host or service.
+type Resolve func(objectType string, id []byte) (host string, service string, err error)
+
+// New returns the event of a stream entry about the host or service with the given ID.
+// If its names can't be resolved, they're left empty.
+func New(
+	resolve Resolve, id string, eventType string, eventTime time.Time, objectType string, objectId []byte,
+	values map[string]interface{},
+) *Event {
+	event := &Event{ID: id, Type: eventType, Time: eventTime, ObjectType: objectType, Data: map[string]string{}}
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			event.Data[key] = s
+		}
+	}
+
+	if len(objectId) > 0 {
+		host, service, err := resolve(objectType, objectId)
+		if err != nil {
+			log.WithFields(log.Fields{"context": "Events", "event": id}).Debugf("Can't resolve names: %s", err)
+		}
+
+		event.Host, event.Service = host, service
+	}
+
+	return event
+}
+
+// FromHistory returns the event of a history stream entry.
+func FromHistory(resolve Resolve, eventType string, eventTime time.Time, values map[string]interface{}) *Event {
+	id, _ := values["event_id"].(string)
+	objectType, _ := values["object_type"].(string)
+
+	idKey := "host_id"
+	if objectType == "service" {
+		idKey = "service_id"
+	}
+
+	objectId, _ := values[idKey].(string)
+	decoded, _ := hex.DecodeString(objectId)
+
+	return New(resolve, id, eventType, eventTime, objectType, decoded, values)
+}
+
+// Filter selects events by their type and the names of their host and service. Empty lists match all events.
+type Filter struct {
+	Types []string
+	// Hosts and Services are glob patterns, see Glob. If Services isn't empty, only events of services match.
+	Hosts    []string
+	Services []string
+}
+
+// Matches returns whether event passes the filter.
+func (f *Filter) Matches(event *Event) bool {
+	if len(f.Types) > 0 && !contains(f.Types, event.Type) {
+		return false
+	}
+
+	if len(f.Hosts) > 0 && !matchesAny(f.Hosts, event.Host) {
+		return false
+	}
+
+	if len(f.Services) > 0 && (event.ObjectType != "service" || !matchesAny(f.Services, event.Service)) {
+		return false
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesAny returns whether name matches one of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Glob(strings.TrimSpace(pattern), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Glob returns whether name matches pattern, in which * matches any string, including "/", and ? any character.
+func Glob(pattern, name string) bool {
+	p, n := []rune(pattern), []rune(name)
+	// The position after the last * and the position in n it's matched up to so far.
+	star, matched := -1, 0
+
+	for i, j := 0, 0; j < len(n); {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == n[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			i++
+			star, matched = i, j
+		case star >= 0:
+			// Let the last * match one more character.
+			matched++
+			i, j = star, matched
+		default:
+			return false
+		}
+
+		if j == len(n) {
+			for i < len(p) && p[i] == '*' {
+				i++
+			}
+
+			return i == len(p)
+		}
+	}
+
+	for _, r := range p {
+		if r != '*' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,70 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package events
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFromHistory(t *testing.T) {
+	var resolved []string
+	resolve := func(objectType string, id []byte) (string, string, error) {
+		resolved = append(resolved, objectType+" "+string(id))
+		return "db-1", "disk /", nil
+	}
+
+	at := time.Unix(1577836800, 0)
+	event := FromHistory(resolve, "state_change", at, map[string]interface{}{
+		"event_id": "42", "object_type": "service", "host_id": "00", "service_id": "7365727669636531",
+		"output": "DISK CRITICAL", "check_attempt": 3,
+	})
+
+	assert.Equal(t, []string{"service service1"}, resolved)
+	assert.Equal(t, &Event{
+		ID: "42", Type: "state_change", Time: at, ObjectType: "service", Host: "db-1", Service: "disk /",
+		Data: map[string]string{
+			"event_id": "42", "object_type": "service", "host_id": "00", "service_id": "7365727669636531",
+			"output": "DISK CRITICAL",
+		},
+	}, event)
+
+	event = FromHistory(resolve, "state_change", at, map[string]interface{}{"event_id": "43", "object_type": "host"})
+	assert.Len(t, resolved, 1, "entries without object ID must not be resolved")
+	assert.Equal(t, "", event.Host)
+}
+
+func TestGlob(t *testing.T) {
+	for _, c := range []struct {
+		pattern, name string
+		matches       bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*", "disk /var/log", true},
+		{"db-*", "db-1", true},
+		{"db-*", "web-1", false},
+		{"disk *", "disk /", true},
+		{"*-?", "db-1", true},
+		{"*-?", "db-12", false},
+		{"a*b*c", "axxbyybc", true},
+		{"a*b", "abc", false},
+		{"a**", "a", true},
+	} {
+		assert.Equal(t, c.matches, Glob(c.pattern, c.name), "%q %q", c.pattern, c.name)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	f := &Filter{Types: []string{"state_change", " notification"}, Hosts: []string{"db-*"}}
+
+	assert.True(t, f.Matches(&Event{Type: "notification", ObjectType: "host", Host: "db-1"}))
+	assert.False(t, f.Matches(&Event{Type: "comment_add", ObjectType: "host", Host: "db-1"}))
+	assert.False(t, f.Matches(&Event{Type: "state_change", ObjectType: "host", Host: "web-1"}))
+
+	f.Services = []string{"disk *"}
+	assert.True(t, f.Matches(&Event{Type: "state_change", ObjectType: "service", Host: "db-1", Service: "disk /"}))
+	assert.False(t, f.Matches(&Event{Type: "state_change", ObjectType: "service", Host: "db-1", Service: "load"}))
+	assert.False(t, f.Matches(&Event{Type: "state_change", ObjectType: "host", Host: "db-1"}))
+}
@@ -0,0 +1,136 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package eventstream streams state updates and history events to HTTP subscribers via Server-Sent Events
+// or WebSocket as they're synced.
+package eventstream
+
+import (
+	"errors"
+	"github.com/Icinga/icingadb/events"
+	"github.com/go-redis/redis"
+	"sync"
+	"time"
+)
+
+// ErrTooManySubscribers is returned by Broker.subscribe if the maximum number of subscribers is connected.
+var ErrTooManySubscribers = errors.New("too many subscribers")
+
+// Filter selects the events of a subscriber.
+type Filter struct {
+	events.Filter
+	// GroupHosts are the names of the hosts of the selected host groups, nil if not filtered by host group.
+	GroupHosts map[string]bool
+}
+
+// Matches returns whether event passes the filter.
+func (f *Filter) Matches(event *events.Event) bool {
+	return f.Filter.Matches(event) && (f.GroupHosts == nil || f.GroupHosts[event.Host])
+}
+
+// subscriber receives the events matching its filter.
+type subscriber struct {
+	filter Filter
+	events chan *events.Event
+	// overflowed is set before events is closed because the subscriber fell behind by more than its buffer.
+	overflowed bool
+}
+
+// Broker publishes events to the subscribers whose filters they match.
+// All of its methods may be called on nil, which publishes nothing.
+type Broker struct {
+	resolve events.Resolve
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// NewBroker returns a Broker resolving the names of the published hosts and services with resolve.
+func NewBroker(resolve events.Resolve) *Broker {
+	return &Broker{resolve: resolve, subscribers: map[*subscriber]struct{}{}}
+}
+
+// PublishState publishes the state update of the host or service id, i.e. the state stream entry, at the given time.
+func (b *Broker) PublishState(objectType string, id []byte, entry redis.XMessage, updateTime time.Time) {
+	if !b.hasSubscribers() {
+		return
+	}
+
+	b.publish(events.New(b.resolve, entry.ID, "state", updateTime, objectType, id, entry.Values))
+}
+
+// PublishHistory publishes the event of a history stream entry.
+func (b *Broker) PublishHistory(eventType string, eventTime time.Time, values map[string]interface{}) {
+	if !b.hasSubscribers() {
+		return
+	}
+
+	b.publish(events.FromHistory(b.resolve, eventType, eventTime, values))
+}
+
+// hasSubscribers returns whether anyone is subscribed, so that no names are resolved for nobody.
+func (b *Broker) hasSubscribers() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers) > 0
+}
+
+// publish queues event for the matching subscribers without blocking.
+// Subscribers whose buffer is full are dropped, so that a slow one doesn't hold up the sync.
+func (b *Broker) publish(event *events.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	PublishedEventsTotal.Inc()
+
+	for s := range b.subscribers {
+		if !s.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			s.overflowed = true
+			b.remove(s)
+			OverflowsTotal.Inc()
+		}
+	}
+}
+
+// subscribe adds a subscriber with the given filter and buffer size, unless there are maxSubscribers already.
+func (b *Broker) subscribe(filter Filter, bufferSize int, maxSubscribers int) (*subscriber, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.subscribers) >= maxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+
+	s := &subscriber{filter: filter, events: make(chan *events.Event, bufferSize)}
+	b.subscribers[s] = struct{}{}
+	Subscribers.Set(float64(len(b.subscribers)))
+
+	return s, nil
+}
+
+// unsubscribe removes s unless it has been dropped already.
+func (b *Broker) unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(s)
+}
+
+// remove removes s and closes its events. b.mu must be locked.
+func (b *Broker) remove(s *subscriber) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.events)
+		Subscribers.Set(float64(len(b.subscribers)))
+	}
+}
@@ -0,0 +1,91 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package eventstream
+
+import (
+	"github.com/Icinga/icingadb/events"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func resolveHost(objectType string, id []byte) (string, string, error) {
+	return string(id), "", nil
+}
+
+func TestBroker(t *testing.T) {
+	var b *Broker
+	b.PublishHistory("state_change", time.Now(), nil)
+	require.False(t, b.hasSubscribers(), "a nil broker must publish nothing")
+
+	b = NewBroker(resolveHost)
+
+	all, err := b.subscribe(Filter{}, 10, 3)
+	require.NoError(t, err)
+
+	states, err := b.subscribe(Filter{Filter: events.Filter{Types: []string{"state"}}}, 10, 3)
+	require.NoError(t, err)
+
+	linux, err := b.subscribe(Filter{GroupHosts: map[string]bool{"web-1": true}}, 10, 3)
+	require.NoError(t, err)
+
+	_, err = b.subscribe(Filter{}, 10, 3)
+	assert.Equal(t, ErrTooManySubscribers, err)
+
+	at := time.Unix(1577836800, 0)
+	b.PublishState("host", []byte("web-1"), redis.XMessage{ID: "1-0", Values: map[string]interface{}{"state": "1"}}, at)
+	b.PublishHistory("state_change", at, map[string]interface{}{
+		"event_id": "42", "object_type": "host", "host_id": "64622d31",
+	})
+
+	event := <-all.events
+	assert.Equal(t, &events.Event{
+		ID: "1-0", Type: "state", Time: at, ObjectType: "host", Host: "web-1", Data: map[string]string{"state": "1"},
+	}, event)
+	assert.Equal(t, "42", (<-all.events).ID)
+
+	assert.Equal(t, event, <-states.events)
+	assert.Len(t, states.events, 0)
+
+	assert.Equal(t, event, <-linux.events)
+	assert.Len(t, linux.events, 0, "events of hosts outside the host groups must be filtered")
+
+	b.unsubscribe(states)
+	b.unsubscribe(states)
+
+	_, ok := <-states.events
+	assert.False(t, ok)
+	assert.False(t, states.overflowed)
+}
+
+func TestBroker_Overflow(t *testing.T) {
+	b := NewBroker(resolveHost)
+
+	slow, err := b.subscribe(Filter{}, 2, 2)
+	require.NoError(t, err)
+
+	fast, err := b.subscribe(Filter{}, 10, 2)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		b.PublishHistory("notification", time.Now(), map[string]interface{}{"event_id": "1"})
+	}
+
+	assert.Len(t, fast.events, 3)
+
+	received := 0
+	for range slow.events {
+		received++
+	}
+
+	assert.Equal(t, 2, received, "the buffered events must be delivered before the overflow")
+	assert.True(t, slow.overflowed)
+
+	// The subscriber has been dropped already.
+	b.unsubscribe(slow)
+
+	_, err = b.subscribe(Filter{}, 1, 2)
+	assert.NoError(t, err, "dropped subscribers must make room for new ones")
+}
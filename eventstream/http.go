@@ -0,0 +1,238 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/events"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/Icinga/icingadb/utils"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Path is the path the event stream is served at.
+const Path = "/v1/events"
+
+// keepaliveInterval is how often an idle Server-Sent Events stream gets a comment, so that proxies keep it open.
+const keepaliveInterval = 30 * time.Second
+
+var hostgroupObserver = connection.DbIoSeconds.WithLabelValues("mysql", "select event stream hostgroup")
+
+// overflow is sent to subscribers before they're disconnected because they fell behind.
+type overflow struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// NewHandler returns the handler of Path. If enabled in the settings returned by settings, it streams the events
+// published by b which match the filter of the query to each subscriber, via WebSocket if requested by the client
+// and via Server-Sent Events otherwise. If the settings have a token, it's required as bearer token.
+// WebSocket connections opened by browsers are only accepted from pages of the same origin.
+//
+// Only the HA responsible instance syncs states and history and thus publishes events,
+// subscribers of the others get none until it takes over.
+//
+// The query filters by host, service, hostgroup and type, each may be given multiple times.
+// Hosts and services may contain * and ? wildcards.
+func NewHandler(super *supervisor.Supervisor, b *Broker, settings func() *config.EventStreamInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := settings()
+		if !info.Enabled {
+			utils.WriteJSONError(w, http.StatusNotFound, "the event stream is disabled, set [event_stream] enabled to enable it")
+			return
+		}
+
+		if info.Token != "" && !utils.HasBearerToken(r, info.Token) {
+			utils.WriteJSONError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+
+		query := r.URL.Query()
+		filter := Filter{Filter: events.Filter{Types: query["type"], Hosts: query["host"], Services: query["service"]}}
+
+		if hostgroups := query["hostgroup"]; len(hostgroups) > 0 {
+			envId := super.EnvId
+			if envId == nil || !super.Dbw.IsConnected() {
+				utils.WriteJSONError(w, http.StatusServiceUnavailable, "database not available yet")
+				return
+			}
+
+			hosts, err := fetchGroupHosts(super.Dbw, envId, hostgroups)
+			if err != nil {
+				log.WithFields(log.Fields{"context": "EventStream"}).Error(err)
+				utils.WriteJSONError(w, http.StatusInternalServerError, "can't query the database")
+				return
+			}
+
+			filter.GroupHosts = hosts
+		}
+
+		s, err := b.subscribe(filter, info.BufferSize, info.MaxSubscribers)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+
+		defer b.unsubscribe(s)
+
+		notice := &overflow{
+			Type:  "overflow",
+			Error: fmt.Sprintf("fell behind by more than %d events, reconnect to resume", info.BufferSize),
+		}
+
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			websocket.Server{
+				Handshake: checkOrigin,
+				Handler:   func(ws *websocket.Conn) { serveWebSocket(ws, s, notice) },
+			}.ServeHTTP(w, r)
+		} else {
+			serveSSE(w, r, s, notice)
+		}
+	})
+}
+
+// checkOrigin rejects WebSocket connections opened by pages of other origins than the event stream itself,
+// as browsers don't apply the same-origin policy to them. Clients other than browsers usually send no Origin.
+func checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+
+	if u.Host != r.Host {
+		return fmt.Errorf("cross-origin WebSocket from %s", origin)
+	}
+
+	config.Origin = u
+
+	return nil
+}
+
+// serveSSE writes the events of s to w as Server-Sent Events until the client disconnects or s overflows.
+func serveSSE(w http.ResponseWriter, r *http.Request, s *subscriber, notice *overflow) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			_, err = io.WriteString(w, ": keepalive\n\n")
+		case event, ok := <-s.events:
+			if !ok {
+				if s.overflowed {
+					data, _ := json.Marshal(notice)
+					fmt.Fprintf(w, "event: overflow\ndata: %s\n\n", data)
+					flusher.Flush()
+				}
+
+				return
+			}
+
+			var data []byte
+			if data, err = json.Marshal(event); err == nil {
+				_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", event.ID, data)
+			}
+		}
+
+		if err != nil {
+			log.WithFields(log.Fields{"context": "EventStream", "error": err}).Debug("Can't write event")
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// serveWebSocket sends the events of s to ws as JSON text frames until the client disconnects or s overflows.
+func serveWebSocket(ws *websocket.Conn, s *subscriber, notice *overflow) {
+	// Subscribers don't send anything, reading just notices when they close the connection.
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, ws)
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-s.events:
+			if !ok {
+				if s.overflowed {
+					_ = websocket.JSON.Send(ws, notice)
+				}
+
+				return
+			}
+
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				log.WithFields(log.Fields{"context": "EventStream", "error": err}).Debug("Can't send event")
+				return
+			}
+		}
+	}
+}
+
+// fetchGroupHosts returns the names of the hosts of the given host groups in the environment envId.
+func fetchGroupHosts(dbw *connection.DBWrapper, envId []byte, hostgroups []string) (map[string]bool, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(hostgroups)), ", ")
+	args := []interface{}{envId}
+	for _, name := range hostgroups {
+		args = append(args, name)
+	}
+
+	rows, err := dbw.SqlFetchAll(
+		hostgroupObserver,
+		"SELECT h.name FROM host h JOIN hostgroup_member hgm ON hgm.host_id = h.id "+
+			"JOIN hostgroup hg ON hg.id = hgm.hostgroup_id WHERE hg.environment_id = ? AND hg.name IN ("+placeholders+")",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if name, ok := row[0].(string); ok {
+			hosts[name] = true
+		}
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,154 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package eventstream
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/events"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForSubscribers waits until b has count subscribers.
+func waitForSubscribers(t *testing.T, b *Broker, count int) {
+	for i := 0; i < 100; i++ {
+		b.mu.Lock()
+		n := len(b.subscribers)
+		b.mu.Unlock()
+
+		if n == count {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("expected %d subscribers", count)
+}
+
+func TestHandler(t *testing.T) {
+	b := NewBroker(resolveHost)
+	settings := &config.EventStreamInfo{BufferSize: 10, MaxSubscribers: 10}
+	server := httptest.NewServer(NewHandler(&supervisor.Supervisor{}, b, func() *config.EventStreamInfo {
+		return settings
+	}))
+	defer server.Close()
+
+	get := func(authorization string) int {
+		request, err := http.NewRequest("GET", server.URL+Path, nil)
+		require.NoError(t, err)
+
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(""), "the event stream must be disabled by default")
+
+	settings.Enabled = true
+	settings.Token = "secret"
+	assert.Equal(t, http.StatusUnauthorized, get("Bearer guess"))
+
+	settings.Token = ""
+	settings.MaxSubscribers = 0
+	assert.Equal(t, http.StatusServiceUnavailable, get(""))
+}
+
+func TestHandler_SSE(t *testing.T) {
+	b := NewBroker(resolveHost)
+	settings := &config.EventStreamInfo{Enabled: true, BufferSize: 10, MaxSubscribers: 10}
+	server := httptest.NewServer(NewHandler(&supervisor.Supervisor{}, b, func() *config.EventStreamInfo {
+		return settings
+	}))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + Path + "?type=notification")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	waitForSubscribers(t, b, 1)
+
+	b.PublishHistory("state_change", time.Now(), map[string]interface{}{"event_id": "1"})
+	b.PublishHistory("notification", time.Now(), map[string]interface{}{"event_id": "2"})
+
+	reader := bufio.NewReader(response.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "id: 2\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(line, "data: "), line)
+
+	var event events.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+	assert.Equal(t, "notification", event.Type)
+
+	response.Body.Close()
+	waitForSubscribers(t, b, 0)
+}
+
+func TestHandler_WebSocket(t *testing.T) {
+	b := NewBroker(resolveHost)
+	settings := &config.EventStreamInfo{Enabled: true, BufferSize: 1, MaxSubscribers: 10}
+	server := httptest.NewServer(NewHandler(&supervisor.Supervisor{}, b, func() *config.EventStreamInfo {
+		return settings
+	}))
+	defer server.Close()
+
+	_, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+Path, "", "https://evil.example.com")
+	assert.Error(t, err, "WebSockets of other origins must be rejected")
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+Path+"?host=web-*", "", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	waitForSubscribers(t, b, 1)
+
+	at := time.Unix(1577836800, 0)
+	b.PublishHistory("notification", at, map[string]interface{}{
+		"event_id": "1", "object_type": "host", "host_id": "64622d31",
+	})
+	b.PublishHistory("notification", at, map[string]interface{}{
+		"event_id": "2", "object_type": "host", "host_id": "7765622d31",
+	})
+
+	var event events.Event
+	require.NoError(t, websocket.JSON.Receive(ws, &event))
+	assert.Equal(t, "2", event.ID)
+	assert.Equal(t, "web-1", event.Host)
+
+	// Overflow the buffer of a single event.
+	for i := 0; i < 3; i++ {
+		b.PublishHistory("notification", at, map[string]interface{}{
+			"event_id": "3", "object_type": "host", "host_id": "7765622d31",
+		})
+	}
+
+	var message map[string]interface{}
+	for message["type"] != "overflow" {
+		message = nil
+		require.NoError(t, websocket.JSON.Receive(ws, &message))
+	}
+
+	assert.Equal(t, "fell behind by more than 1 events, reconnect to resume", message["error"])
+	assert.Error(t, websocket.JSON.Receive(ws, &message), "the connection must be closed after an overflow")
+}
@@ -0,0 +1,29 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package eventstream
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var Subscribers = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "event_stream_subscribers",
+		Help: "Connected event stream subscribers",
+	},
+)
+
+var PublishedEventsTotal = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "event_stream_published_events_total",
+		Help: "State updates and history events published while anyone was subscribed",
+	},
+)
+
+var OverflowsTotal = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "event_stream_overflows_total",
+		Help: "Event stream subscribers disconnected as they fell behind by more than their buffer",
+	},
+)
@@ -0,0 +1,55 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package ha
+
+import (
+	"github.com/Icinga/icingadb/utils"
+	"net/http"
+)
+
+// NewAPI returns the handler of /ha/status and /ha/handover. The latter requires the bearer token returned by token
+// and is disabled if that's empty.
+func NewAPI(h *HA, token func() string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/ha/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+
+		utils.WriteJSON(w, http.StatusOK, h.Status())
+	})
+
+	mux.HandleFunc("/ha/handover", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.WriteJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+
+		expected := token()
+		if expected == "" {
+			utils.WriteJSONError(w, http.StatusForbidden, "handover is disabled, set [ha] api_token to enable it")
+			return
+		}
+
+		if !utils.HasBearerToken(r, expected) {
+			utils.WriteJSONError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		if !h.IsActive() {
+			utils.WriteJSONError(w, http.StatusConflict, "not responsible")
+			return
+		}
+
+		if err := h.Handover(); err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		utils.WriteJSON(w, http.StatusOK, h.Status())
+	})
+
+	return mux
+}
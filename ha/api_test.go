@@ -0,0 +1,50 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package ha
+
+import (
+	"encoding/json"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPI(t *testing.T) {
+	h, err := NewHA(&supervisor.Supervisor{EnvId: []byte{0xca, 0xfe}})
+	require.NoError(t, err)
+
+	token := ""
+	api := NewAPI(h, func() string { return token })
+
+	do := func(method, path, authorization string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		api.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	response := do("GET", "/ha/status", "")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var status Status
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &status))
+	assert.Equal(t, h.uid, status.UID)
+	assert.Equal(t, "cafe", status.Environment)
+	assert.False(t, status.Active)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, do("GET", "/ha/handover", "").Code)
+	assert.Equal(t, http.StatusForbidden, do("POST", "/ha/handover", "").Code, "handover must be disabled without token")
+
+	token = "secret"
+	assert.Equal(t, http.StatusUnauthorized, do("POST", "/ha/handover", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("POST", "/ha/handover", "Bearer guess").Code)
+	assert.Equal(t, http.StatusConflict, do("POST", "/ha/handover", "Bearer secret").Code)
+}
@@ -21,7 +21,30 @@ const (
 	Notify_StopSync
 )
 
+// Timeouts control when responsibility is taken over and given up.
+type Timeouts struct {
+	// Heartbeat is how long Icinga 2 may send no heartbeat before we pause the sync.
+	Heartbeat time.Duration
+	// Takeover is how old the heartbeat of the responsible instance has to be for us to take over.
+	Takeover time.Duration
+	// MaxHeartbeatGap is how far apart two Icinga 2 heartbeats may be, before we verify
+	// in the database that we're still responsible.
+	MaxHeartbeatGap time.Duration
+}
+
+var DefaultTimeouts = Timeouts{
+	Heartbeat:       15 * time.Second,
+	Takeover:        15 * time.Second,
+	MaxHeartbeatGap: 10 * time.Second,
+}
+
+// handoverHoldOff is how long we don't take over again after handing over responsibility voluntarily.
+const handoverHoldOff = time.Minute
+
 type HA struct {
+	// mu guards isActive, lastHeartbeat, lastEventId and super.EnvId, which are read by Status from other goroutines.
+	// They're only written while holding it, so the goroutine of runHA may read them without.
+	mu                         sync.RWMutex
 	isActive                   bool
 	lastHeartbeat              int64
 	uid                        uuid.UUID
@@ -31,6 +54,15 @@ type HA struct {
 	lastEventId                string
 	logger                     *log.Entry
 	heartbeatTimer             *time.Timer
+	// chHandover and chResign pass handover and resign requests to runHA, which replies through the given channel.
+	chHandover chan chan error
+	chResign   chan chan error
+	// resigned is set by runHA once it has resigned, StartHA stops then.
+	resigned      bool
+	handoverUntil time.Time
+	timeouts      Timeouts
+	// fencingToken identifies our current responsibility term, see connection.Fence.
+	fencingToken int64
 }
 
 func NewHA(super *supervisor.Supervisor) (*HA, error) {
@@ -40,6 +72,9 @@ func NewHA(super *supervisor.Supervisor) (*HA, error) {
 		notificationListeners:      make(map[string][]chan int),
 		notificationListenersMutex: sync.Mutex{},
 		lastEventId:                "0-0",
+		chHandover:                 make(chan chan error),
+		chResign:                   make(chan chan error),
+		timeouts:                   DefaultTimeouts,
 	}
 
 	if ho.uid, err = uuid.NewRandom(); err != nil {
@@ -61,43 +96,160 @@ var mysqlObservers = struct {
 	connection.DbIoSeconds.WithLabelValues("mysql", "select id, heartbeat from icingadb_instance where environment_id = ourEnvID"),
 }
 
-func (h *HA) updateOwnInstance() error {
-	_, err := h.super.Dbw.SqlExec(mysqlObservers.updateIcingadbInstanceById,
-		"UPDATE icingadb_instance SET heartbeat = ? WHERE id = ?", h.lastHeartbeat, h.uid[:])
-	return err
+// instance is the icingadb_instance row of our environment.
+type instance struct {
+	id           uuid.UUID
+	heartbeat    int64
+	fencingToken int64
+}
+
+// updateOwnInstance updates our heartbeat. It returns false if we aren't responsible according to the database.
+func (h *HA) updateOwnInstance() (bool, error) {
+	result, err := h.super.Dbw.SqlExec(mysqlObservers.updateIcingadbInstanceById,
+		"UPDATE icingadb_instance SET heartbeat = ? WHERE id = ? AND fencing_token = ?",
+		h.lastHeartbeat, h.uid[:], h.fencingToken)
+	if err != nil {
+		return false, err
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected > 0 {
+		return true, nil
+	}
+
+	// MySQL doesn't count rows whose values didn't change, e.g. two heartbeats within the same second.
+	own, err := h.getInstance()
+	if err != nil {
+		return false, err
+	}
+
+	return own != nil && own.id == h.uid && own.fencingToken == h.fencingToken, nil
+}
+
+// takeOverInstance takes over the responsibility, if the row is still as seen, and returns whether it did.
+// It's a compare-and-swap incrementing the fencing token, so that the writes of the previous instance are rejected.
+// If seen is nil, a new row is inserted unless another instance was faster.
+func (h *HA) takeOverInstance(seen *instance) (bool, error) {
+	if seen == nil {
+		if err := h.insertInstance(); err != nil {
+			// The environment_id is unique. If someone else inserted a row meanwhile, we just lost the race.
+			if other, errGet := h.getInstance(); errGet == nil && other != nil && other.id != h.uid {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		h.becomeActive(1)
+
+		return true, nil
+	}
+
+	var swapped bool
+	err := h.super.Dbw.SqlTransaction(true, true, false, func(tx connection.DbTransaction) error {
+		result, err := h.super.Dbw.SqlExecTx(tx, mysqlObservers.updateIcingadbInstanceByEnvironmentId,
+			"UPDATE icingadb_instance SET id = ?, heartbeat = ?, responsible = 'y', fencing_token = fencing_token + 1 "+
+				"WHERE environment_id = ? AND id = ? AND heartbeat = ? AND fencing_token = ?",
+			h.uid[:], h.lastHeartbeat, h.super.EnvId, seen.id[:], seen.heartbeat, seen.fencingToken)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		swapped = affected == 1
+
+		return err
+	})
+
+	if err != nil || !swapped {
+		return false, err
+	}
+
+	h.becomeActive(seen.fencingToken + 1)
+
+	return true, nil
 }
 
-func (h *HA) takeOverInstance() error {
-	_, err := h.super.Dbw.SqlExec(mysqlObservers.updateIcingadbInstanceByEnvironmentId,
-		"UPDATE icingadb_instance SET id = ?, heartbeat = ? WHERE environment_id = ?",
-		h.uid[:], h.lastHeartbeat, h.super.EnvId)
+// resignInstance marks our instance as not responsible. Its heartbeat is reset, so that others take over immediately.
+func (h *HA) resignInstance() error {
+	_, err := h.super.Dbw.SqlExec(mysqlObservers.updateIcingadbInstanceById,
+		"UPDATE icingadb_instance SET heartbeat = 0, responsible = 'n' WHERE id = ? AND fencing_token = ?",
+		h.uid[:], h.fencingToken)
 	return err
 }
 
 func (h *HA) insertInstance() error {
 	_, err := h.super.Dbw.SqlExec(mysqlObservers.insertIntoIcingadbInstance,
-		"INSERT INTO icingadb_instance(id, environment_id, heartbeat, responsible) VALUES (?, ?, ?, 'y')",
+		"INSERT INTO icingadb_instance(id, environment_id, heartbeat, responsible, fencing_token) VALUES (?, ?, ?, 'y', 1)",
 		h.uid[:], h.super.EnvId, h.lastHeartbeat)
 	return err
 }
 
-func (h *HA) getInstance() (bool, uuid.UUID, int64, error) {
+// getInstance returns the icingadb_instance row of our environment, nil if there is none.
+func (h *HA) getInstance() (*instance, error) {
 	rows, err := h.super.Dbw.SqlFetchAll(mysqlObservers.selectIdHeartbeatFromIcingadbInstanceByEnvironmentId,
-		"SELECT id, heartbeat from icingadb_instance where environment_id = ? LIMIT 1",
+		"SELECT id, heartbeat, fencing_token from icingadb_instance where environment_id = ?",
 		h.super.EnvId,
 	)
 
 	if err != nil {
-		return false, uuid.UUID{}, 0, err
+		return nil, err
 	}
 	if len(rows) == 0 {
-		return false, uuid.UUID{}, 0, nil
+		return nil, nil
+	}
+
+	row := &instance{heartbeat: rows[0][1].(int64), fencingToken: rows[0][2].(int64)}
+	copy(row.id[:], rows[0][0].([]byte))
+
+	return row, nil
+}
+
+// becomeActive starts our responsibility term with the given fencing token
+// and starts the syncs which don't wait for the config dump.
+func (h *HA) becomeActive(fencingToken int64) {
+	h.setActive(true)
+	h.fencingToken = fencingToken
+
+	if fence := h.super.Dbw.Fence; fence != nil {
+		fence.Set(h.super.EnvId, h.uid[:], fencingToken)
 	}
 
-	var theirUUID uuid.UUID
-	copy(theirUUID[:], rows[0][0].([]byte))
+	h.notifyNotificationListener(ListenerTypeResponsibility, Notify_StartSync)
+}
+
+// SetTimeouts replaces the DefaultTimeouts. It must be called before StartHA.
+func (h *HA) SetTimeouts(timeouts Timeouts) {
+	h.timeouts = timeouts
+}
 
-	return true, theirUUID, rows[0][1].(int64), nil
+// isOutdated returns whether a heartbeat at beat is older than the takeover timeout at now.
+func (h *HA) isOutdated(now int64, beat int64) bool {
+	return time.Duration(now-beat)*time.Second > h.timeouts.Takeover
+}
+
+// loseResponsibility stops the sync for the given reason.
+func (h *HA) loseResponsibility(reason string) {
+	if h.isActive {
+		ResponsibilityLostTotal.WithLabelValues(reason).Inc()
+	}
+
+	if fence := h.super.Dbw.Fence; fence != nil {
+		fence.Clear()
+	}
+
+	h.setActive(false)
+	h.notifyNotificationListener("*", Notify_StopSync)
+}
+
+// setActive sets whether we're responsible. Not being responsible resets the position in the config dump stream.
+func (h *HA) setActive(active bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.isActive = active
+	if !active {
+		h.lastEventId = "0-0"
+	}
 }
 
 func (h *HA) StartHA(chEnv chan *Environment) {
@@ -113,11 +265,13 @@ func (h *HA) StartHA(chEnv chan *Environment) {
 
 	h.checkResponsibility()
 
-	h.heartbeatTimer = time.NewTimer(time.Second * 15)
+	h.heartbeatTimer = time.NewTimer(h.timeouts.Heartbeat)
 
-	for {
+	for !h.resigned {
 		h.runHA(chEnv)
 	}
+
+	h.heartbeatTimer.Stop()
 }
 
 func (h *HA) waitForEnvironment(chEnv chan *Environment) {
@@ -130,97 +284,126 @@ func (h *HA) waitForEnvironment(chEnv chan *Environment) {
 		h.super.ChErr <- errors.New("received empty environment")
 		return
 	}
+
+	h.mu.Lock()
 	h.super.EnvId = env.ID
+	h.mu.Unlock()
 }
 
 func (h *HA) checkResponsibility() {
-	found, _, beat, err := h.getInstance()
+	seen, err := h.getInstance()
 	if err != nil {
 		h.logger.Errorf("Failed to fetch instance: %v", err)
 		h.super.ChErr <- errors.New("failed to fetch instance")
 		return
 	}
 
-	if time.Now().Unix()-beat > 15 {
+	// This means there was no instance row match, insert
+	if seen == nil || h.isOutdated(time.Now().Unix(), seen.heartbeat) {
 		h.logger.Info("Taking over.")
 
-		// This means there was no instance row match, insert
-		if !found {
-			err = h.insertInstance()
-		} else {
-			err = h.takeOverInstance()
-		}
-
+		tookOver, err := h.takeOverInstance(seen)
 		if err != nil {
 			h.logger.Errorf("Failed to insert/update instance: %v", err)
 			h.super.ChErr <- errors.New("failed to insert/update instance")
 			return
 		}
 
-		h.isActive = true
+		if tookOver {
+			TakeoversTotal.Inc()
+			return
+		}
+
+		h.logger.Info("Other instance was faster.")
+		h.setActive(false)
 	} else {
 		h.logger.Info("Other instance is active.")
-		h.isActive = false
-		h.lastEventId = "0-0"
+		h.setActive(false)
 	}
 }
 
 func (h *HA) runHA(chEnv chan *Environment) {
 	select {
 	case env := <-chEnv:
+		if h.super.ShuttingDown() {
+			return
+		}
+
 		if bytes.Compare(env.ID, h.super.EnvId) != 0 {
 			h.logger.Error("Received environment is not the one we expected. Panic.")
 			h.super.ChErr <- errors.New("received unexpected environment")
 			return
 		}
 
-		h.heartbeatTimer.Reset(time.Second * 15)
+		h.heartbeatTimer.Reset(h.timeouts.Heartbeat)
 		previous := h.lastHeartbeat
+
+		h.mu.Lock()
 		h.lastHeartbeat = time.Now().Unix()
+		h.mu.Unlock()
 
-		if h.lastHeartbeat-previous < 10 && h.isActive {
-			err := h.updateOwnInstance()
+		if time.Duration(h.lastHeartbeat-previous)*time.Second < h.timeouts.MaxHeartbeatGap && h.isActive {
+			responsible, err := h.updateOwnInstance()
 
 			if err != nil {
 				h.logger.Errorf("Failed to update instance: %v", err)
 				h.super.ChErr <- errors.New("failed to update instance")
 				return
 			}
+
+			if !responsible {
+				h.logger.Warn("Other instance took over, fencing token is outdated. Pausing sync")
+				h.loseResponsibility("fenced")
+			}
+		} else if time.Now().Before(h.handoverUntil) {
+			h.logger.Debug("Handed over recently, leaving responsibility to other instances.")
 		} else {
-			_, they, beat, err := h.getInstance()
+			seen, err := h.getInstance()
 			if err != nil {
 				h.logger.Errorf("Failed to fetch instance: %v", err)
 				h.super.ChErr <- errors.New("failed to fetch instance")
 				return
 			}
-			if they == h.uid {
+			if seen != nil && seen.id == h.uid {
 				h.logger.Debug("We are active.")
 				if !h.isActive {
 					h.logger.Info("Icinga 2 sent heartbeat. Starting sync")
-					h.isActive = true
+					h.becomeActive(seen.fencingToken)
 				}
 
-				if err := h.updateOwnInstance(); err != nil {
+				if _, err := h.updateOwnInstance(); err != nil {
 					h.logger.Errorf("Failed to update instance: %v", err)
 					h.super.ChErr <- errors.New("failed to update instance")
 					return
 				}
-			} else if h.lastHeartbeat-beat > 15 {
+			} else if seen == nil || h.isOutdated(h.lastHeartbeat, seen.heartbeat) {
 				h.logger.Info("Taking over.")
-				if err := h.takeOverInstance(); err != nil {
+				tookOver, err := h.takeOverInstance(seen)
+				if err != nil {
 					h.logger.Errorf("Failed to update instance: %v", err)
 					h.super.ChErr <- errors.New("failed to update instance")
+					return
 				}
-				h.isActive = true
+
+				if tookOver {
+					TakeoversTotal.Inc()
+				} else {
+					h.logger.Info("Other instance was faster.")
+				}
+			} else if h.isActive {
+				h.logger.Info("Other instance took over. Pausing sync")
+				h.loseResponsibility("taken_over")
 			} else {
 				h.logger.Debug("Other instance is active.")
 			}
 		}
+	case chResult := <-h.chHandover:
+		chResult <- h.handover()
+	case chResult := <-h.chResign:
+		chResult <- h.resign()
 	case <-h.heartbeatTimer.C:
-		h.logger.Info("Icinga 2 sent no heartbeat for 15 seconds. Pausing sync")
-		h.isActive = false
-		h.lastEventId = "0-0"
-		h.notifyNotificationListener("*", Notify_StopSync)
+		h.logger.Infof("Icinga 2 sent no heartbeat for %s. Pausing sync", h.timeouts.Heartbeat)
+		h.loseResponsibility("heartbeat_timeout")
 	}
 }
 
@@ -234,11 +417,15 @@ func (h *HA) StartEventListener() {
 }
 
 func (h *HA) runEventListener() {
-	if !h.isActive {
+	h.mu.RLock()
+	isActive, lastEventId := h.isActive, h.lastEventId
+	h.mu.RUnlock()
+
+	if !isActive || h.super.ShuttingDown() {
 		return
 	}
 
-	result := h.super.Rdbw.XRead(&redis.XReadArgs{Block: -1, Streams: []string{"icinga:dump", h.lastEventId}})
+	result := h.super.Rdbw.XRead(&redis.XReadArgs{Block: -1, Streams: []string{"icinga:dump", lastEventId}})
 	streams, err := result.Result()
 	if err != nil {
 		if err.Error() != "redis: nil" {
@@ -253,7 +440,10 @@ func (h *HA) runEventListener() {
 	}
 
 	for _, event := range events {
+		h.mu.Lock()
 		h.lastEventId = event.ID
+		h.mu.Unlock()
+
 		values := event.Values
 
 		if values["state"] == "done" {
@@ -264,6 +454,115 @@ func (h *HA) runEventListener() {
 	}
 }
 
+// handover stops the sync and resigns, so that another instance takes over with its next heartbeat.
+func (h *HA) handover() error {
+	if !h.isActive {
+		return errors.New("not responsible")
+	}
+
+	h.logger.Info("Handing over responsibility")
+
+	h.handoverUntil = time.Now().Add(handoverHoldOff)
+	h.loseResponsibility("handover")
+
+	return h.resignInstance()
+}
+
+// Handover voluntarily gives up the responsibility for the environment to another instance.
+// We don't take over again within handoverHoldOff.
+func (h *HA) Handover() error {
+	chResult := make(chan error, 1)
+
+	select {
+	case h.chHandover <- chResult:
+		return <-chResult
+	case <-time.After(10 * time.Second):
+		return errors.New("HA is busy or not running yet")
+	}
+}
+
+// Status is a snapshot of the HA state.
+type Status struct {
+	UID           uuid.UUID `json:"uid"`
+	Active        bool      `json:"active"`
+	LastHeartbeat int64     `json:"last_heartbeat"`
+	Environment   string    `json:"environment"`
+	LastEventId   string    `json:"last_event_id"`
+}
+
+func (h *HA) Status() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return Status{
+		UID:           h.uid,
+		Active:        h.isActive,
+		LastHeartbeat: h.lastHeartbeat,
+		Environment:   hex.EncodeToString(h.super.EnvId),
+		LastEventId:   h.lastEventId,
+	}
+}
+
+// IsActive returns whether we're responsible for the environment.
+func (h *HA) IsActive() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.isActive
+}
+
+// UID returns the ID of this instance, which is also its name as Redis stream consumer.
+func (h *HA) UID() uuid.UUID {
+	return h.uid
+}
+
+// StopSync tells all listeners to stop syncing. It's called on shutdown, before the workers are waited for.
+func (h *HA) StopSync() {
+	h.notifyNotificationListener("*", Notify_StopSync)
+}
+
+// Resign hands over the responsibility for our environment, if we have it, and stops runHA. It's called on shutdown,
+// after the workers have finished. Once shutting down, we don't take over again.
+func (h *HA) Resign() error {
+	if !h.IsActive() {
+		// Without responsibility there's nothing to hand over, runHA may not even run yet.
+		return nil
+	}
+
+	chResult := make(chan error, 1)
+
+	select {
+	case h.chResign <- chResult:
+		return <-chResult
+	case <-time.After(10 * time.Second):
+		return errors.New("HA is busy")
+	}
+}
+
+// resign does the work of Resign in the goroutine of runHA.
+func (h *HA) resign() error {
+	h.resigned = true
+
+	if !h.isActive {
+		return nil
+	}
+
+	h.setActive(false)
+	ResponsibilityLostTotal.WithLabelValues("shutdown").Inc()
+
+	if fence := h.super.Dbw.Fence; fence != nil {
+		fence.Clear()
+	}
+
+	if err := h.resignInstance(); err != nil {
+		return err
+	}
+
+	h.logger.Info("Handed over responsibility")
+
+	return nil
+}
+
 func (h *HA) RegisterNotificationListener(listenerType string) chan int {
 	ch := make(chan int, 10)
 	h.notificationListenersMutex.Lock()
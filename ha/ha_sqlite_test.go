@@ -0,0 +1,107 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package ha
+
+import (
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/schema"
+	"github.com/Icinga/icingadb/supervisor"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// createSqliteHA returns an HA on a fresh SQLite database, which doesn't need any test backends.
+func createSqliteHA(t *testing.T) (*HA, func()) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+
+	dialect, err := connection.GetDialect("sqlite")
+	require.NoError(t, err)
+
+	dbw, err := connection.NewDBWrapperWithDialect(dialect, filepath.Join(dir, "icingadb.db"), 4)
+	require.NoError(t, err)
+
+	dbw.WaitForConnection()
+	require.NoError(t, schema.Migrate(dbw))
+
+	ha, err := NewHA(&supervisor.Supervisor{ChErr: make(chan error, 10), Dbw: dbw, Done: make(chan struct{})})
+	require.NoError(t, err)
+
+	ha.logger = log.WithFields(log.Fields{"context": "HA-Testing"})
+
+	return ha, func() { os.RemoveAll(dir) }
+}
+
+func TestHA_Resign(t *testing.T) {
+	ha, cleanup := createSqliteHA(t)
+	defer cleanup()
+
+	chEnv := make(chan *Environment)
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ha.StartHA(chEnv)
+	}()
+
+	chEnv <- &Environment{ID: Sha1bytes([]byte("test"))}
+	chEnv <- &Environment{ID: Sha1bytes([]byte("test"))}
+
+	close(ha.super.Done)
+	require.NoError(t, ha.Resign())
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		assert.Fail(t, "StartHA should stop after resigning")
+	}
+
+	assert.False(t, ha.isActive)
+
+	rows, err := ha.super.Dbw.SqlFetchAll(mysqlTestObserver, "SELECT responsible, heartbeat FROM icingadb_instance")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "n", rows[0][0])
+	assert.Equal(t, int64(0), rows[0][1])
+
+	assert.NoError(t, ha.Resign(), "resigning without responsibility must do nothing")
+}
+
+func TestHA_StatusWhileRunning(t *testing.T) {
+	ha, cleanup := createSqliteHA(t)
+	defer cleanup()
+
+	chEnv := make(chan *Environment)
+	go ha.StartHA(chEnv)
+
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+
+		for i := 0; i < 1000; i++ {
+			status := ha.Status()
+			_ = ha.IsActive()
+			_ = len(status.LastEventId) + len(status.Environment)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		chEnv <- &Environment{ID: Sha1bytes([]byte("test"))}
+	}
+
+	<-polled
+
+	status := ha.Status()
+	assert.True(t, status.Active, "HA should take over, if there is no instance")
+	assert.Equal(t, "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3", status.Environment)
+	assert.NotZero(t, status.LastHeartbeat)
+
+	close(ha.super.Done)
+	assert.NoError(t, ha.Resign())
+}
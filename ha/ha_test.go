@@ -104,6 +104,39 @@ func TestHA_checkResponsibility(t *testing.T) {
 	assert.Equal(t, false, ha.isActive, "HA should not be responsible, if another instance is active")
 }
 
+func TestHA_takeOverInstance(t *testing.T) {
+	other := createTestingHA(t, testbackends.RedisTestAddr)
+	other.uid = uuid.MustParse("e4b4a4b1-1b40-4c43-b5f1-0d7d7e1f4f2a")
+
+	ha := createTestingHA(t, testbackends.RedisTestAddr)
+
+	tookOver, err := ha.takeOverInstance(nil)
+	require.NoError(t, err)
+	assert.True(t, tookOver, "HA should take over, if there is no instance")
+	assert.Equal(t, int64(1), ha.fencingToken)
+
+	seen, err := other.getInstance()
+	require.NoError(t, err)
+	require.NotNil(t, seen)
+
+	tookOver, err = other.takeOverInstance(nil)
+	require.NoError(t, err)
+	assert.False(t, tookOver, "HA must not insert a second instance")
+
+	tookOver, err = other.takeOverInstance(seen)
+	require.NoError(t, err)
+	assert.True(t, tookOver, "HA should take over, if the instance is still as seen")
+	assert.Equal(t, int64(2), other.fencingToken)
+
+	tookOver, err = ha.takeOverInstance(seen)
+	require.NoError(t, err)
+	assert.False(t, tookOver, "HA must not take over, if another instance was faster")
+
+	responsible, err := ha.updateOwnInstance()
+	require.NoError(t, err)
+	assert.False(t, responsible, "HA must not be responsible with an outdated fencing token")
+}
+
 func TestHA_waitForEnvironment(t *testing.T) {
 	ha := createTestingHA(t, testbackends.RedisTestAddr)
 
@@ -0,0 +1,23 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package ha
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var TakeoversTotal = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "ha_takeovers_total",
+		Help: "How often this instance took over the responsibility",
+	},
+)
+
+var ResponsibilityLostTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ha_responsibility_lost_total",
+		Help: "How often this instance lost the responsibility per reason",
+	},
+	[]string{"reason"},
+)
@@ -0,0 +1,65 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package ha
+
+import (
+	"github.com/Icinga/icingadb/supervisor"
+	"sync"
+)
+
+// ListenerTypeResponsibility is notified with Notify_StartSync as soon as this instance becomes responsible,
+// independent of the config dump. Like all listeners, it gets Notify_StopSync once the responsibility is lost.
+const ListenerTypeResponsibility = "responsibility"
+
+// RunWhileResponsible calls each worker repeatedly from Notify_StartSync until Notify_StopSync or shutdown.
+// Workers finish their current call before stopping. They are not started again before all of them stopped,
+// so that no two of them process the same Redis stream. It returns once super is shutting down.
+func RunWhileResponsible(super *supervisor.Supervisor, chHA <-chan int, workers ...func()) {
+	var stop chan struct{}
+	running := &sync.WaitGroup{}
+
+	for {
+		select {
+		case msg := <-chHA:
+			switch msg {
+			case Notify_StartSync:
+				if stop != nil || super.ShuttingDown() {
+					continue
+				}
+
+				running.Wait()
+
+				stop = make(chan struct{})
+				for _, worker := range workers {
+					runWorker(super, stop, running, worker)
+				}
+			case Notify_StopSync:
+				if stop != nil {
+					close(stop)
+					stop = nil
+				}
+			}
+		case <-super.Done:
+			return
+		}
+	}
+}
+
+// runWorker calls worker repeatedly until stop is closed or super is shutting down.
+func runWorker(super *supervisor.Supervisor, stop <-chan struct{}, running *sync.WaitGroup, worker func()) {
+	running.Add(1)
+
+	super.Go(func() {
+		defer running.Done()
+
+		for !super.ShuttingDown() {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			worker()
+		}
+	})
+}
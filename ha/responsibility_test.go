@@ -0,0 +1,56 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package ha
+
+import (
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunWhileResponsible(t *testing.T) {
+	super := &supervisor.Supervisor{Done: make(chan struct{}), Workers: &sync.WaitGroup{}}
+	chHA := make(chan int)
+	returned := make(chan struct{})
+
+	var calls int32
+	worker := func() {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Millisecond)
+	}
+
+	go func() {
+		RunWhileResponsible(super, chHA, worker)
+		close(returned)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls), "workers must not run before responsibility")
+
+	chHA <- Notify_StartSync
+	chHA <- Notify_StartSync
+	time.Sleep(10 * time.Millisecond)
+	assert.NotEqual(t, int32(0), atomic.LoadInt32(&calls), "workers must run while responsible")
+
+	chHA <- Notify_StopSync
+	super.Workers.Wait()
+	stopped := atomic.LoadInt32(&calls)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&calls), "workers must stop on Notify_StopSync")
+
+	chHA <- Notify_StartSync
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, atomic.LoadInt32(&calls) > stopped, "workers must resume on Notify_StartSync")
+
+	close(super.Done)
+	super.Workers.Wait()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		assert.Fail(t, "RunWhileResponsible must return on shutdown")
+	}
+}
@@ -0,0 +1,94 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package health
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/Icinga/icingadb/configobject/configsync"
+	"github.com/Icinga/icingadb/ha"
+	"github.com/Icinga/icingadb/supervisor"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type connectionStatus struct {
+	Connected bool `json:"connected"`
+}
+
+type healthStatus struct {
+	Healthy  bool             `json:"healthy"`
+	Redis    connectionStatus `json:"redis"`
+	Database connectionStatus `json:"database"`
+}
+
+type configSyncStatus struct {
+	Done    bool     `json:"done"`
+	Pending []string `json:"pending"`
+}
+
+type readyStatus struct {
+	Ready       bool   `json:"ready"`
+	Environment string `json:"environment"`
+	// Responsible tells whether this instance writes to the database. Standby instances are ready, too.
+	Responsible  bool             `json:"responsible"`
+	ConfigSync   configSyncStatus `json:"config_sync"`
+	ShuttingDown bool             `json:"shutting_down"`
+}
+
+// Checker serves /healthz and /readyz.
+type Checker struct {
+	super *supervisor.Supervisor
+	ha    *ha.HA
+	// initialSyncsPending returns the object types whose config sync is still running.
+	initialSyncsPending func() []string
+}
+
+func NewChecker(super *supervisor.Supervisor, haInstance *ha.HA) *Checker {
+	return &Checker{super: super, ha: haInstance, initialSyncsPending: configsync.InitialSyncsPending}
+}
+
+// Healthz reports whether Redis and the database are connected.
+func (c *Checker) Healthz(w http.ResponseWriter, _ *http.Request) {
+	status := healthStatus{
+		Redis:    connectionStatus{Connected: c.super.Rdbw.IsConnected()},
+		Database: connectionStatus{Connected: c.super.Dbw.IsConnected()},
+	}
+	status.Healthy = status.Redis.Connected && status.Database.Connected
+
+	writeStatus(w, status.Healthy, status)
+}
+
+// Readyz reports whether the environment is known and, if responsible, the initial config sync has finished.
+func (c *Checker) Readyz(w http.ResponseWriter, _ *http.Request) {
+	status := readyStatus{
+		Responsible:  c.ha.IsActive(),
+		ShuttingDown: c.super.ShuttingDown(),
+	}
+
+	if c.super.EnvId != nil {
+		status.Environment = hex.EncodeToString(c.super.EnvId)
+	}
+
+	status.ConfigSync.Pending = c.initialSyncsPending()
+	status.ConfigSync.Done = len(status.ConfigSync.Pending) == 0
+
+	status.Ready = status.Environment != "" && !status.ShuttingDown && (!status.Responsible || status.ConfigSync.Done)
+
+	writeStatus(w, status.Ready, status)
+}
+
+// writeStatus writes status as JSON with 200 OK if ok, 503 Service Unavailable otherwise.
+func writeStatus(w http.ResponseWriter, ok bool, status interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.WithFields(log.Fields{"context": "health", "error": err}).Debug("Can't write status")
+	}
+}
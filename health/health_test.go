@@ -0,0 +1,76 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package health
+
+import (
+	"encoding/json"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/ha"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestChecker(t *testing.T) (*Checker, *supervisor.Supervisor) {
+	super := &supervisor.Supervisor{
+		Rdbw: &connection.RDBWrapper{ConnectedAtomic: new(uint32)},
+		Dbw:  &connection.DBWrapper{ConnectedAtomic: new(uint32)},
+		Done: make(chan struct{}),
+	}
+
+	haInstance, err := ha.NewHA(super)
+	require.NoError(t, err)
+
+	return NewChecker(super, haInstance), super
+}
+
+func request(handler http.HandlerFunc) (int, map[string]interface{}) {
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/", nil))
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+
+	return recorder.Code, body
+}
+
+func TestChecker_Healthz(t *testing.T) {
+	checker, super := newTestChecker(t)
+
+	code, body := request(checker.Healthz)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, false, body["healthy"])
+
+	super.Rdbw.CompareAndSetConnected(true)
+	super.Dbw.CompareAndSetConnected(true)
+
+	code, body = request(checker.Healthz)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, body["healthy"])
+	assert.Equal(t, map[string]interface{}{"connected": true}, body["redis"])
+}
+
+func TestChecker_Readyz(t *testing.T) {
+	checker, super := newTestChecker(t)
+	checker.initialSyncsPending = func() []string { return []string{"host"} }
+
+	code, body := request(checker.Readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, code, "the environment isn't known yet")
+	assert.Equal(t, false, body["ready"])
+
+	super.EnvId = []byte{0xca, 0xfe}
+
+	code, body = request(checker.Readyz)
+	assert.Equal(t, http.StatusOK, code, "a standby doesn't sync config")
+	assert.Equal(t, "cafe", body["environment"])
+	assert.Equal(t, false, body["responsible"])
+
+	close(super.Done)
+
+	code, body = request(checker.Readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, true, body["shutting_down"])
+}
@@ -5,6 +5,7 @@ package jsondecoder
 import (
 	"github.com/Icinga/icingadb/connection"
 	"github.com/json-iterator/go"
+	"sync"
 )
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
@@ -38,19 +39,69 @@ func decodeString(toDecode string, row connection.Row) error {
 // decodePool takes a channel it receives JsonDecodePackages from and an error channel to forward errors.
 // These packages are decoded by a pool of pollSize workers which send their result back through their own channel.
 func DecodePool(chInput <-chan *JsonDecodePackages, chError chan error, poolSize int) {
-	for i := 0; i < poolSize; i++ {
+	NewPool(chInput, chError).Resize(poolSize)
+}
+
+// Pool is a pool of decoding workers whose size can be changed at runtime.
+type Pool struct {
+	chInput <-chan *JsonDecodePackages
+	chError chan error
+	mu      sync.Mutex
+	stops   []chan struct{}
+}
+
+// NewPool returns an empty Pool decoding from chInput. Use Resize to start workers.
+func NewPool(chInput <-chan *JsonDecodePackages, chError chan error) *Pool {
+	return &Pool{chInput: chInput, chError: chError}
+}
+
+// Resize starts or stops workers until there are poolSize of them.
+// A stopped worker finishes the JsonDecodePackages it is working on.
+func (p *Pool) Resize(poolSize int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.stops) < poolSize {
+		stop := make(chan struct{})
+		p.stops = append(p.stops, stop)
+
 		go func(in <-chan *JsonDecodePackages, chErrorInternal chan error) {
-			chErrorInternal <- decodePackage(in)
-		}(chInput, chError)
+			chErrorInternal <- decodePackage(in, stop)
+		}(p.chInput, p.chError)
+	}
+
+	for len(p.stops) > poolSize {
+		close(p.stops[len(p.stops)-1])
+		p.stops = p.stops[:len(p.stops)-1]
 	}
 }
 
+// Size returns the number of workers.
+func (p *Pool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.stops)
+}
+
 // decodePackage is the worker function for DecodePool. Reads from a channel and sends back decoded
-// packages. Returns error if any.
-func decodePackage(chInput <-chan *JsonDecodePackages) error {
+// packages until stop is closed. Returns error if any.
+func decodePackage(chInput <-chan *JsonDecodePackages, stop <-chan struct{}) error {
 	var err error
 
-	for pkgs := range chInput {
+	for {
+		var pkgs *JsonDecodePackages
+		var ok bool
+
+		select {
+		case pkgs, ok = <-chInput:
+			if !ok {
+				return nil
+			}
+		case <-stop:
+			return nil
+		}
+
 		var rows []connection.Row
 		for _, pkg := range pkgs.Packages {
 			row := pkg.Factory()
@@ -71,6 +122,4 @@ func decodePackage(chInput <-chan *JsonDecodePackages) error {
 
 		pkgs.ChBack <- rows
 	}
-
-	return nil
 }
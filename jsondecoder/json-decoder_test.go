@@ -64,3 +64,23 @@ func Test_DecodePool(t *testing.T) {
 	close(chInput)
 	close(chOutput)
 }
+
+func TestPool_Resize(t *testing.T) {
+	var chInput = make(chan *JsonDecodePackages)
+	var chError = make(chan error)
+
+	pool := NewPool(chInput, chError)
+	pool.Resize(3)
+	assert.Equal(t, 3, pool.Size())
+
+	pool.Resize(1)
+	assert.Equal(t, 1, pool.Size())
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, <-chError, "stopped workers must not report errors")
+	}
+
+	chOutput := make(chan []connection.Row, 1)
+	chInput <- &JsonDecodePackages{ChBack: chOutput}
+	assert.Empty(t, <-chOutput, "the remaining worker must still decode")
+}
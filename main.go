@@ -4,10 +4,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"github.com/Icinga/icingadb/api"
 	"github.com/Icinga/icingadb/config"
 	"github.com/Icinga/icingadb/configobject"
 	"github.com/Icinga/icingadb/configobject/configsync"
 	"github.com/Icinga/icingadb/configobject/history"
+	"github.com/Icinga/icingadb/configobject/names"
 	"github.com/Icinga/icingadb/configobject/objecttypes/actionurl"
 	"github.com/Icinga/icingadb/configobject/objecttypes/checkcommand"
 	"github.com/Icinga/icingadb/configobject/objecttypes/checkcommand/checkcommandargument"
@@ -57,27 +60,55 @@ import (
 	"github.com/Icinga/icingadb/configobject/objecttypes/zone"
 	"github.com/Icinga/icingadb/configobject/statesync"
 	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/eventstream"
 	"github.com/Icinga/icingadb/ha"
+	"github.com/Icinga/icingadb/health"
 	"github.com/Icinga/icingadb/jsondecoder"
 	"github.com/Icinga/icingadb/prometheus"
+	"github.com/Icinga/icingadb/schema"
 	"github.com/Icinga/icingadb/supervisor"
+	"github.com/Icinga/icingadb/tsdb"
+	"github.com/Icinga/icingadb/webhook"
 	log "github.com/sirupsen/logrus"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 )
 
 func main() {
-	{
-		ch := make(chan os.Signal, 1)
-		signal.Notify(ch, syscall.SIGTERM, syscall.SIGINT)
-		go handleSignal(ch)
-	}
-
 	configPath := flag.String("config", "icingadb.ini", "path to config")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [command]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Commands:")
+		fmt.Fprintln(flag.CommandLine.Output(), "  migrate\tinstall or upgrade the database schema and exit")
+		fmt.Fprintln(flag.CommandLine.Output(), "  check-config [-connect]\tvalidate the config and optionally the connections, exit non-zero on problems")
+		fmt.Fprintln(flag.CommandLine.Output(), "  dead-letter [list | replay]\tinspect or replay the state and history entries which couldn't be synced")
+		fmt.Fprintln(flag.CommandLine.Output(), "  sla -from DATE [options]\treport the availability of hosts and services as JSON or CSV")
+		fmt.Fprintln(flag.CommandLine.Output(), "  config-delta [options]\treport what the config sync would change in the database, without writing")
+		fmt.Fprintln(flag.CommandLine.Output(), "\nOptions:")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	command := flag.Arg(0)
+	switch command {
+	case "", "migrate":
+	case "check-config":
+		os.Exit(checkConfig(*configPath, flag.Args()[1:]))
+	case "dead-letter":
+		os.Exit(deadLetter(*configPath, flag.Args()[1:]))
+	case "sla":
+		os.Exit(slaReport(*configPath, flag.Args()[1:]))
+	case "config-delta":
+		os.Exit(configDelta(*configPath, flag.Args()[1:]))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	if err := config.ParseConfig(*configPath); err != nil {
 		log.Fatalf("Error reading config: %v", err)
 	}
@@ -88,23 +119,41 @@ func main() {
 	redisInfo := config.GetRedisInfo()
 	mysqlInfo := config.GetMysqlInfo()
 	metricsInfo := config.GetMetricsInfo()
+	icingadbInfo := config.GetIcingadbInfo()
 
-	redisConn := connection.NewRDBWrapper(redisInfo.Host+":"+redisInfo.Port, redisInfo.PoolSize)
+	redisConn, err := connectRedis(redisInfo)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	mysqlConn, err := connection.NewDBWrapper(
-		mysqlInfo.User+":"+mysqlInfo.Password+"@tcp("+mysqlInfo.Host+":"+mysqlInfo.Port+")/"+mysqlInfo.Database,
-		mysqlInfo.MaxOpenConns,
-	)
+	mysqlConn, err := connectDatabase(mysqlInfo)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if command == "migrate" {
+		if err := schema.Migrate(mysqlConn); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := schema.Check(mysqlConn); err != nil {
+		log.Fatal(err)
+	}
+
+	// Writes of the syncs are only accepted while our fencing token is current, see ha.HA.
+	mysqlConn.Fence = &connection.Fence{}
+
 	super := supervisor.Supervisor{
 		ChErr:    make(chan error),
 		ChDecode: make(chan *jsondecoder.JsonDecodePackages),
 		Rdbw:     redisConn,
 		Dbw:      mysqlConn,
 		EnvLock:  &sync.Mutex{},
+		Done:     make(chan struct{}),
+		Workers:  &sync.WaitGroup{},
 	}
 
 	chEnv := make(chan *ha.Environment)
@@ -113,109 +162,274 @@ func main() {
 		log.Fatal(err)
 	}
 
+	haInfo := config.GetHaInfo()
+	haInstance.SetTimeouts(ha.Timeouts{
+		Heartbeat:       haInfo.HeartbeatTimeout,
+		Takeover:        haInfo.TakeoverTimeout,
+		MaxHeartbeatGap: haInfo.MaxHeartbeatGap,
+	})
+
 	go haInstance.StartHA(chEnv)
 	go ha.IcingaHeartbeatListener(redisConn, chEnv, super.ChErr)
 
-	go jsondecoder.DecodePool(super.ChDecode, super.ChErr, 16)
+	decodePool := jsondecoder.NewPool(super.ChDecode, super.ChErr)
+	decodePool.Resize(icingadbInfo.DecodeWorkers)
 
 	startConfigSyncOperators(&super, haInstance)
 
-	statesync.StartStateSync(&super)
+	resolver := names.NewResolver(mysqlConn)
 
-	history.StartHistoryWorkers(&super)
+	perfdataExporter := tsdb.NewExporter(config.GetPerfdataExportInfo(), resolver.Resolve)
+	perfdataExporter.Start(&super)
+
+	eventBroker := eventstream.NewBroker(resolver.Resolve)
+
+	statesync.StartStateSync(
+		&super, haInstance.RegisterNotificationListener(ha.ListenerTypeResponsibility), haInstance.UID().String(),
+		perfdataExporter, eventBroker,
+	)
+
+	dispatcher, err := webhook.NewDispatcher(redisConn, resolver.Resolve, config.GetWebhooks())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dispatcher.Start(&super)
+
+	history.StartHistoryWorkers(
+		&super, haInstance.RegisterNotificationListener(ha.ListenerTypeResponsibility), haInstance.UID().String(),
+		dispatcher, eventBroker,
+	)
 
 	go haInstance.StartEventListener()
 
-	if metricsInfo.Host != "" {
-		go prometheus.HandleHttp(metricsInfo.Host+":"+metricsInfo.Port, super.ChErr)
+	metricsServer := prometheus.NewServer(super.ChErr)
+
+	healthChecker := health.NewChecker(&super, haInstance)
+	metricsServer.Handle("/healthz", http.HandlerFunc(healthChecker.Healthz))
+	metricsServer.Handle("/readyz", http.HandlerFunc(healthChecker.Readyz))
+	metricsServer.Handle("/ha/", ha.NewAPI(haInstance, func() string {
+		return config.GetHaInfo().ApiToken
+	}))
+	metricsServer.Handle(api.Prefix, api.NewAPI(&super, config.GetApiInfo))
+	metricsServer.Handle(eventstream.Path, eventstream.NewHandler(&super, eventBroker, config.GetEventStreamInfo))
+	if err := metricsServer.Listen(metricsAddress(metricsInfo)); err != nil {
+		log.Fatal(err)
 	}
 
+	chSignal := make(chan os.Signal, 1)
+	signal.Notify(chSignal, syscall.SIGTERM, syscall.SIGINT)
+
+	chReload := make(chan os.Signal, 1)
+	signal.Notify(chReload, syscall.SIGHUP)
+
 	for {
 		select {
 		case err := <-super.ChErr:
 			if err != nil {
 				log.Fatal(err)
 			}
+		case <-chReload:
+			reloadConfig(*configPath, &super, decodePool, metricsServer)
+		case sig := <-chSignal:
+			log.WithFields(log.Fields{"signal": sig}).Info("Shutting down")
+			os.Exit(shutdown(&super, haInstance, chSignal, config.GetIcingadbInfo().ShutdownTimeout))
 		}
 	}
 }
 
-func startConfigSyncOperators(super *supervisor.Supervisor, haInstance *ha.HA) {
-	objectTypes := []*configobject.ObjectInformation{
-		&host.ObjectInformation,
-		&hostcustomvar.ObjectInformation,
-		&downtime.ObjectInformation,
+// configObjectTypes are the object types of the config sync.
+var configObjectTypes = []*configobject.ObjectInformation{
+	&host.ObjectInformation,
+	&hostcustomvar.ObjectInformation,
+	&downtime.ObjectInformation,
+
+	&service.ObjectInformation,
+	&servicecustomvar.ObjectInformation,
+	&servicestate.ObjectInformation,
+
+	&hostgroup.ObjectInformation,
+	&hostgroupcustomvar.ObjectInformation,
+	&hostgroupmember.ObjectInformation,
+
+	&servicegroup.ObjectInformation,
+	&servicegroupcustomvar.ObjectInformation,
+	&servicegroupmember.ObjectInformation,
+
+	&user.ObjectInformation,
+	&usercustomvar.ObjectInformation,
+
+	&usergroup.ObjectInformation,
+	&usergroupcustomvar.ObjectInformation,
+	&usergroupmember.ObjectInformation,
+
+	&notification.ObjectInformation,
+	&notificationcustomvar.ObjectInformation,
+	&notificationuser.ObjectInformation,
+	&notificationusergroup.ObjectInformation,
+
+	&customvar.ObjectInformation,
+	&customvarflat.ObjectInformation,
 
-		&service.ObjectInformation,
-		&servicecustomvar.ObjectInformation,
-		&servicestate.ObjectInformation,
+	&zone.ObjectInformation,
 
-		&hostgroup.ObjectInformation,
-		&hostgroupcustomvar.ObjectInformation,
-		&hostgroupmember.ObjectInformation,
+	&endpoint.ObjectInformation,
 
-		&servicegroup.ObjectInformation,
-		&servicegroupcustomvar.ObjectInformation,
-		&servicegroupmember.ObjectInformation,
+	&actionurl.ObjectInformation,
+	&notesurl.ObjectInformation,
+	&iconimage.ObjectInformation,
 
-		&user.ObjectInformation,
-		&usercustomvar.ObjectInformation,
+	&timeperiod.ObjectInformation,
+	&timeperiodcustomvar.ObjectInformation,
+	&timeperiodoverrideinclude.ObjectInformation,
+	&timeperiodoverrideexclude.ObjectInformation,
+	&timeperiodrange.ObjectInformation,
 
-		&usergroup.ObjectInformation,
-		&usergroupcustomvar.ObjectInformation,
-		&usergroupmember.ObjectInformation,
+	&checkcommand.ObjectInformation,
+	&checkcommandcustomvar.ObjectInformation,
+	&checkcommandargument.ObjectInformation,
+	&checkcommandenvvar.ObjectInformation,
 
-		&notification.ObjectInformation,
-		&notificationcustomvar.ObjectInformation,
-		&notificationuser.ObjectInformation,
-		&notificationusergroup.ObjectInformation,
+	&eventcommand.ObjectInformation,
+	&eventcommandcustomvar.ObjectInformation,
+	&eventcommandargument.ObjectInformation,
+	&eventcommandenvvar.ObjectInformation,
 
-		&customvar.ObjectInformation,
-		&customvarflat.ObjectInformation,
+	&notificationcommand.ObjectInformation,
+	&notificationcommandcustomvar.ObjectInformation,
+	&notificationcommandargument.ObjectInformation,
+	&notificationcommandenvvar.ObjectInformation,
 
-		&zone.ObjectInformation,
+	&comment.ObjectInformation,
+	&hoststate.ObjectInformation,
+}
 
-		&endpoint.ObjectInformation,
+func startConfigSyncOperators(super *supervisor.Supervisor, haInstance *ha.HA) {
+	for _, objectInformation := range configObjectTypes {
+		information := objectInformation
+		chHA := haInstance.RegisterNotificationListener(information.NotificationListenerType)
+
+		// Shutdown waits for the Operators, which stop after their current config delta.
+		super.Go(func() {
+			if err := configsync.Operator(super, chHA, information); err != nil {
+				super.ChErr <- err
+			}
+		})
+	}
+}
 
-		&actionurl.ObjectInformation,
-		&notesurl.ObjectInformation,
-		&iconimage.ObjectInformation,
+// connectRedis returns a RDBWrapper connecting to the configured Redis.
+func connectRedis(redisInfo *config.RedisInfo) (*connection.RDBWrapper, error) {
+	redisTls, err := redisInfo.TLS().MakeConfig()
+	if err != nil {
+		return nil, err
+	}
 
-		&timeperiod.ObjectInformation,
-		&timeperiodcustomvar.ObjectInformation,
-		&timeperiodoverrideinclude.ObjectInformation,
-		&timeperiodoverrideexclude.ObjectInformation,
-		&timeperiodrange.ObjectInformation,
+	return connection.NewRDBWrapperWithOptions(&connection.RedisOptions{
+		Address:   redisInfo.Address(),
+		User:      redisInfo.User,
+		Password:  redisInfo.Password,
+		PoolSize:  redisInfo.PoolSize,
+		TLSConfig: redisTls,
+
+		MasterName:       redisInfo.MasterName,
+		SentinelAddrs:    redisInfo.Sentinels,
+		SentinelPassword: redisInfo.SentinelPassword,
+	}), nil
+}
 
-		&checkcommand.ObjectInformation,
-		&checkcommandcustomvar.ObjectInformation,
-		&checkcommandargument.ObjectInformation,
-		&checkcommandenvvar.ObjectInformation,
+// connectDatabase returns a DBWrapper connecting to the configured database.
+func connectDatabase(mysqlInfo *config.MysqlInfo) (*connection.DBWrapper, error) {
+	dialect, err := connection.GetDialect(mysqlInfo.Type)
+	if err != nil {
+		return nil, err
+	}
 
-		&eventcommand.ObjectInformation,
-		&eventcommandcustomvar.ObjectInformation,
-		&eventcommandargument.ObjectInformation,
-		&eventcommandenvvar.ObjectInformation,
+	dsn, err := mysqlInfo.Dsn()
+	if err != nil {
+		return nil, err
+	}
 
-		&notificationcommand.ObjectInformation,
-		&notificationcommandcustomvar.ObjectInformation,
-		&notificationcommandargument.ObjectInformation,
-		&notificationcommandenvvar.ObjectInformation,
+	return connection.NewDBWrapperWithDialect(dialect, dsn, mysqlInfo.MaxOpenConns)
+}
 
-		&comment.ObjectInformation,
-		&hoststate.ObjectInformation,
+// metricsAddress returns the address to serve metrics at, empty if disabled.
+func metricsAddress(metricsInfo *config.MetricsInfo) string {
+	if metricsInfo.Host == "" {
+		return ""
 	}
 
-	for _, objectInformation := range objectTypes {
-		go func(information *configobject.ObjectInformation) {
-			super.ChErr <- configsync.Operator(super, haInstance.RegisterNotificationListener(information.NotificationListenerType), information)
-		}(objectInformation)
+	return metricsInfo.Host + ":" + metricsInfo.Port
+}
+
+// reloadConfig re-reads the config file and applies the settings which can be changed at runtime.
+// Changed settings which need a restart are reported.
+func reloadConfig(path string, super *supervisor.Supervisor, decodePool *jsondecoder.Pool, metricsServer *prometheus.Server) {
+	log.WithFields(log.Fields{"config": path}).Info("Reloading config")
+
+	oldMetricsAddress := metricsAddress(config.GetMetricsInfo())
+
+	restartRequired, err := config.Reload(path)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Can't reload config, keeping the current one")
+		return
 	}
+
+	level, _ := log.ParseLevel(config.GetLogging().Level)
+	log.SetLevel(level)
+
+	if address := metricsAddress(config.GetMetricsInfo()); address != oldMetricsAddress {
+		if err := metricsServer.Listen(address); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("Can't serve metrics")
+		}
+	}
+
+	decodePool.Resize(config.GetIcingadbInfo().DecodeWorkers)
+	super.Dbw.SetMaxOpenConns(config.GetMysqlInfo().MaxOpenConns)
+
+	for _, key := range restartRequired {
+		log.WithFields(log.Fields{"setting": key}).Warn("Changed setting requires a restart to take effect")
+	}
+
+	log.Info("Reloaded config")
 }
 
-func handleSignal(ch <-chan os.Signal) {
-	if sig, ok := <-ch; ok {
-		log.WithFields(log.Fields{"signal": sig}).Info("Shutting down")
-		os.Exit(0)
+// shutdown stops all syncs, waits up to timeout for the workers to finish their current batches
+// and hands over the HA responsibility. It returns the exit code.
+func shutdown(super *supervisor.Supervisor, haInstance *ha.HA, chSignal <-chan os.Signal, timeout time.Duration) int {
+	close(super.Done)
+	haInstance.StopSync()
+
+	workersDone := make(chan struct{})
+	go func() {
+		super.Workers.Wait()
+		close(workersDone)
+	}()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-workersDone:
+			if err := haInstance.Resign(); err != nil {
+				log.WithFields(log.Fields{"context": "HA", "error": err}).Error("Can't hand over responsibility")
+				return 1
+			}
+
+			log.Info("Shut down")
+			return 0
+		case err := <-super.ChErr:
+			// Workers report their last errors while finishing.
+			if err != nil {
+				log.WithFields(log.Fields{"error": err}).Error("Error while shutting down")
+			}
+		case sig := <-chSignal:
+			log.WithFields(log.Fields{"signal": sig}).Warn("Exiting without waiting for workers")
+			return 1
+		case <-deadline.C:
+			log.Warnf("Workers didn't finish within %s, exiting anyway", timeout)
+			return 1
+		}
 	}
 }
@@ -0,0 +1,166 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package perfdata parses the performance data of check results in the monitoring plugins format,
+// e.g. "'disk usage /'=85%;80;90;0;100 time=0.02s".
+package perfdata
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Metric is a single label=value[UOM];[warn];[crit];[min];[max] of performance data.
+type Metric struct {
+	Label string `json:"label"`
+	// Value is nil if the value is unknown ("U").
+	Value *float64 `json:"value"`
+	Unit  string   `json:"unit"`
+	// Warn and Crit are threshold ranges as given, e.g. "10", "10:", "~:10" or "@10:20", empty if not set.
+	Warn string   `json:"warn,omitempty"`
+	Crit string   `json:"crit,omitempty"`
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+}
+
+// Parse parses perfdata. It returns all valid metrics, together with an error about the first invalid one.
+func Parse(perfdata string) ([]Metric, error) {
+	var metrics []Metric
+	var firstErr error
+
+	for _, token := range split(perfdata) {
+		metric, err := parseMetric(token)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, firstErr
+}
+
+// split splits perfdata at spaces outside of quoted labels.
+func split(perfdata string) []string {
+	var tokens []string
+	var token strings.Builder
+	quoted := false
+
+	for _, r := range perfdata {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+
+			continue
+		}
+
+		token.WriteRune(r)
+	}
+
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+
+	return tokens
+}
+
+// parseMetric parses a single label=value[UOM];[warn];[crit];[min];[max].
+func parseMetric(token string) (Metric, error) {
+	eq := strings.LastIndex(token, "=")
+	if eq < 1 {
+		return Metric{}, fmt.Errorf("invalid performance data %q: missing label or value", token)
+	}
+
+	metric := Metric{Label: unquoteLabel(token[:eq])}
+	if metric.Label == "" {
+		return Metric{}, fmt.Errorf("invalid performance data %q: empty label", token)
+	}
+
+	fields := strings.Split(token[eq+1:], ";")
+	if len(fields) > 5 {
+		return Metric{}, fmt.Errorf("invalid performance data %q: too many fields", token)
+	}
+
+	for len(fields) < 5 {
+		fields = append(fields, "")
+	}
+
+	var err error
+	if metric.Value, metric.Unit, err = parseValue(fields[0]); err != nil {
+		return Metric{}, fmt.Errorf("invalid performance data %q: %s", token, err.Error())
+	}
+
+	metric.Warn = fields[1]
+	metric.Crit = fields[2]
+
+	if metric.Min, err = parseOptionalNumber(fields[3]); err != nil {
+		return Metric{}, fmt.Errorf("invalid performance data %q: invalid min: %s", token, err.Error())
+	}
+
+	if metric.Max, err = parseOptionalNumber(fields[4]); err != nil {
+		return Metric{}, fmt.Errorf("invalid performance data %q: invalid max: %s", token, err.Error())
+	}
+
+	return metric, nil
+}
+
+// unquoteLabel removes the single quotes around label. Two single quotes within stand for one.
+func unquoteLabel(label string) string {
+	if len(label) >= 2 && label[0] == '\'' && label[len(label)-1] == '\'' {
+		label = strings.Replace(label[1:len(label)-1], "''", "'", -1)
+	}
+
+	return label
+}
+
+// parseValue splits the number and unit of value, e.g. "0.5s". A value of "U" is unknown and returned as nil.
+func parseValue(value string) (*float64, string, error) {
+	if value == "U" {
+		return nil, "", nil
+	}
+
+	end := 0
+	for end < len(value) && strings.IndexByte("0123456789.+-eE", value[end]) >= 0 {
+		end++
+	}
+
+	// An exponent without digits is rather the start of a unit, e.g. "5EB".
+	for end > 0 && (value[end-1] == 'e' || value[end-1] == 'E') {
+		end--
+	}
+
+	number, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid value %q", value)
+	}
+
+	return &number, value[end:], nil
+}
+
+func parseOptionalNumber(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	// ParseFloat accepts "NaN" and "Inf", which aren't numbers in perfdata.
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return nil, fmt.Errorf("invalid number %q", value)
+	}
+
+	return &number, nil
+}
@@ -0,0 +1,37 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package perfdata
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestParse(t *testing.T) {
+	metrics, err := Parse("'disk usage /'=85.5%;80;90;0;100 time=2e-3s;;~:1 'it''s'=U rx=5EB;@10:20 count=12c;;;;")
+	assert.NoError(t, err)
+	assert.Equal(t, []Metric{
+		{Label: "disk usage /", Value: float(85.5), Unit: "%", Warn: "80", Crit: "90", Min: float(0), Max: float(100)},
+		{Label: "time", Value: float(0.002), Unit: "s", Crit: "~:1"},
+		{Label: "it's"},
+		{Label: "rx", Value: float(5), Unit: "EB", Warn: "@10:20"},
+		{Label: "count", Value: float(12), Unit: "c"},
+	}, metrics)
+}
+
+func TestParseInvalid(t *testing.T) {
+	metrics, err := Parse("a=1 =2 b c=x d=1;2;3;4;5;6 e=1;;;low g=1;;;NaN f=2")
+	assert.EqualError(t, err, `invalid performance data "=2": missing label or value`)
+	assert.Equal(t, []Metric{
+		{Label: "a", Value: float(1)},
+		{Label: "f", Value: float(2)},
+	}, metrics)
+
+	metrics, err = Parse("")
+	assert.NoError(t, err)
+	assert.Nil(t, metrics)
+}
@@ -5,11 +5,64 @@ package prometheus
 import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
+	"net"
 	"net/http"
+	"sync"
 )
 
-func HandleHttp(addr string, chErr chan error) {
-	http.Handle("/metrics", promhttp.Handler())
+// Server serves the metrics and other handlers and can be moved to another address at runtime.
+type Server struct {
+	chErr  chan error
+	mux    *http.ServeMux
+	mu     sync.Mutex
+	server *http.Server
+}
+
+func NewServer(chErr chan error) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &Server{chErr: chErr, mux: mux}
+}
+
+// Handle registers handler for pattern in addition to /metrics.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Listen stops serving at the current address, if any, and starts serving at addr unless it's empty.
+func (s *Server) Listen(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		if err := s.server.Close(); err != nil {
+			log.WithFields(log.Fields{"error": err}).Warn("Can't stop serving metrics")
+		}
+
+		log.Infof("Stopped serving metrics at http://%s/metrics", s.server.Addr)
+		s.server = nil
+	}
+
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: addr, Handler: s.mux}
+	s.server = server
+
 	log.Infof("Serving metrics at http://%s/metrics", addr)
-	chErr <- http.ListenAndServe(addr, nil)
+
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			s.chErr <- err
+		}
+	}()
+
+	return nil
 }
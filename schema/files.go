@@ -0,0 +1,3000 @@
+// Code generated by tools/embedschema. DO NOT EDIT.
+
+package schema
+
+var files = map[string]string{
+	"mysql/mysql.schema.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"\n" +
+		"SET sql_mode = 'STRICT_ALL_TABLES,NO_ENGINE_SUBSTITUTION';\n" +
+		"SET innodb_strict_mode = 1;\n" +
+		"\n" +
+		"CREATE TABLE host (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(host.vars)',\n" +
+		"  groups_checksum binary(20) NOT NULL COMMENT 'sha1(hostgroup.name + hostgroup.name ...)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  address varchar(255) NOT NULL,\n" +
+		"  address6 varchar(255) NOT NULL,\n" +
+		"  address_bin binary(4) DEFAULT NULL,\n" +
+		"  address6_bin binary(16) DEFAULT NULL,\n" +
+		"\n" +
+		"  checkcommand varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'checkcommand.name',\n" +
+		"  checkcommand_id binary(20) NOT NULL COMMENT 'checkcommand.id',\n" +
+		"\n" +
+		"  max_check_attempts int(10) unsigned NOT NULL,\n" +
+		"\n" +
+		"  check_timeperiod varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'timeperiod.name',\n" +
+		"  check_timeperiod_id binary(20) DEFAULT NULL COMMENT 'timeperiod.id',\n" +
+		"\n" +
+		"  check_timeout int(10) unsigned DEFAULT NULL,\n" +
+		"  check_interval int(10) unsigned NOT NULL,\n" +
+		"  check_retry_interval int(10) unsigned NOT NULL,\n" +
+		"\n" +
+		"  active_checks_enabled enum('y','n') NOT NULL,\n" +
+		"  passive_checks_enabled enum('y','n') NOT NULL,\n" +
+		"  event_handler_enabled enum('y','n') NOT NULL,\n" +
+		"  notifications_enabled enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  flapping_enabled enum('y','n') NOT NULL,\n" +
+		"  flapping_threshold_low float unsigned NOT NULL,\n" +
+		"  flapping_threshold_high float unsigned NOT NULL,\n" +
+		"\n" +
+		"  perfdata_enabled enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  eventcommand varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'eventcommand.name',\n" +
+		"  eventcommand_id binary(20) DEFAULT NULL COMMENT 'eventcommand.id',\n" +
+		"\n" +
+		"  is_volatile enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  action_url_id binary(20) DEFAULT NULL COMMENT 'action_url.id',\n" +
+		"  notes_url_id binary(20) DEFAULT NULL COMMENT 'notes_url.id',\n" +
+		"  notes text NOT NULL,\n" +
+		"  icon_image_id binary(20) DEFAULT NULL COMMENT 'icon_image.id',\n" +
+		"  icon_image_alt varchar(32) NOT NULL,\n" +
+		"\n" +
+		"  zone varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'zone.name',\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  command_endpoint varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'endpoint.name',\n" +
+		"  command_endpoint_id binary(20) DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id),\n" +
+		"  KEY idx_action_url_checksum (action_url_id) COMMENT 'cleanup',\n" +
+		"  KEY idx_notes_url_checksum (notes_url_id) COMMENT 'cleanup',\n" +
+		"  KEY idx_icon_image_checksum (icon_image_id) COMMENT 'cleanup'\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE hostgroup (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(hostgroup.vars)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE hostgroup_member (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + host_id + hostgroup_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  hostgroup_id binary(20) NOT NULL COMMENT 'hostgroup.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE host_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + host_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE hostgroup_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + hostgroup_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  hostgroup_id binary(20) NOT NULL COMMENT 'hostgroup.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE host_state (\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"\n" +
+		"  state_type enum('hard', 'soft') NOT NULL,\n" +
+		"  soft_state tinyint(1) unsigned NOT NULL,\n" +
+		"  hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  previous_hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  attempt tinyint(1) unsigned NOT NULL,\n" +
+		"  severity smallint unsigned NOT NULL,\n" +
+		"\n" +
+		"  output text DEFAULT NULL,\n" +
+		"  long_output text DEFAULT NULL,\n" +
+		"  performance_data text DEFAULT NULL,\n" +
+		"  check_commandline text DEFAULT NULL,\n" +
+		"\n" +
+		"  is_problem enum('y', 'n') NOT NULL,\n" +
+		"  is_handled enum('y', 'n') NOT NULL,\n" +
+		"  is_reachable enum('y', 'n') NOT NULL,\n" +
+		"  is_flapping enum('y', 'n') NOT NULL,\n" +
+		"\n" +
+		"  is_acknowledged enum('y', 'n', 'sticky') NOT NULL,\n" +
+		"  acknowledgement_comment_id binary(20) DEFAULT NULL COMMENT 'comment.id',\n" +
+		"\n" +
+		"  in_downtime enum('y', 'n') NOT NULL,\n" +
+		"\n" +
+		"  execution_time int(10) unsigned DEFAULT NULL,\n" +
+		"  latency int(10) unsigned DEFAULT NULL,\n" +
+		"  timeout int(10) unsigned DEFAULT NULL,\n" +
+		"  check_source text DEFAULT NULL,\n" +
+		"\n" +
+		"  last_update bigint(20) unsigned NOT NULL,\n" +
+		"  last_state_change bigint(20) unsigned NOT NULL,\n" +
+		"  next_check bigint(20) unsigned NOT NULL,\n" +
+		"  next_update bigint(20) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (host_id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE service (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(service.vars)',\n" +
+		"  groups_checksum binary(20) NOT NULL COMMENT 'sha1(servicegroup.name + servicegroup.name ...)',\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'sha1(host.id)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  checkcommand varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'checkcommand.name',\n" +
+		"  checkcommand_id binary(20) NOT NULL COMMENT 'checkcommand.id',\n" +
+		"\n" +
+		"  max_check_attempts int(10) unsigned NOT NULL,\n" +
+		"\n" +
+		"  check_timeperiod varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'timeperiod.name',\n" +
+		"  check_timeperiod_id binary(20) DEFAULT NULL COMMENT 'timeperiod.id',\n" +
+		"\n" +
+		"  check_timeout int(10) unsigned DEFAULT NULL,\n" +
+		"  check_interval int(10) unsigned NOT NULL,\n" +
+		"  check_retry_interval int(10) unsigned NOT NULL,\n" +
+		"\n" +
+		"  active_checks_enabled enum('y','n') NOT NULL,\n" +
+		"  passive_checks_enabled enum('y','n') NOT NULL,\n" +
+		"  event_handler_enabled enum('y','n') NOT NULL,\n" +
+		"  notifications_enabled enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  flapping_enabled enum('y','n') NOT NULL,\n" +
+		"  flapping_threshold_low float unsigned NOT NULL,\n" +
+		"  flapping_threshold_high float unsigned NOT NULL,\n" +
+		"\n" +
+		"  perfdata_enabled enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  eventcommand varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'eventcommand.name',\n" +
+		"  eventcommand_id binary(20) DEFAULT NULL COMMENT 'eventcommand.id',\n" +
+		"\n" +
+		"  is_volatile enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  action_url_id binary(20) DEFAULT NULL COMMENT 'action_url.id',\n" +
+		"  notes_url_id binary(20) DEFAULT NULL COMMENT 'notes_url.id',\n" +
+		"  notes text NOT NULL,\n" +
+		"  icon_image_id binary(20) DEFAULT NULL COMMENT 'icon_image.id',\n" +
+		"  icon_image_alt varchar(32) NOT NULL,\n" +
+		"\n" +
+		"  zone varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'zone.name',\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  command_endpoint varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'endpoint.name',\n" +
+		"  command_endpoint_id binary(20) DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE servicegroup (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(servicegroup.vars)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE servicegroup_member (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + servicegroup_id + service_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  service_id binary(20) NOT NULL COMMENT 'service.id',\n" +
+		"  servicegroup_id binary(20) NOT NULL COMMENT 'servicegroup.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE service_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + service_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  service_id binary(20) NOT NULL COMMENT 'service.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE servicegroup_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + servicegroup_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  servicegroup_id binary(20) NOT NULL COMMENT 'servicegroup.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE service_state (\n" +
+		"  service_id binary(20) NOT NULL COMMENT 'service.id',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"\n" +
+		"  state_type enum('hard', 'soft') NOT NULL,\n" +
+		"  soft_state tinyint(1) unsigned NOT NULL,\n" +
+		"  hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  previous_hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  attempt tinyint(1) unsigned NOT NULL,\n" +
+		"  severity smallint unsigned NOT NULL,\n" +
+		"\n" +
+		"  output text DEFAULT NULL,\n" +
+		"  long_output text DEFAULT NULL,\n" +
+		"  performance_data text DEFAULT NULL,\n" +
+		"  check_commandline text DEFAULT NULL,\n" +
+		"\n" +
+		"  is_problem enum('y', 'n') NOT NULL,\n" +
+		"  is_handled enum('y', 'n') NOT NULL,\n" +
+		"  is_reachable enum('y', 'n') NOT NULL,\n" +
+		"  is_flapping enum('y', 'n') NOT NULL,\n" +
+		"\n" +
+		"  is_acknowledged enum('y', 'n', 'sticky') NOT NULL,\n" +
+		"  acknowledgement_comment_id binary(20) DEFAULT NULL COMMENT 'comment.id',\n" +
+		"\n" +
+		"  in_downtime enum('y', 'n') NOT NULL,\n" +
+		"\n" +
+		"  execution_time int(10) unsigned DEFAULT NULL,\n" +
+		"  latency int(10) unsigned DEFAULT NULL,\n" +
+		"  timeout int(10) unsigned DEFAULT NULL,\n" +
+		"  check_source text DEFAULT NULL,\n" +
+		"\n" +
+		"  last_update bigint(20) unsigned NOT NULL,\n" +
+		"  last_state_change bigint(20) unsigned NOT NULL,\n" +
+		"  next_check bigint(20) unsigned NOT NULL,\n" +
+		"  next_update bigint(20) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (service_id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE endpoint (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL,\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) NOT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE environment (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE icingadb_instance (\n" +
+		"  id binary(16) NOT NULL COMMENT 'UUIDv4',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  heartbeat bigint(20) unsigned NOT NULL COMMENT '*nix timestamp',\n" +
+		"  responsible enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"CREATE TABLE checkcommand (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + type + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  command text NOT NULL,\n" +
+		"  timeout int(10) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_argument (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + argument_key)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  argument_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  argument_value text DEFAULT NULL,\n" +
+		"  argument_order tinyint(3) DEFAULT NULL,\n" +
+		"  description text DEFAULT NULL,\n" +
+		"  argument_key_override varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL,\n" +
+		"  repeat_key enum('y','n') NOT NULL,\n" +
+		"  required enum('y','n') NOT NULL,\n" +
+		"  set_if varchar(255) DEFAULT NULL,\n" +
+		"  skip_key enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_envvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + envvar_key)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  envvar_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  envvar_value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"\n" +
+		"CREATE TABLE eventcommand (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + type + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  command text NOT NULL,\n" +
+		"  timeout smallint(5) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_argument (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + argument_key)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  argument_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  argument_value text DEFAULT NULL,\n" +
+		"  argument_order tinyint(3) DEFAULT NULL,\n" +
+		"  description text DEFAULT NULL,\n" +
+		"  argument_key_override varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL,\n" +
+		"  repeat_key enum('y','n') NOT NULL,\n" +
+		"  required enum('y','n') NOT NULL,\n" +
+		"  set_if varchar(255) DEFAULT NULL,\n" +
+		"  skip_key enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_envvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + envvar_key)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  envvar_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  envvar_value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + type + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  command text NOT NULL,\n" +
+		"  timeout smallint(5) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_argument (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + argument_key)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  argument_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  argument_value text DEFAULT NULL,\n" +
+		"  argument_order tinyint(3) DEFAULT NULL,\n" +
+		"  description text DEFAULT NULL,\n" +
+		"  argument_key_override varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL,\n" +
+		"  repeat_key enum('y','n') NOT NULL,\n" +
+		"  required enum('y','n') NOT NULL,\n" +
+		"  set_if varchar(255) DEFAULT NULL,\n" +
+		"  skip_key enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_envvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + envvar_key)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  envvar_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  envvar_value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + command_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE comment (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) DEFAULT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) DEFAULT NULL COMMENT 'service.id',\n" +
+		"\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL,\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  author varchar(255) NOT NULL COLLATE utf8mb4_unicode_ci,\n" +
+		"  text text NOT NULL,\n" +
+		"  entry_type enum('comment','ack') NOT NULL,\n" +
+		"  entry_time bigint(20) unsigned NOT NULL,\n" +
+		"  is_persistent enum('y','n') NOT NULL,\n" +
+		"  is_sticky enum('y','n') NOT NULL,\n" +
+		"  expire_time bigint(20) unsigned DEFAULT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE downtime (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"\n" +
+		"  triggered_by_id binary(20) NULL DEFAULT NULL COMMENT 'downtime.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) DEFAULT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) DEFAULT NULL COMMENT 'service.id',\n" +
+		"\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  author varchar(255) NOT NULL COLLATE utf8mb4_unicode_ci,\n" +
+		"  comment text NOT NULL,\n" +
+		"  entry_time bigint(20) unsigned NOT NULL,\n" +
+		"  scheduled_start_time bigint(20) unsigned NOT NULL,\n" +
+		"  scheduled_end_time bigint(20) unsigned NOT NULL,\n" +
+		"  flexible_duration bigint(20) unsigned NOT NULL,\n" +
+		"  is_flexible enum('y', 'n') NOT NULL,\n" +
+		"\n" +
+		"  is_in_effect enum('y', 'n') NOT NULL,\n" +
+		"  start_time bigint(20) unsigned DEFAULT NULL COMMENT 'Time when the host went into a problem state during the downtimes timeframe',\n" +
+		"  end_time bigint(20) unsigned DEFAULT NULL COMMENT 'Problem state assumed: scheduled_end_time if fixed, start_time + flexible_duration otherwise',\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notification (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL,\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(notification.vars)',\n" +
+		"  users_checksum binary(20) NOT NULL,\n" +
+		"  usergroups_checksum binary(20) NOT NULL,\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) DEFAULT NULL COMMENT 'service.id',\n" +
+		"  command_id binary(20) NOT NULL COMMENT 'command.id',\n" +
+		"\n" +
+		"  times_begin int(10) unsigned DEFAULT NULL,\n" +
+		"  times_end int(10) unsigned DEFAULT NULL,\n" +
+		"  notification_interval int(10) unsigned NOT NULL,\n" +
+		"  timeperiod_id binary(20) DEFAULT NULL COMMENT 'timeperiod.id',\n" +
+		"\n" +
+		"  states tinyint(2) unsigned NOT NULL,\n" +
+		"  types smallint(3) unsigned NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notification_user (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + notification_id + user_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  notification_id binary(20) NOT NULL COMMENT 'notification.id',\n" +
+		"  user_id binary(20) NOT NULL COMMENT 'user.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notification_usergroup (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + notification_id + usergroup_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  notification_id binary(20) NOT NULL COMMENT 'notification.id',\n" +
+		"  usergroup_id binary(20) NOT NULL COMMENT 'usergroup.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notification_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + notification_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  notification_id binary(20) NOT NULL COMMENT 'notification.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE icon_image (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(icon_image)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  icon_image text COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id),\n" +
+		"  KEY idx_icon_image (icon_image(255))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE action_url (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(action_url)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  action_url text COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id),\n" +
+		"  KEY idx_action_url (action_url(255))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notes_url (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(notes_url)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  notes_url text COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id),\n" +
+		"  KEY idx_notes_url (notes_url(255))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE timeperiod (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(env.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  prefer_includes enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_range (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + range_id + timeperiod_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  timeperiod_id binary(20) NOT NULL COMMENT 'timeperiod.id',\n" +
+		"  range_key varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  range_value varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_override_include (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + include_id + timeperiod_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  timeperiod_id binary(20) NOT NULL COMMENT 'timeperiod.id',\n" +
+		"  override_id binary(20) NOT NULL COMMENT 'timeperiod.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_override_exclude (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + exclude_id + timeperiod_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'env.id',\n" +
+		"  timeperiod_id binary(20) NOT NULL COMMENT 'timeperiod.id',\n" +
+		"  override_id binary(20) NOT NULL COMMENT 'timeperiod.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + timeperiod_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  timeperiod_id binary(20) NOT NULL COMMENT 'timeperiod.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name + value)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL COLLATE utf8_bin,\n" +
+		"  value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=COMPRESSED DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE customvar_flat (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + flatname + flatvalue)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'sha1(customvar.id)',\n" +
+		"  flatname_checksum binary(20) NOT NULL COMMENT 'sha1(flatname after conversion)',\n" +
+		"\n" +
+		"  flatname varchar(512) NOT NULL COLLATE utf8_bin COMMENT 'Path converted with `.` and `[ ]`',\n" +
+		"  flatvalue text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=COMPRESSED DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE user (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(user.vars)',\n" +
+		"  groups_checksum binary(20) NOT NULL COMMENT 'sha1(usergroup.name + userroup.name ...)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  email varchar(255) NOT NULL,\n" +
+		"  pager varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  notifications_enabled enum('y', 'n') NOT NULL,\n" +
+		"\n" +
+		"  timeperiod_id binary(20) DEFAULT NULL COMMENT 'timeperiod.id',\n" +
+		"\n" +
+		"  states tinyint(2) unsigned NOT NULL,\n" +
+		"  types smallint(3) unsigned NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE usergroup (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  customvars_checksum binary(20) NOT NULL COMMENT 'sha1(usergroup.vars)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"  display_name varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  zone_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE usergroup_member (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + usergroup_id + user_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  user_id binary(20) NOT NULL COMMENT 'user.id',\n" +
+		"  usergroup_id binary(20) NOT NULL COMMENT 'usergroup.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE user_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + user_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  user_id binary(20) NOT NULL COMMENT 'user.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE usergroup_customvar (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + usergroup_id + customvar_id)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  usergroup_id binary(20) NOT NULL COMMENT 'usergroup.id',\n" +
+		"  customvar_id binary(20) NOT NULL COMMENT 'customvar.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE zone (\n" +
+		"  id binary(20) NOT NULL COMMENT 'sha1(environment.name + name)',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  name_checksum binary(20) NOT NULL COMMENT 'sha1(name)',\n" +
+		"  properties_checksum binary(20) NOT NULL COMMENT 'sha1(all properties)',\n" +
+		"  parents_checksum binary(20) NOT NULL COMMENT 'sha1(all parents checksums)',\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,\n" +
+		"\n" +
+		"  is_global enum('y','n') NOT NULL,\n" +
+		"  parent_id binary(20) DEFAULT NULL COMMENT 'zone.id',\n" +
+		"\n" +
+		"  depth tinyint(3) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id),\n" +
+		"  INDEX idx_parent_id (parent_id),\n" +
+		"  UNIQUE INDEX idx_environment_id_id (environment_id,id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"CREATE TABLE notification_history (\n" +
+		"  id binary(16) NOT NULL COMMENT 'UUID',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  endpoint_id binary(20) NULL DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) NULL DEFAULT NULL COMMENT 'service.id',\n" +
+		"  notification_id binary(20) NOT NULL COMMENT 'notification.id',\n" +
+		"\n" +
+		"  type enum('downtime_start', 'downtime_end', 'downtime_removed', 'custom', 'acknowledgement', 'problem', 'recovery', 'flapping_start', 'flapping_end') NOT NULL,\n" +
+		"  send_time bigint(20) unsigned NOT NULL,\n" +
+		"  state tinyint(1) unsigned NOT NULL,\n" +
+		"  previous_hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  author text NOT NULL,\n" +
+		"  `text` text NOT NULL,\n" +
+		"  users_notified smallint(5) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE user_notification_history (\n" +
+		"  id binary(16) NOT NULL COMMENT 'UUID',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  notification_history_id binary(16) NOT NULL COMMENT 'UUID notification_history.id',\n" +
+		"  user_id binary(20) NOT NULL COMMENT 'user.id',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE state_history (\n" +
+		"  id binary(16) NOT NULL COMMENT 'UUID',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  endpoint_id binary(20) NULL DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) NULL DEFAULT NULL COMMENT 'service.id',\n" +
+		"\n" +
+		"  event_time bigint(20) unsigned NOT NULL,\n" +
+		"  state_type enum('hard', 'soft') NOT NULL,\n" +
+		"  soft_state tinyint(1) unsigned NOT NULL,\n" +
+		"  hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  previous_soft_state tinyint(1) unsigned NOT NULL,\n" +
+		"  previous_hard_state tinyint(1) unsigned NOT NULL,\n" +
+		"  attempt tinyint(1) unsigned NOT NULL,\n" +
+		"  output text DEFAULT NULL,\n" +
+		"  long_output text DEFAULT NULL,\n" +
+		"  max_check_attempts int(10) unsigned NOT NULL,\n" +
+		"  check_source text DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE downtime_history (\n" +
+		"  downtime_id binary(20) NOT NULL COMMENT 'downtime.id',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  endpoint_id binary(20) NULL DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"  triggered_by_id binary(20) NULL DEFAULT NULL COMMENT 'downtime.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) NULL DEFAULT NULL COMMENT 'service.id',\n" +
+		"\n" +
+		"  entry_time bigint(20) unsigned NOT NULL,\n" +
+		"  author varchar(255) NOT NULL COLLATE utf8mb4_unicode_ci,\n" +
+		"  comment text NOT NULL,\n" +
+		"  is_flexible enum('y', 'n') NOT NULL,\n" +
+		"  flexible_duration bigint(20) unsigned NOT NULL,\n" +
+		"  scheduled_start_time bigint(20) unsigned NOT NULL,\n" +
+		"  scheduled_end_time bigint(20) unsigned NOT NULL,\n" +
+		"  start_time bigint(20) unsigned NOT NULL COMMENT 'Time when the host went into a problem state during the downtimes timeframe',\n" +
+		"  end_time bigint(20) unsigned NOT NULL COMMENT 'Problem state assumed: scheduled_end_time if fixed, start_time + duration otherwise',\n" +
+		"  has_been_cancelled enum('y', 'n') NOT NULL,\n" +
+		"  trigger_time bigint(20) unsigned NOT NULL,\n" +
+		"  cancel_time bigint(20) unsigned NULL DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (downtime_id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE comment_history (\n" +
+		"  comment_id binary(20) NOT NULL COMMENT 'comment.id',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  endpoint_id binary(20) NULL DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) NULL DEFAULT NULL COMMENT 'service.id',\n" +
+		"\n" +
+		"  entry_time bigint(20) unsigned NOT NULL,\n" +
+		"  author varchar(255) NOT NULL COLLATE utf8mb4_unicode_ci,\n" +
+		"  comment text NOT NULL,\n" +
+		"  entry_type enum('comment','ack') NOT NULL,\n" +
+		"  is_persistent enum('y','n') NOT NULL,\n" +
+		"  is_sticky enum('y','n') NOT NULL,\n" +
+		"  expire_time bigint(20) unsigned DEFAULT NULL,\n" +
+		"  remove_time bigint(20) unsigned NULL DEFAULT NULL,\n" +
+		"  has_been_removed enum('y','n') NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (comment_id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE flapping_history (\n" +
+		"  id binary(16) NOT NULL COMMENT 'UUID',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  endpoint_id binary(20) NULL DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) NULL DEFAULT NULL COMMENT 'service.id',\n" +
+		"\n" +
+		"  event_time bigint(20) unsigned NOT NULL,\n" +
+		"  percent_state_change float unsigned NOT NULL,\n" +
+		"  flapping_threshold_low float unsigned NOT NULL,\n" +
+		"  flapping_threshold_high float unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE history (\n" +
+		"  id binary(16) NOT NULL COMMENT 'notification_history_id, state_history_id, flapping_history_id or UUID',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'environment.id',\n" +
+		"  endpoint_id binary(20) NULL DEFAULT NULL COMMENT 'endpoint.id',\n" +
+		"  object_type enum('host', 'service') NOT NULL,\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  service_id binary(20) NULL DEFAULT NULL COMMENT 'service.id',\n" +
+		"  notification_history_id binary(16) NULL DEFAULT NULL COMMENT 'notification_history.id',\n" +
+		"  state_history_id binary(16) NULL DEFAULT NULL COMMENT 'state_history.id',\n" +
+		"  downtime_history_id binary(20) NULL DEFAULT NULL COMMENT 'downtime_history.downtime_id',\n" +
+		"  comment_history_id binary(20) NULL DEFAULT NULL COMMENT 'comment_history.comment_id',\n" +
+		"  flapping_history_id binary(16) NULL DEFAULT NULL COMMENT 'flapping_history.id',\n" +
+		"\n" +
+		"  event_type enum('notification','state_change','downtime_schedule','downtime_start', 'downtime_end','comment_add','comment_remove','flapping_start','flapping_end') NOT NULL,\n" +
+		"  event_time bigint(20) unsigned NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE icingadb_schema (\n" +
+		"  id int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  version smallint(5) unsigned NOT NULL,\n" +
+		"  timestamp bigint(20) unsigned NOT NULL COMMENT '*nix timestamp in milliseconds',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (1, UNIX_TIMESTAMP() * 1000);\n",
+	"mysql/upgrades/1.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Starts tracking the schema version of databases set up before icingadb_schema existed.\n" +
+		"\n" +
+		"CREATE TABLE icingadb_schema (\n" +
+		"  id int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  version smallint(5) unsigned NOT NULL,\n" +
+		"  timestamp bigint(20) unsigned NOT NULL COMMENT '*nix timestamp in milliseconds',\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin ROW_FORMAT=DYNAMIC;\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (1, UNIX_TIMESTAMP() * 1000);\n",
+	"mysql/upgrades/2.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Adds the fencing token to icingadb_instance and allows only one row per environment, so that a takeover can be\n" +
+		"-- done as compare-and-swap. The rows are recreated by the running instances with their next heartbeat.\n" +
+		"\n" +
+		"DELETE FROM icingadb_instance;\n" +
+		"\n" +
+		"ALTER TABLE icingadb_instance\n" +
+		"  ADD COLUMN fencing_token bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'incremented on every takeover',\n" +
+		"  ADD UNIQUE KEY idx_icingadb_instance_environment_id (environment_id);\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (2, UNIX_TIMESTAMP() * 1000);\n",
+	"mysql/upgrades/3.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Adds the tables with the parsed performance data of the current host and service states, one row per label.\n" +
+		"\n" +
+		"CREATE TABLE host_perfdata (\n" +
+		"  host_id binary(20) NOT NULL COMMENT 'host.id',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  label varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  value double DEFAULT NULL COMMENT 'NULL if unknown (U)',\n" +
+		"  unit varchar(255) NOT NULL,\n" +
+		"  warn varchar(255) DEFAULT NULL COMMENT 'threshold range',\n" +
+		"  crit varchar(255) DEFAULT NULL COMMENT 'threshold range',\n" +
+		"  min double DEFAULT NULL,\n" +
+		"  max double DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (host_id, label)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"CREATE TABLE service_perfdata (\n" +
+		"  service_id binary(20) NOT NULL COMMENT 'service.id',\n" +
+		"  environment_id binary(20) NOT NULL COMMENT 'sha1(environment.name)',\n" +
+		"  label varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  value double DEFAULT NULL COMMENT 'NULL if unknown (U)',\n" +
+		"  unit varchar(255) NOT NULL,\n" +
+		"  warn varchar(255) DEFAULT NULL COMMENT 'threshold range',\n" +
+		"  crit varchar(255) DEFAULT NULL COMMENT 'threshold range',\n" +
+		"  min double DEFAULT NULL,\n" +
+		"  max double DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (service_id, label)\n" +
+		") ENGINE=InnoDb ROW_FORMAT=DYNAMIC DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_bin;\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (3, UNIX_TIMESTAMP() * 1000);\n",
+	"pgsql/pgsql.schema.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"\n" +
+		"CREATE TABLE host (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(host.vars)\n" +
+		"  groups_checksum bytea NOT NULL, -- sha1(hostgroup.name + hostgroup.name ...)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  address varchar(255) NOT NULL,\n" +
+		"  address6 varchar(255) NOT NULL,\n" +
+		"  address_bin bytea DEFAULT NULL,\n" +
+		"  address6_bin bytea DEFAULT NULL,\n" +
+		"\n" +
+		"  checkcommand varchar(255) NOT NULL, -- checkcommand.name\n" +
+		"  checkcommand_id bytea NOT NULL, -- checkcommand.id\n" +
+		"\n" +
+		"  max_check_attempts bigint NOT NULL,\n" +
+		"\n" +
+		"  check_timeperiod varchar(255) NOT NULL, -- timeperiod.name\n" +
+		"  check_timeperiod_id bytea DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  check_timeout bigint DEFAULT NULL,\n" +
+		"  check_interval bigint NOT NULL,\n" +
+		"  check_retry_interval bigint NOT NULL,\n" +
+		"\n" +
+		"  active_checks_enabled varchar(1) NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),\n" +
+		"  passive_checks_enabled varchar(1) NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),\n" +
+		"  event_handler_enabled varchar(1) NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),\n" +
+		"  notifications_enabled varchar(1) NOT NULL CHECK (notifications_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  flapping_enabled varchar(1) NOT NULL CHECK (flapping_enabled IN ('y', 'n')),\n" +
+		"  flapping_threshold_low real NOT NULL,\n" +
+		"  flapping_threshold_high real NOT NULL,\n" +
+		"\n" +
+		"  perfdata_enabled varchar(1) NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  eventcommand varchar(255) NOT NULL, -- eventcommand.name\n" +
+		"  eventcommand_id bytea DEFAULT NULL, -- eventcommand.id\n" +
+		"\n" +
+		"  is_volatile varchar(1) NOT NULL CHECK (is_volatile IN ('y', 'n')),\n" +
+		"\n" +
+		"  action_url_id bytea DEFAULT NULL, -- action_url.id\n" +
+		"  notes_url_id bytea DEFAULT NULL, -- notes_url.id\n" +
+		"  notes text NOT NULL,\n" +
+		"  icon_image_id bytea DEFAULT NULL, -- icon_image.id\n" +
+		"  icon_image_alt varchar(32) NOT NULL,\n" +
+		"\n" +
+		"  zone varchar(255) NOT NULL, -- zone.name\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  command_endpoint varchar(255) NOT NULL, -- endpoint.name\n" +
+		"  command_endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"CREATE INDEX idx_action_url_checksum ON host (action_url_id); -- cleanup\n" +
+		"CREATE INDEX idx_notes_url_checksum ON host (notes_url_id); -- cleanup\n" +
+		"CREATE INDEX idx_icon_image_checksum ON host (icon_image_id); -- cleanup\n" +
+		"\n" +
+		"CREATE TABLE hostgroup (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(hostgroup.vars)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE hostgroup_member (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + host_id + hostgroup_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  hostgroup_id bytea NOT NULL, -- hostgroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE host_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + host_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE hostgroup_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + hostgroup_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  hostgroup_id bytea NOT NULL, -- hostgroup.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE host_state (\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"\n" +
+		"  state_type varchar(4) NOT NULL CHECK (state_type IN ('hard', 'soft')),\n" +
+		"  soft_state smallint NOT NULL,\n" +
+		"  hard_state smallint NOT NULL,\n" +
+		"  previous_hard_state smallint NOT NULL,\n" +
+		"  attempt smallint NOT NULL,\n" +
+		"  severity integer NOT NULL,\n" +
+		"\n" +
+		"  output text DEFAULT NULL,\n" +
+		"  long_output text DEFAULT NULL,\n" +
+		"  performance_data text DEFAULT NULL,\n" +
+		"  check_commandline text DEFAULT NULL,\n" +
+		"\n" +
+		"  is_problem varchar(1) NOT NULL CHECK (is_problem IN ('y', 'n')),\n" +
+		"  is_handled varchar(1) NOT NULL CHECK (is_handled IN ('y', 'n')),\n" +
+		"  is_reachable varchar(1) NOT NULL CHECK (is_reachable IN ('y', 'n')),\n" +
+		"  is_flapping varchar(1) NOT NULL CHECK (is_flapping IN ('y', 'n')),\n" +
+		"\n" +
+		"  is_acknowledged varchar(6) NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),\n" +
+		"  acknowledgement_comment_id bytea DEFAULT NULL, -- comment.id\n" +
+		"\n" +
+		"  in_downtime varchar(1) NOT NULL CHECK (in_downtime IN ('y', 'n')),\n" +
+		"\n" +
+		"  execution_time bigint DEFAULT NULL,\n" +
+		"  latency bigint DEFAULT NULL,\n" +
+		"  timeout bigint DEFAULT NULL,\n" +
+		"  check_source text DEFAULT NULL,\n" +
+		"\n" +
+		"  last_update bigint NOT NULL,\n" +
+		"  last_state_change bigint NOT NULL,\n" +
+		"  next_check bigint NOT NULL,\n" +
+		"  next_update bigint NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (host_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(service.vars)\n" +
+		"  groups_checksum bytea NOT NULL, -- sha1(servicegroup.name + servicegroup.name ...)\n" +
+		"  host_id bytea NOT NULL, -- sha1(host.id)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  checkcommand varchar(255) NOT NULL, -- checkcommand.name\n" +
+		"  checkcommand_id bytea NOT NULL, -- checkcommand.id\n" +
+		"\n" +
+		"  max_check_attempts bigint NOT NULL,\n" +
+		"\n" +
+		"  check_timeperiod varchar(255) NOT NULL, -- timeperiod.name\n" +
+		"  check_timeperiod_id bytea DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  check_timeout bigint DEFAULT NULL,\n" +
+		"  check_interval bigint NOT NULL,\n" +
+		"  check_retry_interval bigint NOT NULL,\n" +
+		"\n" +
+		"  active_checks_enabled varchar(1) NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),\n" +
+		"  passive_checks_enabled varchar(1) NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),\n" +
+		"  event_handler_enabled varchar(1) NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),\n" +
+		"  notifications_enabled varchar(1) NOT NULL CHECK (notifications_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  flapping_enabled varchar(1) NOT NULL CHECK (flapping_enabled IN ('y', 'n')),\n" +
+		"  flapping_threshold_low real NOT NULL,\n" +
+		"  flapping_threshold_high real NOT NULL,\n" +
+		"\n" +
+		"  perfdata_enabled varchar(1) NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  eventcommand varchar(255) NOT NULL, -- eventcommand.name\n" +
+		"  eventcommand_id bytea DEFAULT NULL, -- eventcommand.id\n" +
+		"\n" +
+		"  is_volatile varchar(1) NOT NULL CHECK (is_volatile IN ('y', 'n')),\n" +
+		"\n" +
+		"  action_url_id bytea DEFAULT NULL, -- action_url.id\n" +
+		"  notes_url_id bytea DEFAULT NULL, -- notes_url.id\n" +
+		"  notes text NOT NULL,\n" +
+		"  icon_image_id bytea DEFAULT NULL, -- icon_image.id\n" +
+		"  icon_image_alt varchar(32) NOT NULL,\n" +
+		"\n" +
+		"  zone varchar(255) NOT NULL, -- zone.name\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  command_endpoint varchar(255) NOT NULL, -- endpoint.name\n" +
+		"  command_endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE servicegroup (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(servicegroup.vars)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE servicegroup_member (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + servicegroup_id + service_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  service_id bytea NOT NULL, -- service.id\n" +
+		"  servicegroup_id bytea NOT NULL, -- servicegroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + service_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  service_id bytea NOT NULL, -- service.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE servicegroup_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + servicegroup_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  servicegroup_id bytea NOT NULL, -- servicegroup.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service_state (\n" +
+		"  service_id bytea NOT NULL, -- service.id\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"\n" +
+		"  state_type varchar(4) NOT NULL CHECK (state_type IN ('hard', 'soft')),\n" +
+		"  soft_state smallint NOT NULL,\n" +
+		"  hard_state smallint NOT NULL,\n" +
+		"  previous_hard_state smallint NOT NULL,\n" +
+		"  attempt smallint NOT NULL,\n" +
+		"  severity integer NOT NULL,\n" +
+		"\n" +
+		"  output text DEFAULT NULL,\n" +
+		"  long_output text DEFAULT NULL,\n" +
+		"  performance_data text DEFAULT NULL,\n" +
+		"  check_commandline text DEFAULT NULL,\n" +
+		"\n" +
+		"  is_problem varchar(1) NOT NULL CHECK (is_problem IN ('y', 'n')),\n" +
+		"  is_handled varchar(1) NOT NULL CHECK (is_handled IN ('y', 'n')),\n" +
+		"  is_reachable varchar(1) NOT NULL CHECK (is_reachable IN ('y', 'n')),\n" +
+		"  is_flapping varchar(1) NOT NULL CHECK (is_flapping IN ('y', 'n')),\n" +
+		"\n" +
+		"  is_acknowledged varchar(6) NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),\n" +
+		"  acknowledgement_comment_id bytea DEFAULT NULL, -- comment.id\n" +
+		"\n" +
+		"  in_downtime varchar(1) NOT NULL CHECK (in_downtime IN ('y', 'n')),\n" +
+		"\n" +
+		"  execution_time bigint DEFAULT NULL,\n" +
+		"  latency bigint DEFAULT NULL,\n" +
+		"  timeout bigint DEFAULT NULL,\n" +
+		"  check_source text DEFAULT NULL,\n" +
+		"\n" +
+		"  last_update bigint NOT NULL,\n" +
+		"  last_state_change bigint NOT NULL,\n" +
+		"  next_check bigint NOT NULL,\n" +
+		"  next_update bigint NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (service_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE endpoint (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL,\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  zone_id bytea NOT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE environment (\n" +
+		"  id bytea NOT NULL, -- sha1(name)\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE icingadb_instance (\n" +
+		"  id bytea NOT NULL, -- UUIDv4\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  heartbeat bigint NOT NULL, -- *nix timestamp\n" +
+		"  responsible varchar(1) NOT NULL CHECK (responsible IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + type + name)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  command text NOT NULL,\n" +
+		"  timeout bigint NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_argument (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + argument_key)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  argument_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  argument_value text DEFAULT NULL,\n" +
+		"  argument_order smallint DEFAULT NULL,\n" +
+		"  description text DEFAULT NULL,\n" +
+		"  argument_key_override varchar(64) DEFAULT NULL,\n" +
+		"  repeat_key varchar(1) NOT NULL CHECK (repeat_key IN ('y', 'n')),\n" +
+		"  required varchar(1) NOT NULL CHECK (required IN ('y', 'n')),\n" +
+		"  set_if varchar(255) DEFAULT NULL,\n" +
+		"  skip_key varchar(1) NOT NULL CHECK (skip_key IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_envvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + envvar_key)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  envvar_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  envvar_value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + type + name)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  command text NOT NULL,\n" +
+		"  timeout integer NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_argument (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + argument_key)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  argument_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  argument_value text DEFAULT NULL,\n" +
+		"  argument_order smallint DEFAULT NULL,\n" +
+		"  description text DEFAULT NULL,\n" +
+		"  argument_key_override varchar(64) DEFAULT NULL,\n" +
+		"  repeat_key varchar(1) NOT NULL CHECK (repeat_key IN ('y', 'n')),\n" +
+		"  required varchar(1) NOT NULL CHECK (required IN ('y', 'n')),\n" +
+		"  set_if varchar(255) DEFAULT NULL,\n" +
+		"  skip_key varchar(1) NOT NULL CHECK (skip_key IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_envvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + envvar_key)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  envvar_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  envvar_value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + type + name)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  command text NOT NULL,\n" +
+		"  timeout integer NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_argument (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + argument_key)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  argument_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  argument_value text DEFAULT NULL,\n" +
+		"  argument_order smallint DEFAULT NULL,\n" +
+		"  description text DEFAULT NULL,\n" +
+		"  argument_key_override varchar(64) DEFAULT NULL,\n" +
+		"  repeat_key varchar(1) NOT NULL CHECK (repeat_key IN ('y', 'n')),\n" +
+		"  required varchar(1) NOT NULL CHECK (required IN ('y', 'n')),\n" +
+		"  set_if varchar(255) DEFAULT NULL,\n" +
+		"  skip_key varchar(1) NOT NULL CHECK (skip_key IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_envvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + envvar_key)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  envvar_key varchar(64) NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  envvar_value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + command_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE comment (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea DEFAULT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL,\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  author varchar(255) NOT NULL,\n" +
+		"  \"text\" text NOT NULL,\n" +
+		"  entry_type varchar(7) NOT NULL CHECK (entry_type IN ('comment', 'ack')),\n" +
+		"  entry_time bigint NOT NULL,\n" +
+		"  is_persistent varchar(1) NOT NULL CHECK (is_persistent IN ('y', 'n')),\n" +
+		"  is_sticky varchar(1) NOT NULL CHECK (is_sticky IN ('y', 'n')),\n" +
+		"  expire_time bigint DEFAULT NULL,\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE downtime (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"\n" +
+		"  triggered_by_id bytea DEFAULT NULL, -- downtime.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea DEFAULT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  author varchar(255) NOT NULL,\n" +
+		"  comment text NOT NULL,\n" +
+		"  entry_time bigint NOT NULL,\n" +
+		"  scheduled_start_time bigint NOT NULL,\n" +
+		"  scheduled_end_time bigint NOT NULL,\n" +
+		"  flexible_duration bigint NOT NULL,\n" +
+		"  is_flexible varchar(1) NOT NULL CHECK (is_flexible IN ('y', 'n')),\n" +
+		"\n" +
+		"  is_in_effect varchar(1) NOT NULL CHECK (is_in_effect IN ('y', 'n')),\n" +
+		"  start_time bigint DEFAULT NULL, -- Time when the host went into a problem state during the downtimes timeframe\n" +
+		"  end_time bigint DEFAULT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + flexible_duration otherwise\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL,\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(notification.vars)\n" +
+		"  users_checksum bytea NOT NULL,\n" +
+		"  usergroups_checksum bytea NOT NULL,\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"  command_id bytea NOT NULL, -- command.id\n" +
+		"\n" +
+		"  times_begin bigint DEFAULT NULL,\n" +
+		"  times_end bigint DEFAULT NULL,\n" +
+		"  notification_interval bigint NOT NULL,\n" +
+		"  timeperiod_id bytea DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  states smallint NOT NULL,\n" +
+		"  types integer NOT NULL,\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification_user (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + notification_id + user_id)\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  notification_id bytea NOT NULL, -- notification.id\n" +
+		"  user_id bytea NOT NULL, -- user.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification_usergroup (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + notification_id + usergroup_id)\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  notification_id bytea NOT NULL, -- notification.id\n" +
+		"  usergroup_id bytea NOT NULL, -- usergroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + notification_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  notification_id bytea NOT NULL, -- notification.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE icon_image (\n" +
+		"  id bytea NOT NULL, -- sha1(icon_image)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  icon_image text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id)\n" +
+		");\n" +
+		"CREATE INDEX idx_icon_image ON icon_image USING hash (icon_image);\n" +
+		"\n" +
+		"CREATE TABLE action_url (\n" +
+		"  id bytea NOT NULL, -- sha1(action_url)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  action_url text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id)\n" +
+		");\n" +
+		"CREATE INDEX idx_action_url ON action_url USING hash (action_url);\n" +
+		"\n" +
+		"CREATE TABLE notes_url (\n" +
+		"  id bytea NOT NULL, -- sha1(notes_url)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  notes_url text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id)\n" +
+		");\n" +
+		"CREATE INDEX idx_notes_url ON notes_url USING hash (notes_url);\n" +
+		"\n" +
+		"CREATE TABLE timeperiod (\n" +
+		"  id bytea NOT NULL, -- sha1(env.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"  prefer_includes varchar(1) NOT NULL CHECK (prefer_includes IN ('y', 'n')),\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_range (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + range_id + timeperiod_id)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  timeperiod_id bytea NOT NULL, -- timeperiod.id\n" +
+		"  range_key varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  range_value varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_override_include (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + include_id + timeperiod_id)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  timeperiod_id bytea NOT NULL, -- timeperiod.id\n" +
+		"  override_id bytea NOT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_override_exclude (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + exclude_id + timeperiod_id)\n" +
+		"  environment_id bytea NOT NULL, -- env.id\n" +
+		"  timeperiod_id bytea NOT NULL, -- timeperiod.id\n" +
+		"  override_id bytea NOT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + timeperiod_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  timeperiod_id bytea NOT NULL, -- timeperiod.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name + value)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  value text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE customvar_flat (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + flatname + flatvalue)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  customvar_id bytea NOT NULL, -- sha1(customvar.id)\n" +
+		"  flatname_checksum bytea NOT NULL, -- sha1(flatname after conversion)\n" +
+		"\n" +
+		"  flatname varchar(512) NOT NULL, -- Path converted with `.` and `[ ]`\n" +
+		"  flatvalue text NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE \"user\" (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(user.vars)\n" +
+		"  groups_checksum bytea NOT NULL, -- sha1(usergroup.name + userroup.name ...)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  email varchar(255) NOT NULL,\n" +
+		"  pager varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  notifications_enabled varchar(1) NOT NULL CHECK (notifications_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  timeperiod_id bytea DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  states smallint NOT NULL,\n" +
+		"  types integer NOT NULL,\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE usergroup (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum bytea NOT NULL, -- sha1(usergroup.vars)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"  display_name varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  zone_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE usergroup_member (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + usergroup_id + user_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  user_id bytea NOT NULL, -- user.id\n" +
+		"  usergroup_id bytea NOT NULL, -- usergroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE user_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + user_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  user_id bytea NOT NULL, -- user.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE usergroup_customvar (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + usergroup_id + customvar_id)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  usergroup_id bytea NOT NULL, -- usergroup.id\n" +
+		"  customvar_id bytea NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE zone (\n" +
+		"  id bytea NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum bytea NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum bytea NOT NULL, -- sha1(all properties)\n" +
+		"  parents_checksum bytea NOT NULL, -- sha1(all parents checksums)\n" +
+		"\n" +
+		"  name varchar(255) NOT NULL,\n" +
+		"  name_ci varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  is_global varchar(1) NOT NULL CHECK (is_global IN ('y', 'n')),\n" +
+		"  parent_id bytea DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  depth smallint NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"CREATE INDEX idx_parent_id ON zone (parent_id);\n" +
+		"CREATE UNIQUE INDEX idx_environment_id_id ON zone (environment_id,id);\n" +
+		"\n" +
+		"CREATE TABLE notification_history (\n" +
+		"  id bytea NOT NULL, -- UUID\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"  notification_id bytea NOT NULL, -- notification.id\n" +
+		"\n" +
+		"  type varchar(16) NOT NULL CHECK (type IN ('downtime_start', 'downtime_end', 'downtime_removed', 'custom', 'acknowledgement', 'problem', 'recovery', 'flapping_start', 'flapping_end')),\n" +
+		"  send_time bigint NOT NULL,\n" +
+		"  state smallint NOT NULL,\n" +
+		"  previous_hard_state smallint NOT NULL,\n" +
+		"  author text NOT NULL,\n" +
+		"  \"text\" text NOT NULL,\n" +
+		"  users_notified integer NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE user_notification_history (\n" +
+		"  id bytea NOT NULL, -- UUID\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  notification_history_id bytea NOT NULL, -- UUID notification_history.id\n" +
+		"  user_id bytea NOT NULL, -- user.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE state_history (\n" +
+		"  id bytea NOT NULL, -- UUID\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  event_time bigint NOT NULL,\n" +
+		"  state_type varchar(4) NOT NULL CHECK (state_type IN ('hard', 'soft')),\n" +
+		"  soft_state smallint NOT NULL,\n" +
+		"  hard_state smallint NOT NULL,\n" +
+		"  previous_soft_state smallint NOT NULL,\n" +
+		"  previous_hard_state smallint NOT NULL,\n" +
+		"  attempt smallint NOT NULL,\n" +
+		"  output text DEFAULT NULL,\n" +
+		"  long_output text DEFAULT NULL,\n" +
+		"  max_check_attempts bigint NOT NULL,\n" +
+		"  check_source text DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE downtime_history (\n" +
+		"  downtime_id bytea NOT NULL, -- downtime.id\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"  triggered_by_id bytea DEFAULT NULL, -- downtime.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  entry_time bigint NOT NULL,\n" +
+		"  author varchar(255) NOT NULL,\n" +
+		"  comment text NOT NULL,\n" +
+		"  is_flexible varchar(1) NOT NULL CHECK (is_flexible IN ('y', 'n')),\n" +
+		"  flexible_duration bigint NOT NULL,\n" +
+		"  scheduled_start_time bigint NOT NULL,\n" +
+		"  scheduled_end_time bigint NOT NULL,\n" +
+		"  start_time bigint NOT NULL, -- Time when the host went into a problem state during the downtimes timeframe\n" +
+		"  end_time bigint NOT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + duration otherwise\n" +
+		"  has_been_cancelled varchar(1) NOT NULL CHECK (has_been_cancelled IN ('y', 'n')),\n" +
+		"  trigger_time bigint NOT NULL,\n" +
+		"  cancel_time bigint DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (downtime_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE comment_history (\n" +
+		"  comment_id bytea NOT NULL, -- comment.id\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  entry_time bigint NOT NULL,\n" +
+		"  author varchar(255) NOT NULL,\n" +
+		"  comment text NOT NULL,\n" +
+		"  entry_type varchar(7) NOT NULL CHECK (entry_type IN ('comment', 'ack')),\n" +
+		"  is_persistent varchar(1) NOT NULL CHECK (is_persistent IN ('y', 'n')),\n" +
+		"  is_sticky varchar(1) NOT NULL CHECK (is_sticky IN ('y', 'n')),\n" +
+		"  expire_time bigint DEFAULT NULL,\n" +
+		"  remove_time bigint DEFAULT NULL,\n" +
+		"  has_been_removed varchar(1) NOT NULL CHECK (has_been_removed IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (comment_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE flapping_history (\n" +
+		"  id bytea NOT NULL, -- UUID\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  event_time bigint NOT NULL,\n" +
+		"  percent_state_change real NOT NULL,\n" +
+		"  flapping_threshold_low real NOT NULL,\n" +
+		"  flapping_threshold_high real NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE history (\n" +
+		"  id bytea NOT NULL, -- notification_history_id, state_history_id, flapping_history_id or UUID\n" +
+		"  environment_id bytea NOT NULL, -- environment.id\n" +
+		"  endpoint_id bytea DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type varchar(7) NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  service_id bytea DEFAULT NULL, -- service.id\n" +
+		"  notification_history_id bytea DEFAULT NULL, -- notification_history.id\n" +
+		"  state_history_id bytea DEFAULT NULL, -- state_history.id\n" +
+		"  downtime_history_id bytea DEFAULT NULL, -- downtime_history.downtime_id\n" +
+		"  comment_history_id bytea DEFAULT NULL, -- comment_history.comment_id\n" +
+		"  flapping_history_id bytea DEFAULT NULL, -- flapping_history.id\n" +
+		"\n" +
+		"  event_type varchar(17) NOT NULL CHECK (event_type IN ('notification', 'state_change', 'downtime_schedule', 'downtime_start', 'downtime_end', 'comment_add', 'comment_remove', 'flapping_start', 'flapping_end')),\n" +
+		"  event_time bigint NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE icingadb_schema (\n" +
+		"  id serial NOT NULL,\n" +
+		"  version integer NOT NULL,\n" +
+		"  timestamp bigint NOT NULL, -- *nix timestamp in milliseconds\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));\n",
+	"pgsql/upgrades/1.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Starts tracking the schema version of databases set up before icingadb_schema existed.\n" +
+		"\n" +
+		"CREATE TABLE icingadb_schema (\n" +
+		"  id serial NOT NULL,\n" +
+		"  version integer NOT NULL,\n" +
+		"  timestamp bigint NOT NULL, -- *nix timestamp in milliseconds\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));\n",
+	"pgsql/upgrades/2.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Adds the fencing token to icingadb_instance and allows only one row per environment, so that a takeover can be\n" +
+		"-- done as compare-and-swap. The rows are recreated by the running instances with their next heartbeat.\n" +
+		"\n" +
+		"DELETE FROM icingadb_instance;\n" +
+		"\n" +
+		"ALTER TABLE icingadb_instance ADD COLUMN fencing_token bigint NOT NULL DEFAULT 0; -- incremented on every takeover\n" +
+		"\n" +
+		"CREATE UNIQUE INDEX idx_icingadb_instance_environment_id ON icingadb_instance (environment_id);\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (2, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));\n",
+	"pgsql/upgrades/3.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Adds the tables with the parsed performance data of the current host and service states, one row per label.\n" +
+		"\n" +
+		"CREATE TABLE host_perfdata (\n" +
+		"  host_id bytea NOT NULL, -- host.id\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  label varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  value double precision DEFAULT NULL, -- NULL if unknown (U)\n" +
+		"  unit varchar(255) NOT NULL,\n" +
+		"  warn varchar(255) DEFAULT NULL, -- threshold range\n" +
+		"  crit varchar(255) DEFAULT NULL, -- threshold range\n" +
+		"  min double precision DEFAULT NULL,\n" +
+		"  max double precision DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (host_id, label)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service_perfdata (\n" +
+		"  service_id bytea NOT NULL, -- service.id\n" +
+		"  environment_id bytea NOT NULL, -- sha1(environment.name)\n" +
+		"  label varchar(255) NOT NULL,\n" +
+		"\n" +
+		"  value double precision DEFAULT NULL, -- NULL if unknown (U)\n" +
+		"  unit varchar(255) NOT NULL,\n" +
+		"  warn varchar(255) DEFAULT NULL, -- threshold range\n" +
+		"  crit varchar(255) DEFAULT NULL, -- threshold range\n" +
+		"  min double precision DEFAULT NULL,\n" +
+		"  max double precision DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (service_id, label)\n" +
+		");\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (3, CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS bigint));\n",
+	"sqlite/sqlite.schema.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"\n" +
+		"CREATE TABLE host (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(host.vars)\n" +
+		"  groups_checksum BLOB NOT NULL, -- sha1(hostgroup.name + hostgroup.name ...)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"\n" +
+		"  address TEXT NOT NULL,\n" +
+		"  address6 TEXT NOT NULL,\n" +
+		"  address_bin BLOB DEFAULT NULL,\n" +
+		"  address6_bin BLOB DEFAULT NULL,\n" +
+		"\n" +
+		"  checkcommand TEXT NOT NULL, -- checkcommand.name\n" +
+		"  checkcommand_id BLOB NOT NULL, -- checkcommand.id\n" +
+		"\n" +
+		"  max_check_attempts INTEGER NOT NULL,\n" +
+		"\n" +
+		"  check_timeperiod TEXT NOT NULL, -- timeperiod.name\n" +
+		"  check_timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  check_timeout INTEGER DEFAULT NULL,\n" +
+		"  check_interval INTEGER NOT NULL,\n" +
+		"  check_retry_interval INTEGER NOT NULL,\n" +
+		"\n" +
+		"  active_checks_enabled TEXT NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),\n" +
+		"  passive_checks_enabled TEXT NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),\n" +
+		"  event_handler_enabled TEXT NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),\n" +
+		"  notifications_enabled TEXT NOT NULL CHECK (notifications_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  flapping_enabled TEXT NOT NULL CHECK (flapping_enabled IN ('y', 'n')),\n" +
+		"  flapping_threshold_low REAL NOT NULL,\n" +
+		"  flapping_threshold_high REAL NOT NULL,\n" +
+		"\n" +
+		"  perfdata_enabled TEXT NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  eventcommand TEXT NOT NULL, -- eventcommand.name\n" +
+		"  eventcommand_id BLOB DEFAULT NULL, -- eventcommand.id\n" +
+		"\n" +
+		"  is_volatile TEXT NOT NULL CHECK (is_volatile IN ('y', 'n')),\n" +
+		"\n" +
+		"  action_url_id BLOB DEFAULT NULL, -- action_url.id\n" +
+		"  notes_url_id BLOB DEFAULT NULL, -- notes_url.id\n" +
+		"  notes TEXT NOT NULL,\n" +
+		"  icon_image_id BLOB DEFAULT NULL, -- icon_image.id\n" +
+		"  icon_image_alt TEXT NOT NULL,\n" +
+		"\n" +
+		"  zone TEXT NOT NULL, -- zone.name\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  command_endpoint TEXT NOT NULL, -- endpoint.name\n" +
+		"  command_endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"CREATE INDEX idx_action_url_checksum ON host (action_url_id); -- cleanup\n" +
+		"CREATE INDEX idx_notes_url_checksum ON host (notes_url_id); -- cleanup\n" +
+		"CREATE INDEX idx_icon_image_checksum ON host (icon_image_id); -- cleanup\n" +
+		"\n" +
+		"CREATE TABLE hostgroup (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(hostgroup.vars)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE hostgroup_member (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + host_id + hostgroup_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  hostgroup_id BLOB NOT NULL, -- hostgroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE host_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + host_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE hostgroup_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + hostgroup_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  hostgroup_id BLOB NOT NULL, -- hostgroup.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE host_state (\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"\n" +
+		"  state_type TEXT NOT NULL CHECK (state_type IN ('hard', 'soft')),\n" +
+		"  soft_state INTEGER NOT NULL,\n" +
+		"  hard_state INTEGER NOT NULL,\n" +
+		"  previous_hard_state INTEGER NOT NULL,\n" +
+		"  attempt INTEGER NOT NULL,\n" +
+		"  severity INTEGER NOT NULL,\n" +
+		"\n" +
+		"  output TEXT DEFAULT NULL,\n" +
+		"  long_output TEXT DEFAULT NULL,\n" +
+		"  performance_data TEXT DEFAULT NULL,\n" +
+		"  check_commandline TEXT DEFAULT NULL,\n" +
+		"\n" +
+		"  is_problem TEXT NOT NULL CHECK (is_problem IN ('y', 'n')),\n" +
+		"  is_handled TEXT NOT NULL CHECK (is_handled IN ('y', 'n')),\n" +
+		"  is_reachable TEXT NOT NULL CHECK (is_reachable IN ('y', 'n')),\n" +
+		"  is_flapping TEXT NOT NULL CHECK (is_flapping IN ('y', 'n')),\n" +
+		"\n" +
+		"  is_acknowledged TEXT NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),\n" +
+		"  acknowledgement_comment_id BLOB DEFAULT NULL, -- comment.id\n" +
+		"\n" +
+		"  in_downtime TEXT NOT NULL CHECK (in_downtime IN ('y', 'n')),\n" +
+		"\n" +
+		"  execution_time INTEGER DEFAULT NULL,\n" +
+		"  latency INTEGER DEFAULT NULL,\n" +
+		"  timeout INTEGER DEFAULT NULL,\n" +
+		"  check_source TEXT DEFAULT NULL,\n" +
+		"\n" +
+		"  last_update INTEGER NOT NULL,\n" +
+		"  last_state_change INTEGER NOT NULL,\n" +
+		"  next_check INTEGER NOT NULL,\n" +
+		"  next_update INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (host_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(service.vars)\n" +
+		"  groups_checksum BLOB NOT NULL, -- sha1(servicegroup.name + servicegroup.name ...)\n" +
+		"  host_id BLOB NOT NULL, -- sha1(host.id)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"\n" +
+		"  checkcommand TEXT NOT NULL, -- checkcommand.name\n" +
+		"  checkcommand_id BLOB NOT NULL, -- checkcommand.id\n" +
+		"\n" +
+		"  max_check_attempts INTEGER NOT NULL,\n" +
+		"\n" +
+		"  check_timeperiod TEXT NOT NULL, -- timeperiod.name\n" +
+		"  check_timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  check_timeout INTEGER DEFAULT NULL,\n" +
+		"  check_interval INTEGER NOT NULL,\n" +
+		"  check_retry_interval INTEGER NOT NULL,\n" +
+		"\n" +
+		"  active_checks_enabled TEXT NOT NULL CHECK (active_checks_enabled IN ('y', 'n')),\n" +
+		"  passive_checks_enabled TEXT NOT NULL CHECK (passive_checks_enabled IN ('y', 'n')),\n" +
+		"  event_handler_enabled TEXT NOT NULL CHECK (event_handler_enabled IN ('y', 'n')),\n" +
+		"  notifications_enabled TEXT NOT NULL CHECK (notifications_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  flapping_enabled TEXT NOT NULL CHECK (flapping_enabled IN ('y', 'n')),\n" +
+		"  flapping_threshold_low REAL NOT NULL,\n" +
+		"  flapping_threshold_high REAL NOT NULL,\n" +
+		"\n" +
+		"  perfdata_enabled TEXT NOT NULL CHECK (perfdata_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  eventcommand TEXT NOT NULL, -- eventcommand.name\n" +
+		"  eventcommand_id BLOB DEFAULT NULL, -- eventcommand.id\n" +
+		"\n" +
+		"  is_volatile TEXT NOT NULL CHECK (is_volatile IN ('y', 'n')),\n" +
+		"\n" +
+		"  action_url_id BLOB DEFAULT NULL, -- action_url.id\n" +
+		"  notes_url_id BLOB DEFAULT NULL, -- notes_url.id\n" +
+		"  notes TEXT NOT NULL,\n" +
+		"  icon_image_id BLOB DEFAULT NULL, -- icon_image.id\n" +
+		"  icon_image_alt TEXT NOT NULL,\n" +
+		"\n" +
+		"  zone TEXT NOT NULL, -- zone.name\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  command_endpoint TEXT NOT NULL, -- endpoint.name\n" +
+		"  command_endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE servicegroup (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(servicegroup.vars)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE servicegroup_member (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + servicegroup_id + service_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  service_id BLOB NOT NULL, -- service.id\n" +
+		"  servicegroup_id BLOB NOT NULL, -- servicegroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + service_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  service_id BLOB NOT NULL, -- service.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE servicegroup_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + servicegroup_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  servicegroup_id BLOB NOT NULL, -- servicegroup.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service_state (\n" +
+		"  service_id BLOB NOT NULL, -- service.id\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"\n" +
+		"  state_type TEXT NOT NULL CHECK (state_type IN ('hard', 'soft')),\n" +
+		"  soft_state INTEGER NOT NULL,\n" +
+		"  hard_state INTEGER NOT NULL,\n" +
+		"  previous_hard_state INTEGER NOT NULL,\n" +
+		"  attempt INTEGER NOT NULL,\n" +
+		"  severity INTEGER NOT NULL,\n" +
+		"\n" +
+		"  output TEXT DEFAULT NULL,\n" +
+		"  long_output TEXT DEFAULT NULL,\n" +
+		"  performance_data TEXT DEFAULT NULL,\n" +
+		"  check_commandline TEXT DEFAULT NULL,\n" +
+		"\n" +
+		"  is_problem TEXT NOT NULL CHECK (is_problem IN ('y', 'n')),\n" +
+		"  is_handled TEXT NOT NULL CHECK (is_handled IN ('y', 'n')),\n" +
+		"  is_reachable TEXT NOT NULL CHECK (is_reachable IN ('y', 'n')),\n" +
+		"  is_flapping TEXT NOT NULL CHECK (is_flapping IN ('y', 'n')),\n" +
+		"\n" +
+		"  is_acknowledged TEXT NOT NULL CHECK (is_acknowledged IN ('y', 'n', 'sticky')),\n" +
+		"  acknowledgement_comment_id BLOB DEFAULT NULL, -- comment.id\n" +
+		"\n" +
+		"  in_downtime TEXT NOT NULL CHECK (in_downtime IN ('y', 'n')),\n" +
+		"\n" +
+		"  execution_time INTEGER DEFAULT NULL,\n" +
+		"  latency INTEGER DEFAULT NULL,\n" +
+		"  timeout INTEGER DEFAULT NULL,\n" +
+		"  check_source TEXT DEFAULT NULL,\n" +
+		"\n" +
+		"  last_update INTEGER NOT NULL,\n" +
+		"  last_state_change INTEGER NOT NULL,\n" +
+		"  next_check INTEGER NOT NULL,\n" +
+		"  next_update INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (service_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE endpoint (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL,\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB NOT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE environment (\n" +
+		"  id BLOB NOT NULL, -- sha1(name)\n" +
+		"  name TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE icingadb_instance (\n" +
+		"  id BLOB NOT NULL, -- UUIDv4\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  heartbeat INTEGER NOT NULL, -- *nix timestamp\n" +
+		"  responsible TEXT NOT NULL CHECK (responsible IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + type + name)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  command TEXT NOT NULL,\n" +
+		"  timeout INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_argument (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + argument_key)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  argument_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  argument_value TEXT DEFAULT NULL,\n" +
+		"  argument_order INTEGER DEFAULT NULL,\n" +
+		"  description TEXT DEFAULT NULL,\n" +
+		"  argument_key_override TEXT DEFAULT NULL,\n" +
+		"  repeat_key TEXT NOT NULL CHECK (repeat_key IN ('y', 'n')),\n" +
+		"  required TEXT NOT NULL CHECK (required IN ('y', 'n')),\n" +
+		"  set_if TEXT DEFAULT NULL,\n" +
+		"  skip_key TEXT NOT NULL CHECK (skip_key IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_envvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + envvar_key)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  envvar_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  envvar_value TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE checkcommand_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + type + name)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  command TEXT NOT NULL,\n" +
+		"  timeout INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_argument (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + argument_key)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  argument_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  argument_value TEXT DEFAULT NULL,\n" +
+		"  argument_order INTEGER DEFAULT NULL,\n" +
+		"  description TEXT DEFAULT NULL,\n" +
+		"  argument_key_override TEXT DEFAULT NULL,\n" +
+		"  repeat_key TEXT NOT NULL CHECK (repeat_key IN ('y', 'n')),\n" +
+		"  required TEXT NOT NULL CHECK (required IN ('y', 'n')),\n" +
+		"  set_if TEXT DEFAULT NULL,\n" +
+		"  skip_key TEXT NOT NULL CHECK (skip_key IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_envvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + envvar_key)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  envvar_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  envvar_value TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE eventcommand_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + type + name)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  command TEXT NOT NULL,\n" +
+		"  timeout INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_argument (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + argument_key)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  argument_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  argument_value TEXT DEFAULT NULL,\n" +
+		"  argument_order INTEGER DEFAULT NULL,\n" +
+		"  description TEXT DEFAULT NULL,\n" +
+		"  argument_key_override TEXT DEFAULT NULL,\n" +
+		"  repeat_key TEXT NOT NULL CHECK (repeat_key IN ('y', 'n')),\n" +
+		"  required TEXT NOT NULL CHECK (required IN ('y', 'n')),\n" +
+		"  set_if TEXT DEFAULT NULL,\n" +
+		"  skip_key TEXT NOT NULL CHECK (skip_key IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_envvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + envvar_key)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  envvar_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  envvar_value TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notificationcommand_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + command_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE comment (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB DEFAULT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL,\n" +
+		"  name TEXT NOT NULL,\n" +
+		"\n" +
+		"  author TEXT NOT NULL,\n" +
+		"  \"text\" TEXT NOT NULL,\n" +
+		"  entry_type TEXT NOT NULL CHECK (entry_type IN ('comment', 'ack')),\n" +
+		"  entry_time INTEGER NOT NULL,\n" +
+		"  is_persistent TEXT NOT NULL CHECK (is_persistent IN ('y', 'n')),\n" +
+		"  is_sticky TEXT NOT NULL CHECK (is_sticky IN ('y', 'n')),\n" +
+		"  expire_time INTEGER DEFAULT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE downtime (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"\n" +
+		"  triggered_by_id BLOB DEFAULT NULL, -- downtime.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB DEFAULT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  name TEXT NOT NULL,\n" +
+		"\n" +
+		"  author TEXT NOT NULL,\n" +
+		"  comment TEXT NOT NULL,\n" +
+		"  entry_time INTEGER NOT NULL,\n" +
+		"  scheduled_start_time INTEGER NOT NULL,\n" +
+		"  scheduled_end_time INTEGER NOT NULL,\n" +
+		"  flexible_duration INTEGER NOT NULL,\n" +
+		"  is_flexible TEXT NOT NULL CHECK (is_flexible IN ('y', 'n')),\n" +
+		"\n" +
+		"  is_in_effect TEXT NOT NULL CHECK (is_in_effect IN ('y', 'n')),\n" +
+		"  start_time INTEGER DEFAULT NULL, -- Time when the host went into a problem state during the downtimes timeframe\n" +
+		"  end_time INTEGER DEFAULT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + flexible_duration otherwise\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL,\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(notification.vars)\n" +
+		"  users_checksum BLOB NOT NULL,\n" +
+		"  usergroups_checksum BLOB NOT NULL,\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"  command_id BLOB NOT NULL, -- command.id\n" +
+		"\n" +
+		"  times_begin INTEGER DEFAULT NULL,\n" +
+		"  times_end INTEGER DEFAULT NULL,\n" +
+		"  notification_interval INTEGER NOT NULL,\n" +
+		"  timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  states INTEGER NOT NULL,\n" +
+		"  types INTEGER NOT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification_user (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + notification_id + user_id)\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  notification_id BLOB NOT NULL, -- notification.id\n" +
+		"  user_id BLOB NOT NULL, -- user.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification_usergroup (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + notification_id + usergroup_id)\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  notification_id BLOB NOT NULL, -- notification.id\n" +
+		"  usergroup_id BLOB NOT NULL, -- usergroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE notification_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + notification_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  notification_id BLOB NOT NULL, -- notification.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE icon_image (\n" +
+		"  id BLOB NOT NULL, -- sha1(icon_image)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  icon_image TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id)\n" +
+		");\n" +
+		"CREATE INDEX idx_icon_image ON icon_image (icon_image);\n" +
+		"\n" +
+		"CREATE TABLE action_url (\n" +
+		"  id BLOB NOT NULL, -- sha1(action_url)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  action_url TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id)\n" +
+		");\n" +
+		"CREATE INDEX idx_action_url ON action_url (action_url);\n" +
+		"\n" +
+		"CREATE TABLE notes_url (\n" +
+		"  id BLOB NOT NULL, -- sha1(notes_url)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  notes_url TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (environment_id, id)\n" +
+		");\n" +
+		"CREATE INDEX idx_notes_url ON notes_url (notes_url);\n" +
+		"\n" +
+		"CREATE TABLE timeperiod (\n" +
+		"  id BLOB NOT NULL, -- sha1(env.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"  prefer_includes TEXT NOT NULL CHECK (prefer_includes IN ('y', 'n')),\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_range (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + range_id + timeperiod_id)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  timeperiod_id BLOB NOT NULL, -- timeperiod.id\n" +
+		"  range_key TEXT NOT NULL,\n" +
+		"\n" +
+		"  range_value TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_override_include (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + include_id + timeperiod_id)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  timeperiod_id BLOB NOT NULL, -- timeperiod.id\n" +
+		"  override_id BLOB NOT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_override_exclude (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + exclude_id + timeperiod_id)\n" +
+		"  environment_id BLOB NOT NULL, -- env.id\n" +
+		"  timeperiod_id BLOB NOT NULL, -- timeperiod.id\n" +
+		"  override_id BLOB NOT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE timeperiod_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + timeperiod_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  timeperiod_id BLOB NOT NULL, -- timeperiod.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name + value)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  value TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE customvar_flat (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + flatname + flatvalue)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  customvar_id BLOB NOT NULL, -- sha1(customvar.id)\n" +
+		"  flatname_checksum BLOB NOT NULL, -- sha1(flatname after conversion)\n" +
+		"\n" +
+		"  flatname TEXT NOT NULL, -- Path converted with `.` and `[ ]`\n" +
+		"  flatvalue TEXT NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE \"user\" (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(user.vars)\n" +
+		"  groups_checksum BLOB NOT NULL, -- sha1(usergroup.name + userroup.name ...)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"\n" +
+		"  email TEXT NOT NULL,\n" +
+		"  pager TEXT NOT NULL,\n" +
+		"\n" +
+		"  notifications_enabled TEXT NOT NULL CHECK (notifications_enabled IN ('y', 'n')),\n" +
+		"\n" +
+		"  timeperiod_id BLOB DEFAULT NULL, -- timeperiod.id\n" +
+		"\n" +
+		"  states INTEGER NOT NULL,\n" +
+		"  types INTEGER NOT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE usergroup (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  customvars_checksum BLOB NOT NULL, -- sha1(usergroup.vars)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"  display_name TEXT NOT NULL,\n" +
+		"\n" +
+		"  zone_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE usergroup_member (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + usergroup_id + user_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  user_id BLOB NOT NULL, -- user.id\n" +
+		"  usergroup_id BLOB NOT NULL, -- usergroup.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE user_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + user_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  user_id BLOB NOT NULL, -- user.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE usergroup_customvar (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + usergroup_id + customvar_id)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  usergroup_id BLOB NOT NULL, -- usergroup.id\n" +
+		"  customvar_id BLOB NOT NULL, -- customvar.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE zone (\n" +
+		"  id BLOB NOT NULL, -- sha1(environment.name + name)\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  name_checksum BLOB NOT NULL, -- sha1(name)\n" +
+		"  properties_checksum BLOB NOT NULL, -- sha1(all properties)\n" +
+		"  parents_checksum BLOB NOT NULL, -- sha1(all parents checksums)\n" +
+		"\n" +
+		"  name TEXT NOT NULL,\n" +
+		"  name_ci TEXT NOT NULL,\n" +
+		"\n" +
+		"  is_global TEXT NOT NULL CHECK (is_global IN ('y', 'n')),\n" +
+		"  parent_id BLOB DEFAULT NULL, -- zone.id\n" +
+		"\n" +
+		"  depth INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"CREATE INDEX idx_parent_id ON zone (parent_id);\n" +
+		"CREATE UNIQUE INDEX idx_environment_id_id ON zone (environment_id,id);\n" +
+		"\n" +
+		"CREATE TABLE notification_history (\n" +
+		"  id BLOB NOT NULL, -- UUID\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"  notification_id BLOB NOT NULL, -- notification.id\n" +
+		"\n" +
+		"  type TEXT NOT NULL CHECK (type IN ('downtime_start', 'downtime_end', 'downtime_removed', 'custom', 'acknowledgement', 'problem', 'recovery', 'flapping_start', 'flapping_end')),\n" +
+		"  send_time INTEGER NOT NULL,\n" +
+		"  state INTEGER NOT NULL,\n" +
+		"  previous_hard_state INTEGER NOT NULL,\n" +
+		"  author TEXT NOT NULL,\n" +
+		"  \"text\" TEXT NOT NULL,\n" +
+		"  users_notified INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE user_notification_history (\n" +
+		"  id BLOB NOT NULL, -- UUID\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  notification_history_id BLOB NOT NULL, -- UUID notification_history.id\n" +
+		"  user_id BLOB NOT NULL, -- user.id\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE state_history (\n" +
+		"  id BLOB NOT NULL, -- UUID\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  event_time INTEGER NOT NULL,\n" +
+		"  state_type TEXT NOT NULL CHECK (state_type IN ('hard', 'soft')),\n" +
+		"  soft_state INTEGER NOT NULL,\n" +
+		"  hard_state INTEGER NOT NULL,\n" +
+		"  previous_soft_state INTEGER NOT NULL,\n" +
+		"  previous_hard_state INTEGER NOT NULL,\n" +
+		"  attempt INTEGER NOT NULL,\n" +
+		"  output TEXT DEFAULT NULL,\n" +
+		"  long_output TEXT DEFAULT NULL,\n" +
+		"  max_check_attempts INTEGER NOT NULL,\n" +
+		"  check_source TEXT DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE downtime_history (\n" +
+		"  downtime_id BLOB NOT NULL, -- downtime.id\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"  triggered_by_id BLOB DEFAULT NULL, -- downtime.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  entry_time INTEGER NOT NULL,\n" +
+		"  author TEXT NOT NULL,\n" +
+		"  comment TEXT NOT NULL,\n" +
+		"  is_flexible TEXT NOT NULL CHECK (is_flexible IN ('y', 'n')),\n" +
+		"  flexible_duration INTEGER NOT NULL,\n" +
+		"  scheduled_start_time INTEGER NOT NULL,\n" +
+		"  scheduled_end_time INTEGER NOT NULL,\n" +
+		"  start_time INTEGER NOT NULL, -- Time when the host went into a problem state during the downtimes timeframe\n" +
+		"  end_time INTEGER NOT NULL, -- Problem state assumed: scheduled_end_time if fixed, start_time + duration otherwise\n" +
+		"  has_been_cancelled TEXT NOT NULL CHECK (has_been_cancelled IN ('y', 'n')),\n" +
+		"  trigger_time INTEGER NOT NULL,\n" +
+		"  cancel_time INTEGER DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (downtime_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE comment_history (\n" +
+		"  comment_id BLOB NOT NULL, -- comment.id\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  entry_time INTEGER NOT NULL,\n" +
+		"  author TEXT NOT NULL,\n" +
+		"  comment TEXT NOT NULL,\n" +
+		"  entry_type TEXT NOT NULL CHECK (entry_type IN ('comment', 'ack')),\n" +
+		"  is_persistent TEXT NOT NULL CHECK (is_persistent IN ('y', 'n')),\n" +
+		"  is_sticky TEXT NOT NULL CHECK (is_sticky IN ('y', 'n')),\n" +
+		"  expire_time INTEGER DEFAULT NULL,\n" +
+		"  remove_time INTEGER DEFAULT NULL,\n" +
+		"  has_been_removed TEXT NOT NULL CHECK (has_been_removed IN ('y', 'n')),\n" +
+		"\n" +
+		"  PRIMARY KEY (comment_id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE flapping_history (\n" +
+		"  id BLOB NOT NULL, -- UUID\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"\n" +
+		"  event_time INTEGER NOT NULL,\n" +
+		"  percent_state_change REAL NOT NULL,\n" +
+		"  flapping_threshold_low REAL NOT NULL,\n" +
+		"  flapping_threshold_high REAL NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE history (\n" +
+		"  id BLOB NOT NULL, -- notification_history_id, state_history_id, flapping_history_id or UUID\n" +
+		"  environment_id BLOB NOT NULL, -- environment.id\n" +
+		"  endpoint_id BLOB DEFAULT NULL, -- endpoint.id\n" +
+		"  object_type TEXT NOT NULL CHECK (object_type IN ('host', 'service')),\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  service_id BLOB DEFAULT NULL, -- service.id\n" +
+		"  notification_history_id BLOB DEFAULT NULL, -- notification_history.id\n" +
+		"  state_history_id BLOB DEFAULT NULL, -- state_history.id\n" +
+		"  downtime_history_id BLOB DEFAULT NULL, -- downtime_history.downtime_id\n" +
+		"  comment_history_id BLOB DEFAULT NULL, -- comment_history.comment_id\n" +
+		"  flapping_history_id BLOB DEFAULT NULL, -- flapping_history.id\n" +
+		"\n" +
+		"  event_type TEXT NOT NULL CHECK (event_type IN ('notification', 'state_change', 'downtime_schedule', 'downtime_start', 'downtime_end', 'comment_add', 'comment_remove', 'flapping_start', 'flapping_end')),\n" +
+		"  event_time INTEGER NOT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE icingadb_schema (\n" +
+		"  id INTEGER NOT NULL,\n" +
+		"  version INTEGER NOT NULL,\n" +
+		"  timestamp INTEGER NOT NULL, -- *nix timestamp in milliseconds\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(strftime('%s', 'now') AS INTEGER) * 1000);\n",
+	"sqlite/upgrades/1.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Starts tracking the schema version of databases set up before icingadb_schema existed.\n" +
+		"\n" +
+		"CREATE TABLE icingadb_schema (\n" +
+		"  id INTEGER NOT NULL,\n" +
+		"  version INTEGER NOT NULL,\n" +
+		"  timestamp INTEGER NOT NULL, -- *nix timestamp in milliseconds\n" +
+		"\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (1, CAST(strftime('%s', 'now') AS INTEGER) * 1000);\n",
+	"sqlite/upgrades/2.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Adds the fencing token to icingadb_instance and allows only one row per environment, so that a takeover can be\n" +
+		"-- done as compare-and-swap. The rows are recreated by the running instances with their next heartbeat.\n" +
+		"\n" +
+		"DELETE FROM icingadb_instance;\n" +
+		"\n" +
+		"ALTER TABLE icingadb_instance ADD COLUMN fencing_token INTEGER NOT NULL DEFAULT 0; -- incremented on every takeover\n" +
+		"\n" +
+		"CREATE UNIQUE INDEX idx_icingadb_instance_environment_id ON icingadb_instance (environment_id);\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (2, CAST(strftime('%s', 'now') AS INTEGER) * 1000);\n",
+	"sqlite/upgrades/3.sql": "-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n" +
+		"--\n" +
+		"-- Adds the tables with the parsed performance data of the current host and service states, one row per label.\n" +
+		"\n" +
+		"CREATE TABLE host_perfdata (\n" +
+		"  host_id BLOB NOT NULL, -- host.id\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  label TEXT NOT NULL,\n" +
+		"\n" +
+		"  value REAL DEFAULT NULL, -- NULL if unknown (U)\n" +
+		"  unit TEXT NOT NULL,\n" +
+		"  warn TEXT DEFAULT NULL, -- threshold range\n" +
+		"  crit TEXT DEFAULT NULL, -- threshold range\n" +
+		"  min REAL DEFAULT NULL,\n" +
+		"  max REAL DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (host_id, label)\n" +
+		");\n" +
+		"\n" +
+		"CREATE TABLE service_perfdata (\n" +
+		"  service_id BLOB NOT NULL, -- service.id\n" +
+		"  environment_id BLOB NOT NULL, -- sha1(environment.name)\n" +
+		"  label TEXT NOT NULL,\n" +
+		"\n" +
+		"  value REAL DEFAULT NULL, -- NULL if unknown (U)\n" +
+		"  unit TEXT NOT NULL,\n" +
+		"  warn TEXT DEFAULT NULL, -- threshold range\n" +
+		"  crit TEXT DEFAULT NULL, -- threshold range\n" +
+		"  min REAL DEFAULT NULL,\n" +
+		"  max REAL DEFAULT NULL,\n" +
+		"\n" +
+		"  PRIMARY KEY (service_id, label)\n" +
+		");\n" +
+		"\n" +
+		"INSERT INTO icingadb_schema (version, timestamp) VALUES (3, CAST(strftime('%s', 'now') AS INTEGER) * 1000);\n",
+}
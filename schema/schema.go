@@ -0,0 +1,215 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package schema installs and upgrades the database schema shipped in etc/schema.
+package schema
+
+//go:generate go run ../tools/embedschema -o files.go ../etc/schema
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/Icinga/icingadb/connection"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+// Version is the schema version this build of icingadb expects.
+// Every version > 1 needs an upgrade script etc/schema/<type>/upgrades/<version>.sql for each database type.
+const Version = 3
+
+var mysqlObserver = connection.DbIoSeconds.WithLabelValues("mysql", "migrate schema")
+
+// Check verifies that the database has been migrated to Version.
+func Check(dbw *connection.DBWrapper) error {
+	current, err := currentVersion(dbw)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case current == 0:
+		return fmt.Errorf("database schema is missing or untracked, run 'icingadb migrate' to install or upgrade it")
+	case current < Version:
+		return fmt.Errorf("database schema version %d is older than the expected %d, run 'icingadb migrate'", current, Version)
+	case current > Version:
+		return fmt.Errorf("database schema version %d is newer than the expected %d, please upgrade icingadb", current, Version)
+	}
+
+	return nil
+}
+
+// Migrate installs the schema into an empty database or applies the upgrade scripts up to Version.
+// Each step runs in its own transaction, which makes it atomic on databases with transactional DDL
+// (PostgreSQL and SQLite), but not on MySQL.
+func Migrate(dbw *connection.DBWrapper) error {
+	return migrate(dbw, Version)
+}
+
+func migrate(dbw *connection.DBWrapper, target int) error {
+	dbType := dbw.Dialect.Name()
+
+	current, err := currentVersion(dbw)
+	if err != nil {
+		return err
+	}
+
+	if current > target {
+		return fmt.Errorf("database schema version %d is newer than the expected %d, please upgrade icingadb", current, target)
+	}
+
+	if current == 0 {
+		empty, err := isEmpty(dbw)
+		if err != nil {
+			return err
+		}
+
+		if empty {
+			log.WithFields(log.Fields{"context": "schema", "type": dbType}).Info("Installing database schema")
+
+			if err := apply(dbw, fmt.Sprintf("%s/%s.schema.sql", dbType, dbType), 1); err != nil {
+				return err
+			}
+
+			current = 1
+		}
+	}
+
+	for version := current + 1; version <= target; version++ {
+		log.WithFields(log.Fields{"context": "schema", "version": version}).Info("Upgrading database schema")
+
+		if err := apply(dbw, fmt.Sprintf("%s/upgrades/%d.sql", dbType, version), version); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{"context": "schema", "version": target}).Info("Database schema is up to date")
+
+	return nil
+}
+
+// apply runs the statements of the embedded file in one transaction and verifies that it recorded version.
+func apply(dbw *connection.DBWrapper, file string, version int) error {
+	script, ok := files[file]
+	if !ok {
+		return fmt.Errorf("missing schema file %s", file)
+	}
+
+	err := dbw.SqlTransaction(true, false, false, func(tx connection.DbTransaction) error {
+		for _, stmt := range SplitStatements(script) {
+			if _, err := dbw.SqlExecTx(tx, mysqlObserver, stmt); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't apply %s: %s", file, err.Error())
+	}
+
+	current, err := currentVersion(dbw)
+	if err != nil {
+		return err
+	}
+
+	if current != version {
+		return fmt.Errorf("%s did not record schema version %d", file, version)
+	}
+
+	return nil
+}
+
+// currentVersion returns the highest version recorded in icingadb_schema, 0 if that table doesn't exist.
+// Any other error is returned, so that e.g. missing privileges aren't mistaken for a missing schema.
+func currentVersion(dbw *connection.DBWrapper) (int, error) {
+	if err := dbw.Db.Ping(); err != nil {
+		return 0, err
+	}
+
+	rows, err := dbw.Db.Query("SELECT MAX(version) FROM icingadb_schema")
+	if err != nil {
+		if dbw.Dialect.IsUndefinedTable(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var version sql.NullInt64
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+	}
+
+	return int(version.Int64), rows.Err()
+}
+
+// isEmpty returns whether the database doesn't contain the icingadb tables yet.
+// Only a missing table counts as empty, any other error is returned.
+func isEmpty(dbw *connection.DBWrapper) (bool, error) {
+	if err := dbw.Db.Ping(); err != nil {
+		return false, err
+	}
+
+	rows, err := dbw.Db.Query("SELECT 1 FROM environment WHERE 1 = 0")
+	if err != nil {
+		if dbw.Dialect.IsUndefinedTable(err) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return false, rows.Close()
+}
+
+// SplitStatements splits an SQL script into its statements, which have to end with a semicolon at the end of a line.
+func SplitStatements(script string) []string {
+	var statements []string
+	var stmt []string
+
+	for _, line := range strings.Split(script, "\n") {
+		stmt = append(stmt, line)
+
+		if code := strings.TrimSpace(stripComment(line)); strings.HasSuffix(code, ";") {
+			statements = appendStatement(statements, strings.TrimSuffix(strings.Join(stmt, "\n"), line)+code[:len(code)-1])
+			stmt = nil
+		}
+	}
+
+	return appendStatement(statements, strings.Join(stmt, "\n"))
+}
+
+// appendStatement appends stmt to statements unless it consists of comments and whitespace only.
+func appendStatement(statements []string, stmt string) []string {
+	for _, line := range strings.Split(stmt, "\n") {
+		if strings.TrimSpace(stripComment(line)) != "" {
+			return append(statements, strings.TrimSpace(stmt))
+		}
+	}
+
+	return statements
+}
+
+// stripComment removes a -- comment from line, ignoring -- inside quotes.
+func stripComment(line string) string {
+	var quote rune
+
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case strings.HasPrefix(line[i:], "--"):
+			return line[:i]
+		}
+	}
+
+	return line
+}
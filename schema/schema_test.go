@@ -0,0 +1,170 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package schema
+
+import (
+	"fmt"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSqliteTestDBW(t *testing.T) (*connection.DBWrapper, func()) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+
+	dialect, err := connection.GetDialect("sqlite")
+	require.NoError(t, err)
+
+	dbw, err := connection.NewDBWrapperWithDialect(dialect, filepath.Join(dir, "icingadb.db"), 4)
+	require.NoError(t, err)
+
+	dbw.WaitForConnection()
+
+	return dbw, func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+CREATE TABLE a (
+  id INTEGER NOT NULL, -- comment;
+  name TEXT NOT NULL
+);
+CREATE INDEX idx_a ON a (name); -- lookup
+
+-- trailing comment
+INSERT INTO a VALUES (1, 'x;y');`
+
+	assert.Equal(t, []string{
+		"-- IcingaDB | (c) 2019 Icinga GmbH | GPLv2+\n\nCREATE TABLE a (\n  id INTEGER NOT NULL, -- comment;\n  name TEXT NOT NULL\n)",
+		"CREATE INDEX idx_a ON a (name)",
+		"-- trailing comment\nINSERT INTO a VALUES (1, 'x;y')",
+	}, SplitStatements(script))
+}
+
+func TestFiles(t *testing.T) {
+	matches, err := filepath.Glob("../etc/schema/*/*.schema.sql")
+	require.NoError(t, err)
+
+	upgrades, err := filepath.Glob("../etc/schema/*/upgrades/*.sql")
+	require.NoError(t, err)
+
+	matches = append(matches, upgrades...)
+	assert.Len(t, files, len(matches), "files.go is outdated, run go generate")
+
+	for _, match := range matches {
+		content, err := ioutil.ReadFile(match)
+		require.NoError(t, err)
+
+		rel, err := filepath.Rel("../etc/schema", match)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(content), files[filepath.ToSlash(rel)], "files.go is outdated, run go generate")
+	}
+
+	for _, dbType := range []string{"mysql", "pgsql", "sqlite"} {
+		assert.Contains(t, files, fmt.Sprintf("%s/%s.schema.sql", dbType, dbType))
+
+		for version := 1; version <= Version; version++ {
+			assert.Contains(t, files, fmt.Sprintf("%s/upgrades/%d.sql", dbType, version))
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	assert.Error(t, Check(dbw), "empty database must not pass the check")
+
+	require.NoError(t, Migrate(dbw))
+	assert.NoError(t, Check(dbw))
+
+	// Running it again must be a no-op.
+	require.NoError(t, Migrate(dbw))
+	assert.NoError(t, Check(dbw))
+}
+
+func TestMigrate_Untracked(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	_, err := dbw.Db.Exec(files["sqlite/sqlite.schema.sql"])
+	require.NoError(t, err)
+
+	_, err = dbw.Db.Exec("DROP TABLE icingadb_schema")
+	require.NoError(t, err)
+
+	assert.Error(t, Check(dbw), "untracked schema must not pass the check")
+
+	require.NoError(t, Migrate(dbw))
+	assert.NoError(t, Check(dbw))
+}
+
+func TestMigrate_Upgrade(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	require.NoError(t, Migrate(dbw))
+
+	next := fmt.Sprintf("sqlite/upgrades/%d.sql", Version+1)
+	files[next] = fmt.Sprintf(
+		"CREATE TABLE foo (id INTEGER NOT NULL);\nINSERT INTO icingadb_schema (version, timestamp) VALUES (%d, 0);\n",
+		Version+1,
+	)
+	defer delete(files, next)
+
+	require.NoError(t, migrate(dbw, Version+1))
+
+	version, err := currentVersion(dbw)
+	require.NoError(t, err)
+	assert.Equal(t, Version+1, version)
+
+	assert.Error(t, Check(dbw), "newer schema must not pass the check")
+}
+
+func TestMigrate_FailedUpgrade(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	require.NoError(t, Migrate(dbw))
+
+	next := fmt.Sprintf("sqlite/upgrades/%d.sql", Version+1)
+	files[next] = fmt.Sprintf(
+		"CREATE TABLE foo (id INTEGER NOT NULL);\nINSERT INTO icingadb_schema (version, timestamp) VALUES (%d, 0);\nSYNTAX ERROR;\n",
+		Version+1,
+	)
+	defer delete(files, next)
+
+	assert.Error(t, migrate(dbw, Version+1))
+
+	// SQLite has transactional DDL, so nothing of the failed upgrade must remain.
+	version, err := currentVersion(dbw)
+	require.NoError(t, err)
+	assert.Equal(t, Version, version)
+
+	_, err = dbw.Db.Exec("SELECT 1 FROM foo")
+	assert.Error(t, err)
+}
+
+func TestMigrate_BrokenSchemaTable(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	_, err := dbw.Db.Exec("CREATE TABLE icingadb_schema (id INTEGER NOT NULL)")
+	require.NoError(t, err)
+
+	_, err = currentVersion(dbw)
+	assert.Error(t, err, "errors other than a missing table must not be taken for version 0")
+	assert.Error(t, Migrate(dbw))
+
+	_, err = dbw.Db.Exec("SELECT 1 FROM environment")
+	assert.Error(t, err, "the schema must not have been installed")
+}
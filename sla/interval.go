@@ -0,0 +1,117 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package sla
+
+import "sort"
+
+// Interval is the time from Start (inclusive) to End (exclusive) in milliseconds since the epoch,
+// the unit of all times in the database.
+type Interval struct {
+	Start int64
+	End   int64
+}
+
+// Intervals is a set of non-overlapping intervals sorted by start, as returned by normalize.
+type Intervals []Interval
+
+// normalize sorts intervals and merges the overlapping and adjacent ones. Empty intervals are dropped.
+func normalize(intervals []Interval) Intervals {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		if interval.End > interval.Start {
+			sorted = append(sorted, interval)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var merged Intervals
+	for _, interval := range sorted {
+		if last := len(merged) - 1; last >= 0 && interval.Start <= merged[last].End {
+			if interval.End > merged[last].End {
+				merged[last].End = interval.End
+			}
+
+			continue
+		}
+
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+// Duration returns the total length of all intervals in milliseconds.
+func (is Intervals) Duration() int64 {
+	var duration int64
+	for _, interval := range is {
+		duration += interval.End - interval.Start
+	}
+
+	return duration
+}
+
+// Intersect returns the time which is in both is and other.
+func (is Intervals) Intersect(other Intervals) Intervals {
+	var result []Interval
+
+	for i, j := 0, 0; i < len(is) && j < len(other); {
+		start, end := max(is[i].Start, other[j].Start), min(is[i].End, other[j].End)
+		if start < end {
+			result = append(result, Interval{start, end})
+		}
+
+		if is[i].End < other[j].End {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return normalize(result)
+}
+
+// Subtract returns the time which is in is, but not in other.
+func (is Intervals) Subtract(other Intervals) Intervals {
+	var result []Interval
+
+	for _, interval := range is {
+		start := interval.Start
+
+		for _, cut := range other {
+			if cut.End <= start || cut.Start >= interval.End {
+				continue
+			}
+
+			if cut.Start > start {
+				result = append(result, Interval{start, cut.Start})
+			}
+
+			start = cut.End
+		}
+
+		if start < interval.End {
+			result = append(result, Interval{start, interval.End})
+		}
+	}
+
+	return normalize(result)
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,38 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package sla
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(
+		t,
+		Intervals{{0, 20}, {30, 40}},
+		normalize([]Interval{{30, 40}, {10, 20}, {0, 10}, {5, 15}, {50, 50}, {60, 55}}),
+	)
+	assert.Nil(t, normalize(nil))
+}
+
+func TestIntervals_Intersect(t *testing.T) {
+	a := Intervals{{0, 10}, {20, 30}, {40, 50}}
+	b := Intervals{{5, 25}, {45, 60}}
+
+	assert.Equal(t, Intervals{{5, 10}, {20, 25}, {45, 50}}, a.Intersect(b))
+	assert.Equal(t, a.Intersect(b), b.Intersect(a))
+	assert.Nil(t, a.Intersect(nil))
+}
+
+func TestIntervals_Subtract(t *testing.T) {
+	a := Intervals{{0, 10}, {20, 30}}
+
+	assert.Equal(t, Intervals{{0, 2}, {4, 10}, {25, 30}}, a.Subtract(Intervals{{2, 4}, {15, 25}}))
+	assert.Equal(t, a, a.Subtract(nil))
+	assert.Nil(t, a.Subtract(Intervals{{0, 30}}))
+}
+
+func TestIntervals_Duration(t *testing.T) {
+	assert.Equal(t, int64(15), Intervals{{0, 10}, {20, 25}}.Duration())
+}
@@ -0,0 +1,50 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package sla
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteJSON writes results as JSON array to w.
+func WriteJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(results)
+}
+
+// WriteCSV writes results as CSV with a header line to w. Availability is empty if it's unknown.
+func WriteCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"object_type", "host", "service", "total_seconds", "problem_seconds", "availability",
+	}); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		availability := ""
+		if result.Availability != nil {
+			availability = strconv.FormatFloat(*result.Availability, 'f', 4, 64)
+		}
+
+		if err := writer.Write([]string{
+			result.ObjectType,
+			result.Host,
+			result.Service,
+			strconv.FormatFloat(result.TotalSeconds, 'f', -1, 64),
+			strconv.FormatFloat(result.ProblemSeconds, 'f', -1, 64),
+			availability,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,371 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package sla computes the availability of hosts and services from their hard state history.
+package sla
+
+import (
+	"fmt"
+	"github.com/Icinga/icingadb/connection"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Options select the objects and the time a report is about.
+type Options struct {
+	// Environment is the ID of the environment.
+	Environment []byte
+	From        time.Time
+	To          time.Time
+	// ObjectType is "host", "service" or empty for both.
+	ObjectType string
+	// Host and Service restrict the report to objects with that name, if not empty.
+	Host    string
+	Service string
+	// ExcludeDowntimes excludes the time objects were in downtime, as if it wasn't part of the report.
+	ExcludeDowntimes bool
+	// Timeperiod, if not nil, restricts the report to the time covered by it, in the time zone Location.
+	Timeperiod Timeperiod
+	Location   *time.Location
+}
+
+// Result is the availability of one object.
+type Result struct {
+	ObjectType string `json:"object_type"`
+	Host       string `json:"host"`
+	Service    string `json:"service,omitempty"`
+	// TotalSeconds is the time the report is about, after excluding downtimes and time outside the timeperiod.
+	TotalSeconds float64 `json:"total_seconds"`
+	// ProblemSeconds is the part of TotalSeconds the object was in a hard problem state.
+	ProblemSeconds float64 `json:"problem_seconds"`
+	// Availability is the percentage of TotalSeconds the object was OK, nil if TotalSeconds is 0.
+	Availability *float64 `json:"availability"`
+}
+
+// stateChange is a hard state change of an object.
+type stateChange struct {
+	time    int64
+	problem bool
+}
+
+// object is a host or service with its state changes and downtimes within the report.
+type object struct {
+	objectType     string
+	host           string
+	service        string
+	initialProblem bool
+	initialKnown   bool
+	changes        []stateChange
+	downtimes      []Interval
+}
+
+var slaObserver = connection.DbIoSeconds.WithLabelValues("mysql", "select sla report")
+
+// Report computes the availability of the objects selected by opts.
+func Report(dbw *connection.DBWrapper, opts Options) ([]Result, error) {
+	report := Interval{toMillis(opts.From), toMillis(opts.To)}
+
+	objects, err := loadObjects(dbw, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadStates(dbw, opts.Environment, report, objects); err != nil {
+		return nil, err
+	}
+
+	if opts.ExcludeDowntimes {
+		if err := loadDowntimes(dbw, opts.Environment, report, objects); err != nil {
+			return nil, err
+		}
+	}
+
+	window := Intervals{report}
+	if opts.Timeperiod != nil {
+		location := opts.Location
+		if location == nil {
+			location = time.Local
+		}
+
+		if window, err = opts.Timeperiod.Intervals(report, location); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]Result, 0, len(objects))
+	for _, o := range objects {
+		total, problem := availability(report, window, o.initialProblem, o.changes, normalize(o.downtimes))
+		result := Result{
+			ObjectType:     o.objectType,
+			Host:           o.host,
+			Service:        o.service,
+			TotalSeconds:   float64(total) / 1000,
+			ProblemSeconds: float64(problem) / 1000,
+		}
+
+		if total > 0 {
+			percentage := 100 * float64(total-problem) / float64(total)
+			result.Availability = &percentage
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return results[i].Host < results[j].Host
+		}
+
+		return results[i].Service < results[j].Service
+	})
+
+	return results, nil
+}
+
+// availability returns the time of window within report minus downtimes and how much of it the object was
+// in a problem state, given its state at the start of report and its state changes within.
+func availability(report Interval, window Intervals, initialProblem bool, changes []stateChange, downtimes Intervals) (total int64, problem int64) {
+	window = window.Subtract(downtimes)
+
+	var problems []Interval
+	since, isProblem := report.Start, initialProblem
+
+	for _, change := range changes {
+		if isProblem {
+			problems = append(problems, Interval{since, change.time})
+		}
+
+		since, isProblem = change.time, change.problem
+	}
+
+	if isProblem {
+		problems = append(problems, Interval{since, report.End})
+	}
+
+	return window.Duration(), normalize(problems).Intersect(window).Duration()
+}
+
+// isProblem returns whether a hard state is a problem. Everything but OK/UP and pending (99) is.
+func isProblem(hardState int64) bool {
+	return hardState != 0 && hardState != 99
+}
+
+// loadObjects returns the selected hosts and services by their ID.
+func loadObjects(dbw *connection.DBWrapper, opts Options) (map[string]*object, error) {
+	objects := map[string]*object{}
+
+	if opts.ObjectType != "service" && opts.Service == "" {
+		query := "SELECT id, name FROM host WHERE environment_id = ?"
+		args := []interface{}{opts.Environment}
+
+		if opts.Host != "" {
+			query += " AND name = ?"
+			args = append(args, opts.Host)
+		}
+
+		rows, err := dbw.SqlFetchAll(slaObserver, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			objects[string(row[0].([]byte))] = &object{objectType: "host", host: stringValue(row[1])}
+		}
+	}
+
+	if opts.ObjectType != "host" {
+		query := "SELECT s.id, h.name, s.name FROM service s JOIN host h ON h.id = s.host_id WHERE s.environment_id = ?"
+		args := []interface{}{opts.Environment}
+
+		if opts.Host != "" {
+			query += " AND h.name = ?"
+			args = append(args, opts.Host)
+		}
+
+		if opts.Service != "" {
+			query += " AND s.name = ?"
+			args = append(args, opts.Service)
+		}
+
+		rows, err := dbw.SqlFetchAll(slaObserver, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			objects[string(row[0].([]byte))] = &object{
+				objectType: "service", host: stringValue(row[1]), service: stringValue(row[2]),
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// loadStates sets the state of objects at the start of report and their state changes within.
+func loadStates(dbw *connection.DBWrapper, environment []byte, report Interval, objects map[string]*object) error {
+	// The latest hard state before the report of each object.
+	rows, err := dbw.SqlFetchAll(
+		slaObserver,
+		"SELECT sh.host_id, sh.service_id, sh.hard_state FROM state_history sh "+
+			"JOIN (SELECT COALESCE(service_id, host_id) AS object_id, MAX(event_time) AS event_time FROM state_history "+
+			"WHERE environment_id = ? AND state_type = 'hard' AND event_time < ? "+
+			"GROUP BY COALESCE(service_id, host_id)) latest "+
+			"ON COALESCE(sh.service_id, sh.host_id) = latest.object_id AND sh.event_time = latest.event_time "+
+			"WHERE sh.environment_id = ? AND sh.state_type = 'hard'",
+		environment, report.Start, environment,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if o, ok := objects[objectID(row[0], row[1])]; ok {
+			o.initialProblem = isProblem(int64Value(row[2]))
+			o.initialKnown = true
+		}
+	}
+
+	rows, err = dbw.SqlFetchAll(
+		slaObserver,
+		"SELECT host_id, service_id, event_time, hard_state, previous_hard_state FROM state_history "+
+			"WHERE environment_id = ? AND state_type = 'hard' AND event_time >= ? AND event_time < ? ORDER BY event_time",
+		environment, report.Start, report.End,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		o, ok := objects[objectID(row[0], row[1])]
+		if !ok {
+			continue
+		}
+
+		if !o.initialKnown {
+			// No state before the report, so it's the one the first change within came from.
+			o.initialProblem = isProblem(int64Value(row[4]))
+			o.initialKnown = true
+		}
+
+		o.changes = append(o.changes, stateChange{time: int64Value(row[2]), problem: isProblem(int64Value(row[3]))})
+	}
+
+	return nil
+}
+
+// loadDowntimes sets the downtimes of objects overlapping with report.
+func loadDowntimes(dbw *connection.DBWrapper, environment []byte, report Interval, objects map[string]*object) error {
+	// Downtimes which never started, e.g. flexible ones without a problem, have no trigger time.
+	// Running ones without an end yet last until the end of the report.
+	rows, err := dbw.SqlFetchAll(
+		slaObserver,
+		"SELECT host_id, service_id, start_time, end_time, cancel_time FROM downtime_history "+
+			"WHERE environment_id = ? AND trigger_time > 0 AND start_time < ? AND COALESCE(cancel_time, end_time, ?) > ?",
+		environment, report.End, report.End, report.Start,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if o, ok := objects[objectID(row[0], row[1])]; ok {
+			end := report.End
+			if row[4] != nil {
+				end = int64Value(row[4])
+			} else if row[3] != nil {
+				end = int64Value(row[3])
+			}
+
+			o.downtimes = append(o.downtimes, Interval{int64Value(row[2]), end})
+		}
+	}
+
+	return nil
+}
+
+// FindEnvironment returns the ID of the environment called name.
+// If name is empty, the only environment there is will do.
+func FindEnvironment(dbw *connection.DBWrapper, name string) ([]byte, error) {
+	query := "SELECT id, name FROM environment"
+	var args []interface{}
+
+	if name != "" {
+		query += " WHERE name = ?"
+		args = append(args, name)
+	}
+
+	rows, err := dbw.SqlFetchAll(slaObserver, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(rows) == 1:
+		return rows[0][0].([]byte), nil
+	case name != "":
+		return nil, fmt.Errorf("no environment %q", name)
+	case len(rows) == 0:
+		return nil, fmt.Errorf("no environment synced yet")
+	default:
+		return nil, fmt.Errorf("there are %d environments, one has to be chosen", len(rows))
+	}
+}
+
+// LoadTimeperiod returns the ranges of the timeperiod called name, nil if there is no such timeperiod.
+func LoadTimeperiod(dbw *connection.DBWrapper, environment []byte, name string) (Timeperiod, error) {
+	rows, err := dbw.SqlFetchAll(
+		slaObserver,
+		"SELECT t.id, r.range_key, r.range_value FROM timeperiod t "+
+			"LEFT JOIN timeperiod_range r ON r.timeperiod_id = t.id WHERE t.environment_id = ? AND t.name = ?",
+		environment, name,
+	)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	timeperiod := Timeperiod{}
+	for _, row := range rows {
+		if row[1] != nil {
+			timeperiod[stringValue(row[1])] = stringValue(row[2])
+		}
+	}
+
+	return timeperiod, nil
+}
+
+// objectID returns the key of a host or service in the map returned by loadObjects.
+// The IDs are selected as is, not via COALESCE(), as the type of expressions is unknown to the database drivers.
+func objectID(hostID, serviceID interface{}) string {
+	if id, ok := serviceID.([]byte); ok && id != nil {
+		return string(id)
+	}
+
+	id, _ := hostID.([]byte)
+	return string(id)
+}
+
+// int64Value returns v, which is an integer column, as int64.
+func int64Value(v interface{}) int64 {
+	switch value := v.(type) {
+	case int64:
+		return value
+	case []byte:
+		i, _ := strconv.ParseInt(string(value), 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
+
+// stringValue returns v, which is a text column, as string.
+func stringValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return ""
+	}
+}
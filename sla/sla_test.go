@@ -0,0 +1,142 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package sla
+
+import (
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAvailability(t *testing.T) {
+	report := Interval{0, 100}
+	changes := []stateChange{{20, true}, {40, false}, {90, true}}
+
+	total, problem := availability(report, Intervals{report}, false, changes, nil)
+	assert.Equal(t, int64(100), total)
+	assert.Equal(t, int64(30), problem, "problems from 20 to 40 and 90 to 100")
+
+	total, problem = availability(report, Intervals{report}, true, changes, nil)
+	assert.Equal(t, int64(100), total)
+	assert.Equal(t, int64(50), problem, "problems from 0 to 40 and 90 to 100")
+
+	total, problem = availability(report, Intervals{report}, false, changes, Intervals{{30, 50}})
+	assert.Equal(t, int64(80), total, "downtime from 30 to 50 excluded")
+	assert.Equal(t, int64(20), problem, "problems from 20 to 30 and 90 to 100")
+
+	total, problem = availability(report, Intervals{{0, 25}, {85, 95}}, false, changes, nil)
+	assert.Equal(t, int64(35), total, "only within the timeperiod")
+	assert.Equal(t, int64(10), problem, "problems from 20 to 25 and 90 to 95")
+
+	total, problem = availability(report, nil, true, nil, nil)
+	assert.Equal(t, int64(0), total)
+	assert.Equal(t, int64(0), problem)
+}
+
+// newSqliteTestDBW returns a DBWrapper of a new SQLite database and a function removing it.
+func newSqliteTestDBW(t *testing.T) (*connection.DBWrapper, func()) {
+	dir, err := ioutil.TempDir("", "icingadb")
+	require.NoError(t, err)
+
+	dialect, err := connection.GetDialect("sqlite")
+	require.NoError(t, err)
+
+	dbw, err := connection.NewDBWrapperWithDialect(dialect, filepath.Join(dir, "icingadb.db"), 4)
+	require.NoError(t, err)
+
+	dbw.WaitForConnection()
+
+	return dbw, func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestLoadStates(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	require.NoError(t, schema.Migrate(dbw))
+
+	env := []byte("env")
+	states := []struct {
+		host          byte
+		eventTime     int64
+		stateType     string
+		hardState     int64
+		previousState int64
+	}{
+		{1, 10, "hard", 1, 0},
+		{1, 20, "hard", 0, 1},
+		{1, 30, "soft", 0, 0},
+		{1, 150, "hard", 1, 0},
+		{1, 250, "hard", 0, 1},
+		{2, 150, "hard", 0, 1},
+	}
+
+	for i, s := range states {
+		_, err := dbw.SqlExec(
+			slaObserver,
+			"INSERT INTO state_history (id, environment_id, object_type, host_id, event_time, state_type, soft_state, "+
+				"hard_state, previous_soft_state, previous_hard_state, attempt, max_check_attempts) "+
+				"VALUES (?, ?, 'host', ?, ?, ?, 0, ?, 0, ?, 1, 1)",
+			[]byte{byte(i)}, env, []byte{s.host}, s.eventTime, s.stateType, s.hardState, s.previousState,
+		)
+		require.NoError(t, err)
+	}
+
+	objects := map[string]*object{"\x01": {}, "\x02": {}, "\x03": {}}
+	require.NoError(t, loadStates(dbw, env, Interval{100, 200}, objects))
+
+	assert.Equal(t, &object{initialKnown: true, changes: []stateChange{{150, true}}}, objects["\x01"])
+	assert.Equal(
+		t, &object{initialProblem: true, initialKnown: true, changes: []stateChange{{150, false}}}, objects["\x02"],
+		"the state before the report has to be taken from the first change within",
+	)
+	assert.Equal(t, &object{}, objects["\x03"])
+}
+
+func TestLoadDowntimes(t *testing.T) {
+	dbw, cleanup := newSqliteTestDBW(t)
+	defer cleanup()
+
+	// Only the columns queried, with an end_time which may not be known yet while the downtime is running.
+	_, err := dbw.Db.Exec(
+		"CREATE TABLE downtime_history (environment_id BLOB NOT NULL, host_id BLOB NOT NULL, service_id BLOB, " +
+			"start_time INTEGER NOT NULL, end_time INTEGER, trigger_time INTEGER NOT NULL, cancel_time INTEGER)",
+	)
+	require.NoError(t, err)
+
+	env := []byte("env")
+	downtimes := []struct {
+		host       byte
+		startTime  int64
+		endTime    interface{}
+		cancelTime interface{}
+	}{
+		{1, 50, int64(120), nil},
+		{1, 150, int64(300), int64(180)},
+		{1, 20, int64(80), nil},
+		{2, 190, nil, nil},
+	}
+
+	for _, d := range downtimes {
+		_, err := dbw.SqlExec(
+			slaObserver,
+			"INSERT INTO downtime_history (environment_id, host_id, start_time, end_time, trigger_time, cancel_time) "+
+				"VALUES (?, ?, ?, ?, ?, ?)",
+			env, []byte{d.host}, d.startTime, d.endTime, d.startTime, d.cancelTime,
+		)
+		require.NoError(t, err)
+	}
+
+	objects := map[string]*object{"\x01": {}, "\x02": {}}
+	require.NoError(t, loadDowntimes(dbw, env, Interval{100, 200}, objects))
+
+	assert.Equal(t, []Interval{{50, 120}, {150, 180}}, objects["\x01"].downtimes, "the cancel time has to win")
+	assert.Equal(t, []Interval{{190, 200}}, objects["\x02"].downtimes, "running downtimes last until the report end")
+}
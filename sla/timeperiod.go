@@ -0,0 +1,122 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package sla
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timeperiod holds the ranges of an Icinga 2 timeperiod, e.g. "monday" => "09:00-17:00,18:00-20:00".
+// Only weekdays and dates (2020-01-31) are supported as keys.
+type Timeperiod map[string]string
+
+// Unsupported returns the range keys which are ignored by Intervals.
+func (tp Timeperiod) Unsupported() []string {
+	var unsupported []string
+	for key := range tp {
+		if _, ok := weekdays[strings.ToLower(key)]; ok {
+			continue
+		}
+
+		if _, err := time.Parse("2006-01-02", key); err == nil {
+			continue
+		}
+
+		unsupported = append(unsupported, key)
+	}
+
+	return unsupported
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Intervals returns the time within window covered by the timeperiod. The ranges are in the time zone loc.
+func (tp Timeperiod) Intervals(window Interval, loc *time.Location) (Intervals, error) {
+	byWeekday := map[time.Weekday]string{}
+	byDate := map[string]string{}
+
+	for key, value := range tp {
+		if weekday, ok := weekdays[strings.ToLower(key)]; ok {
+			byWeekday[weekday] = value
+		} else if _, err := time.Parse("2006-01-02", key); err == nil {
+			byDate[key] = value
+		}
+	}
+
+	start := fromMillis(window.Start).In(loc)
+	end := fromMillis(window.End).In(loc)
+
+	var intervals []Interval
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		for _, value := range []string{byWeekday[day.Weekday()], byDate[day.Format("2006-01-02")]} {
+			ranges, err := parseRanges(day, value)
+			if err != nil {
+				return nil, err
+			}
+
+			intervals = append(intervals, ranges...)
+		}
+	}
+
+	return normalize(intervals).Intersect(Intervals{window}), nil
+}
+
+// parseRanges parses comma separated time ranges like "09:00-17:00" of day.
+func parseRanges(day time.Time, value string) ([]Interval, error) {
+	var intervals []Interval
+
+	for _, r := range strings.Split(value, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+
+		parts := strings.Split(r, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid timeperiod range %q", r)
+		}
+
+		start, err := parseClock(day, parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := parseClock(day, parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, Interval{toMillis(start), toMillis(end)})
+	}
+
+	return intervals, nil
+}
+
+// parseClock returns the time of day at clock, e.g. "09:30". "24:00" is the end of day.
+func parseClock(day time.Time, clock string) (time.Time, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(strings.TrimSpace(clock), "%d:%d", &hour, &minute); err != nil ||
+		hour < 0 || minute < 0 || minute > 59 || hour > 24 || hour == 24 && minute > 0 {
+		return time.Time{}, fmt.Errorf("invalid time of day %q", clock)
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()), nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
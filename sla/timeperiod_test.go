@@ -0,0 +1,46 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package sla
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTimeperiod_Intervals(t *testing.T) {
+	loc := time.FixedZone("test", 3600)
+	at := func(day, hour, minute int) int64 {
+		return toMillis(time.Date(2020, 1, day, hour, minute, 0, 0, loc))
+	}
+
+	tp := Timeperiod{
+		"Monday":     "09:00-17:00",
+		"tuesday":    "00:00-01:00, 23:00-24:00",
+		"2020-01-08": "12:00-13:00",
+		"monday -1":  "00:00-24:00",
+	}
+
+	// From Monday, 2020-01-06 10:00 to Wednesday 12:30.
+	intervals, err := tp.Intervals(Interval{at(6, 10, 0), at(8, 12, 30)}, loc)
+	require.NoError(t, err)
+
+	assert.Equal(t, Intervals{
+		{at(6, 10, 0), at(6, 17, 0)},
+		{at(7, 0, 0), at(7, 1, 0)},
+		{at(7, 23, 0), at(8, 0, 0)},
+		{at(8, 12, 0), at(8, 12, 30)},
+	}, intervals)
+
+	assert.Equal(t, []string{"monday -1"}, tp.Unsupported())
+}
+
+func TestTimeperiod_IntervalsInvalid(t *testing.T) {
+	window := Interval{0, toMillis(time.Unix(0, 0).AddDate(0, 0, 7))}
+
+	for _, value := range []string{"09:00", "9-17", "09:00-25:00", "24:30-24:45"} {
+		_, err := Timeperiod{"monday": value}.Intervals(window, time.UTC)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,138 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/sla"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"strings"
+	"time"
+)
+
+// slaReport prints the availability of hosts and services over a time range. It returns the exit code.
+func slaReport(path string, args []string) int {
+	flags := flag.NewFlagSet("sla", flag.ExitOnError)
+	from := flags.String("from", "", "start of the report, as 2006-01-02 or RFC 3339 (required)")
+	to := flags.String("to", "", "end of the report (exclusive), as 2006-01-02 or RFC 3339 (default now)")
+	environment := flags.String("environment", "", "name of the environment, required if there are multiple")
+	host := flags.String("host", "", "report only the host with this name and its services")
+	service := flags.String("service", "", "report only services with this name")
+	objectType := flags.String("type", "all", "report hosts, services or all")
+	excludeDowntimes := flags.Bool("exclude-downtimes", false, "don't count the time objects were in downtime")
+	timeperiod := flags.String("timeperiod", "", "count only the time covered by the timeperiod with this name")
+	format := flags.String("format", "json", "output format, json or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: sla -from DATE [options]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	opts := sla.Options{
+		Host:             *host,
+		Service:          *service,
+		ExcludeDowntimes: *excludeDowntimes,
+		Location:         time.Local,
+	}
+
+	var err error
+	if opts.From, err = parseReportTime(*from); err != nil || *from == "" {
+		fmt.Fprintf(os.Stderr, "-from: invalid or missing date %q\n", *from)
+		return 2
+	}
+
+	opts.To = time.Now()
+	if *to != "" {
+		if opts.To, err = parseReportTime(*to); err != nil {
+			fmt.Fprintf(os.Stderr, "-to: invalid date %q\n", *to)
+			return 2
+		}
+	}
+
+	if !opts.To.After(opts.From) {
+		fmt.Fprintln(os.Stderr, "-to has to be after -from")
+		return 2
+	}
+
+	switch *objectType {
+	case "host", "service":
+		opts.ObjectType = *objectType
+	case "all":
+	default:
+		fmt.Fprintf(os.Stderr, "-type: invalid type %q\n", *objectType)
+		return 2
+	}
+
+	write := sla.WriteJSON
+	switch *format {
+	case "json":
+	case "csv":
+		write = sla.WriteCSV
+	default:
+		fmt.Fprintf(os.Stderr, "-format: invalid format %q\n", *format)
+		return 2
+	}
+
+	if err := config.ParseConfig(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, err.Error())
+		return 1
+	}
+
+	// The connection wrapper logs its attempts, only the outcome is of interest here.
+	log.SetLevel(log.FatalLevel)
+
+	mysqlConn, err := connectDatabase(config.GetMysqlInfo())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "database: %s\n", err.Error())
+		return 1
+	}
+
+	if opts.Environment, err = sla.FindEnvironment(mysqlConn, *environment); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	if *timeperiod != "" {
+		if opts.Timeperiod, err = sla.LoadTimeperiod(mysqlConn, opts.Environment, *timeperiod); err != nil {
+			fmt.Fprintf(os.Stderr, "database: %s\n", err.Error())
+			return 1
+		}
+
+		if opts.Timeperiod == nil {
+			fmt.Fprintf(os.Stderr, "no timeperiod %q\n", *timeperiod)
+			return 1
+		}
+
+		if unsupported := opts.Timeperiod.Unsupported(); len(unsupported) > 0 {
+			fmt.Fprintf(
+				os.Stderr, "Ignoring unsupported ranges of timeperiod %q: %s\n",
+				*timeperiod, strings.Join(unsupported, ", "),
+			)
+		}
+	}
+
+	results, err := sla.Report(mysqlConn, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "database: %s\n", err.Error())
+		return 1
+	}
+
+	if err := write(os.Stdout, results); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+// parseReportTime parses a date (local midnight) or an RFC 3339 timestamp.
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
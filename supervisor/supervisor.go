@@ -15,4 +15,36 @@ type Supervisor struct {
 	Dbw      *connection.DBWrapper
 	EnvId    []byte
 	EnvLock  *sync.Mutex
+	// Done is closed once IcingaDB is shutting down.
+	Done chan struct{}
+	// Workers tracks the workers which have to finish their current batch before IcingaDB may exit.
+	Workers *sync.WaitGroup
+}
+
+// ShuttingDown returns whether Done has been closed.
+func (s *Supervisor) ShuttingDown() bool {
+	if s.Done == nil {
+		return false
+	}
+
+	select {
+	case <-s.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Go runs worker in a new goroutine, which is waited for by Workers.
+func (s *Supervisor) Go(worker func()) {
+	if s.Workers == nil {
+		go worker()
+		return
+	}
+
+	s.Workers.Add(1)
+	go func() {
+		defer s.Workers.Done()
+		worker()
+	}()
 }
@@ -0,0 +1,34 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package supervisor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestSupervisor_ShuttingDown(t *testing.T) {
+	super := Supervisor{}
+	assert.False(t, super.ShuttingDown(), "without Done there is no shutdown")
+
+	super.Done = make(chan struct{})
+	assert.False(t, super.ShuttingDown())
+
+	close(super.Done)
+	assert.True(t, super.ShuttingDown())
+}
+
+func TestSupervisor_Go(t *testing.T) {
+	super := Supervisor{Done: make(chan struct{}), Workers: &sync.WaitGroup{}}
+	finished := false
+
+	super.Go(func() {
+		<-super.Done
+		finished = true
+	})
+
+	close(super.Done)
+	super.Workers.Wait()
+	assert.True(t, finished, "Workers must wait for the worker")
+}
@@ -0,0 +1,97 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// embedschema writes the SQL files below etc/schema into a Go source file,
+// so that they are part of the icingadb binary.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	out := flag.String("o", "files.go", "output file")
+	pkg := flag.String("pkg", "schema", "package name")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatalf("usage: %s [-o files.go] [-pkg schema] SCHEMA_DIR", os.Args[0])
+	}
+
+	root := flag.Arg(0)
+	files := map[string]string{}
+
+	for _, pattern := range []string{"*/*.schema.sql", "*/upgrades/*.sql"} {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, match := range matches {
+			content, err := ioutil.ReadFile(match)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			files[filepath.ToSlash(rel)] = string(content)
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by tools/embedschema. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", *pkg)
+	buf.WriteString("var files = map[string]string{\n")
+
+	for _, name := range names {
+		fmt.Fprintf(buf, "%q: %s,\n", name, quote(files[name]))
+	}
+
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// quote returns content as one double quoted string per line, so that the generated file stays readable.
+func quote(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	quoted := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if line != "" {
+			quoted = append(quoted, fmt.Sprintf("%q", line))
+		}
+	}
+
+	if len(quoted) == 0 {
+		return `""`
+	}
+
+	return strings.Join(quoted, " +\n")
+}
@@ -0,0 +1,107 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package tsdb
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphiteWriter writes points in the Graphite plaintext protocol over TCP.
+type GraphiteWriter struct {
+	address string
+	prefix  string
+	timeout time.Duration
+}
+
+// NewGraphiteWriter returns a writer connecting to address (host:port) for each batch.
+// The metric paths start with prefix, followed by the host name as Icinga's GraphiteWriter does, e.g.
+// icinga2.web.services.disk.perfdata.root.value.
+func NewGraphiteWriter(address string, prefix string, timeout time.Duration) *GraphiteWriter {
+	return &GraphiteWriter{address: address, prefix: prefix, timeout: timeout}
+}
+
+// Write implements the Writer interface.
+func (w *GraphiteWriter) Write(points []Point) error {
+	conn, err := net.DialTimeout("tcp", w.address, w.timeout)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(w.timeout)); err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(conn)
+	for _, point := range points {
+		w.writeLines(buf, point)
+	}
+
+	return buf.Flush()
+}
+
+// writeLines writes the value and the numeric thresholds and bounds of point, one per line.
+func (w *GraphiteWriter) writeLines(buf *bufio.Writer, point Point) {
+	path := w.path(point)
+	timestamp := " " + strconv.FormatInt(point.Time.Unix(), 10) + "\n"
+
+	writeLine := func(suffix string, value float64) {
+		buf.WriteString(path)
+		buf.WriteString(suffix)
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+		buf.WriteString(timestamp)
+	}
+
+	writeLine(".value", *point.Metric.Value)
+
+	if warn, ok := threshold(point.Metric.Warn); ok {
+		writeLine(".warn", warn)
+	}
+
+	if crit, ok := threshold(point.Metric.Crit); ok {
+		writeLine(".crit", crit)
+	}
+
+	if point.Metric.Min != nil {
+		writeLine(".min", *point.Metric.Min)
+	}
+
+	if point.Metric.Max != nil {
+		writeLine(".max", *point.Metric.Max)
+	}
+}
+
+// path returns the metric path of point without the value suffix.
+func (w *GraphiteWriter) path(point Point) string {
+	var path strings.Builder
+	if w.prefix != "" {
+		path.WriteString(w.prefix)
+		path.WriteByte('.')
+	}
+
+	path.WriteString(graphiteEscape(point.Host))
+	if point.ObjectType == "service" {
+		path.WriteString(".services.")
+		path.WriteString(graphiteEscape(point.Service))
+	} else {
+		path.WriteString(".host")
+	}
+
+	path.WriteString(".perfdata.")
+	path.WriteString(graphiteEscape(point.Metric.Label))
+
+	return path.String()
+}
+
+// graphiteEscape replaces the characters with a meaning in metric paths, like Icinga's GraphiteWriter.
+func graphiteEscape(component string) string {
+	return graphiteEscaper.Replace(component)
+}
+
+var graphiteEscaper = strings.NewReplacer(".", "_", " ", "_", "\t", "_", "\n", "_", "\\", "_", "/", "_")
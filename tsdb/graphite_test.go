@@ -0,0 +1,56 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package tsdb
+
+import (
+	"github.com/Icinga/icingadb/perfdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGraphiteWriter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+		data, _ := ioutil.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	writer := NewGraphiteWriter(listener.Addr().String(), "icinga2", time.Second)
+	at := time.Unix(1577836800, 0)
+
+	require.NoError(t, writer.Write([]Point{
+		{
+			ObjectType: "service", Host: "web.example.com", Service: "disk /", Time: at,
+			Metric: perfdata.Metric{Label: "/var", Value: value(85), Warn: "~:80", Crit: "90:", Max: value(100)},
+		},
+		{ObjectType: "host", Host: "web", Time: at, Metric: perfdata.Metric{Label: "rta", Value: value(0.5)}},
+	}))
+
+	select {
+	case data := <-received:
+		assert.Equal(
+			t,
+			"icinga2.web_example_com.services.disk__.perfdata._var.value 85 1577836800\n"+
+				"icinga2.web_example_com.services.disk__.perfdata._var.warn 80 1577836800\n"+
+				"icinga2.web_example_com.services.disk__.perfdata._var.max 100 1577836800\n"+
+				"icinga2.web.host.perfdata.rta.value 0.5 1577836800\n",
+			data,
+		)
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+}
@@ -0,0 +1,139 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package tsdb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxdbMeasurement is the measurement of all points.
+const influxdbMeasurement = "perfdata"
+
+// InfluxdbWriter writes points in the InfluxDB line protocol over HTTP.
+type InfluxdbWriter struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewInfluxdbWriter returns a writer posting to the write endpoint writeUrl, e.g. http://localhost:8086/write?db=icinga
+// for InfluxDB 1.x or http://localhost:8086/api/v2/write?org=icinga&bucket=icinga for 2.x.
+func NewInfluxdbWriter(writeUrl string, token string, timeout time.Duration) *InfluxdbWriter {
+	// The point times are in milliseconds.
+	if u, err := url.Parse(writeUrl); err == nil {
+		query := u.Query()
+		query.Set("precision", "ms")
+		u.RawQuery = query.Encode()
+		writeUrl = u.String()
+	}
+
+	return &InfluxdbWriter{url: writeUrl, token: token, client: &http.Client{Timeout: timeout}}
+}
+
+// Write implements the Writer interface.
+func (w *InfluxdbWriter) Write(points []Point) error {
+	var body bytes.Buffer
+	for _, point := range points {
+		writeInfluxdbLine(&body, point)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("InfluxDB responded with %s: %s", res.Status, strings.TrimSpace(string(message)))
+
+	switch res.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return RejectedError{err}
+	default:
+		return err
+	}
+}
+
+// writeInfluxdbLine writes point as line, e.g.:
+// perfdata,hostname=web,service=disk,metric=/ value=85,warn=80,crit=90,min=0,max=100,unit="%" 1577836800000
+func writeInfluxdbLine(buf *bytes.Buffer, point Point) {
+	buf.WriteString(influxdbMeasurement)
+
+	writeInfluxdbTag(buf, "hostname", point.Host)
+	writeInfluxdbTag(buf, "service", point.Service)
+	writeInfluxdbTag(buf, "metric", point.Metric.Label)
+
+	buf.WriteString(" value=")
+	buf.WriteString(strconv.FormatFloat(*point.Metric.Value, 'g', -1, 64))
+
+	if warn, ok := threshold(point.Metric.Warn); ok {
+		writeInfluxdbField(buf, "warn", warn)
+	}
+
+	if crit, ok := threshold(point.Metric.Crit); ok {
+		writeInfluxdbField(buf, "crit", crit)
+	}
+
+	if point.Metric.Min != nil {
+		writeInfluxdbField(buf, "min", *point.Metric.Min)
+	}
+
+	if point.Metric.Max != nil {
+		writeInfluxdbField(buf, "max", *point.Metric.Max)
+	}
+
+	if point.Metric.Unit != "" {
+		buf.WriteString(`,unit="`)
+		buf.WriteString(influxdbStringEscaper.Replace(point.Metric.Unit))
+		buf.WriteByte('"')
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(point.Time.UnixNano()/int64(time.Millisecond), 10))
+	buf.WriteByte('\n')
+}
+
+// writeInfluxdbTag writes ",key=value" unless value is empty, which isn't allowed for tags.
+func writeInfluxdbTag(buf *bytes.Buffer, key, value string) {
+	if value == "" {
+		return
+	}
+
+	buf.WriteByte(',')
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(influxdbTagEscaper.Replace(value))
+}
+
+func writeInfluxdbField(buf *bytes.Buffer, key string, value float64) {
+	buf.WriteByte(',')
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+var influxdbTagEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+var influxdbStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
@@ -0,0 +1,61 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package tsdb
+
+import (
+	"github.com/Icinga/icingadb/perfdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestInfluxdbWriter(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	status := http.StatusNoContent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	writer := NewInfluxdbWriter(server.URL+"/write?db=icinga", "secret", time.Second)
+	at := time.Unix(1577836800, 0)
+
+	require.NoError(t, writer.Write([]Point{
+		{
+			ObjectType: "service", Host: "web 1", Service: "disk,root", Time: at,
+			Metric: perfdata.Metric{
+				Label: "/", Value: value(85), Unit: "%", Warn: "80", Crit: "@90:95", Min: value(0), Max: value(100),
+			},
+		},
+		{ObjectType: "host", Host: "web", Time: at, Metric: perfdata.Metric{Label: "rta", Value: value(0.5)}},
+	}))
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, "icinga", requests[0].URL.Query().Get("db"))
+	assert.Equal(t, "ms", requests[0].URL.Query().Get("precision"))
+	assert.Equal(t, "Token secret", requests[0].Header.Get("Authorization"))
+	assert.Equal(
+		t,
+		`perfdata,hostname=web\ 1,service=disk\,root,metric=/ value=85,warn=80,min=0,max=100,unit="%" 1577836800000`+"\n"+
+			"perfdata,hostname=web,metric=rta value=0.5 1577836800000\n",
+		bodies[0],
+	)
+
+	status = http.StatusBadRequest
+	err := writer.Write([]Point{{Host: "web", Time: at, Metric: perfdata.Metric{Label: "rta", Value: value(1)}}})
+	assert.IsType(t, RejectedError{}, err)
+
+	status = http.StatusServiceUnavailable
+	err = writer.Write([]Point{{Host: "web", Time: at, Metric: perfdata.Metric{Label: "rta", Value: value(1)}}})
+	assert.Error(t, err)
+	assert.NotEqual(t, RejectedError{}, err)
+}
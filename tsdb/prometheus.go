@@ -0,0 +1,48 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package tsdb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var WrittenPointsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "perfdata_export_written_points_total",
+		Help: "Perfdata points written per writer",
+	},
+	[]string{"writer"},
+)
+
+var DroppedPointsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "perfdata_export_dropped_points_total",
+		Help: "Perfdata points dropped per writer as the buffer was full, they were rejected or their object is unknown",
+	},
+	[]string{"writer"},
+)
+
+var WriteErrorsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "perfdata_export_write_errors_total",
+		Help: "Failed perfdata writes per writer",
+	},
+	[]string{"writer"},
+)
+
+var BufferedPoints = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "perfdata_export_buffered_points",
+		Help: "Perfdata points waiting to be written per writer",
+	},
+	[]string{"writer"},
+)
+
+var WriteSeconds = promauto.NewSummaryVec(
+	prometheus.SummaryOpts{
+		Name: "perfdata_export_write_seconds",
+		Help: "Duration of perfdata writes per writer",
+	},
+	[]string{"writer"},
+)
@@ -0,0 +1,292 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package tsdb writes the performance data of host and service states to time-series databases.
+package tsdb
+
+import (
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/configobject/names"
+	"github.com/Icinga/icingadb/perfdata"
+	"github.com/Icinga/icingadb/supervisor"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Point is a perfdata metric of a host or service at a point in time.
+type Point struct {
+	ObjectType string
+	Host       string
+	Service    string
+	Metric     perfdata.Metric
+	Time       time.Time
+
+	// id is the ID of the object, whose names are resolved before the point is written.
+	id []byte
+}
+
+// Writer writes points to a time-series database.
+type Writer interface {
+	// Write writes points. If it returns a RejectedError, retrying them is pointless.
+	Write(points []Point) error
+}
+
+// RejectedError is returned by a Writer if the database refused the points themselves, not just this time.
+type RejectedError struct {
+	Err error
+}
+
+func (e RejectedError) Error() string {
+	return e.Err.Error()
+}
+
+// Resolve returns the names of a host or service, names.ErrUnknown if there is no such object.
+type Resolve func(objectType string, id []byte) (host string, service string, err error)
+
+// Exporter buffers points and writes them to each of its writers in batches.
+// All of its methods may be called on nil, which exports nothing.
+type Exporter struct {
+	queues []*queue
+}
+
+// NewExporter returns an Exporter for the writers configured in info, nil if there are none.
+func NewExporter(info *config.PerfdataExportInfo, resolve Resolve) *Exporter {
+	writers := map[string]Writer{}
+	if info.InfluxdbUrl != "" {
+		writers["influxdb"] = NewInfluxdbWriter(info.InfluxdbUrl, info.InfluxdbToken, info.Timeout)
+	}
+
+	if info.GraphiteAddress != "" {
+		writers["graphite"] = NewGraphiteWriter(info.GraphiteAddress, info.GraphitePrefix, info.Timeout)
+	}
+
+	if len(writers) == 0 {
+		return nil
+	}
+
+	exporter := &Exporter{}
+	for name, writer := range writers {
+		exporter.queues = append(exporter.queues, newQueue(name, writer, resolve, info))
+	}
+
+	return exporter
+}
+
+// Start starts writing the buffered points until super is shutting down.
+func (e *Exporter) Start(super *supervisor.Supervisor) {
+	if e == nil {
+		return
+	}
+
+	for _, q := range e.queues {
+		q := q
+		super.Go(func() { q.run(super.Done) })
+	}
+}
+
+// Export buffers the metrics of the host or service with the given ID.
+// Metrics with an unknown value are skipped. It doesn't block on the writers.
+func (e *Exporter) Export(objectType string, id []byte, metrics []perfdata.Metric, t time.Time) {
+	if e == nil {
+		return
+	}
+
+	points := make([]Point, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric.Value != nil {
+			points = append(points, Point{ObjectType: objectType, Metric: metric, Time: t, id: id})
+		}
+	}
+
+	if len(points) == 0 {
+		return
+	}
+
+	for _, q := range e.queues {
+		q.add(points)
+	}
+}
+
+// queue buffers the points of a writer.
+type queue struct {
+	name          string
+	writer        Writer
+	resolve       Resolve
+	batchSize     int
+	bufferSize    int
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	points []Point
+	// full is signalled once there are at least batchSize points.
+	full chan struct{}
+}
+
+func newQueue(name string, writer Writer, resolve Resolve, info *config.PerfdataExportInfo) *queue {
+	return &queue{
+		name:          name,
+		writer:        writer,
+		resolve:       resolve,
+		batchSize:     info.BatchSize,
+		bufferSize:    info.BufferSize,
+		flushInterval: info.FlushInterval,
+		full:          make(chan struct{}, 1),
+	}
+}
+
+// add appends points to the buffer, dropping the oldest ones beyond bufferSize.
+func (q *queue) add(points []Point) {
+	q.mu.Lock()
+	q.points = append(q.points, points...)
+	q.truncate()
+	buffered := len(q.points)
+	q.mu.Unlock()
+
+	BufferedPoints.WithLabelValues(q.name).Set(float64(buffered))
+
+	if buffered >= q.batchSize {
+		select {
+		case q.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// requeue puts points which couldn't be written back in front of the buffer.
+func (q *queue) requeue(points []Point) {
+	q.mu.Lock()
+	q.points = append(points, q.points...)
+	q.truncate()
+	buffered := len(q.points)
+	q.mu.Unlock()
+
+	BufferedPoints.WithLabelValues(q.name).Set(float64(buffered))
+}
+
+// truncate drops the oldest points beyond bufferSize. q.mu must be locked.
+func (q *queue) truncate() {
+	if excess := len(q.points) - q.bufferSize; excess > 0 {
+		q.points = append([]Point(nil), q.points[excess:]...)
+		DroppedPointsTotal.WithLabelValues(q.name).Add(float64(excess))
+	}
+}
+
+// take removes up to batchSize points from the front of the buffer.
+func (q *queue) take() []Point {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := len(q.points)
+	if n > q.batchSize {
+		n = q.batchSize
+	}
+
+	batch := q.points[:n:n]
+	q.points = q.points[n:]
+
+	return batch
+}
+
+// run flushes the buffer every flushInterval or once there's a full batch, and a last time once done is closed.
+func (q *queue) run(done <-chan struct{}) {
+	ticker := time.NewTicker(q.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			q.flush()
+			return
+		case <-ticker.C:
+		case <-q.full:
+		}
+
+		q.flush()
+	}
+}
+
+// flush writes the buffer in batches until it's empty or a write fails. Failed batches are kept for the next flush.
+func (q *queue) flush() {
+	for {
+		batch := q.take()
+		if len(batch) == 0 {
+			return
+		}
+
+		points, err := q.resolveNames(batch)
+		if err != nil {
+			q.requeue(batch)
+			log.WithFields(log.Fields{"context": "PerfdataExport", "writer": q.name}).Error(err)
+			return
+		}
+
+		if len(points) > 0 {
+			start := time.Now()
+			err = q.writer.Write(points)
+			WriteSeconds.WithLabelValues(q.name).Observe(time.Since(start).Seconds())
+		}
+
+		if err != nil {
+			WriteErrorsTotal.WithLabelValues(q.name).Inc()
+
+			if _, rejected := err.(RejectedError); rejected {
+				DroppedPointsTotal.WithLabelValues(q.name).Add(float64(len(points)))
+				log.WithFields(log.Fields{
+					"context": "PerfdataExport", "writer": q.name,
+				}).Errorf("Dropping %d points: %s", len(points), err.Error())
+				continue
+			}
+
+			q.requeue(points)
+			log.WithFields(log.Fields{
+				"context": "PerfdataExport", "writer": q.name,
+			}).Errorf("Can't write %d points, retrying later: %s", len(points), err.Error())
+			return
+		}
+
+		WrittenPointsTotal.WithLabelValues(q.name).Add(float64(len(points)))
+
+		q.mu.Lock()
+		buffered := len(q.points)
+		q.mu.Unlock()
+
+		BufferedPoints.WithLabelValues(q.name).Set(float64(buffered))
+	}
+}
+
+// resolveNames sets the host and service names of points. Points of unknown objects are dropped.
+func (q *queue) resolveNames(points []Point) ([]Point, error) {
+	resolved := make([]Point, 0, len(points))
+	for _, point := range points {
+		if point.Host == "" {
+			host, service, err := q.resolve(point.ObjectType, point.id)
+			if err == names.ErrUnknown {
+				DroppedPointsTotal.WithLabelValues(q.name).Inc()
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			point.Host, point.Service = host, service
+		}
+
+		resolved = append(resolved, point)
+	}
+
+	return resolved, nil
+}
+
+// threshold returns a warning or critical threshold as number, if it's a plain one like "80" or "~:80".
+func threshold(value string) (float64, bool) {
+	if len(value) > 2 && value[:2] == "~:" {
+		value = value[2:]
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+
+	return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+}
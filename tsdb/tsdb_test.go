@@ -0,0 +1,93 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package tsdb
+
+import (
+	"errors"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/configobject/names"
+	"github.com/Icinga/icingadb/perfdata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testWriter struct {
+	err     error
+	written [][]Point
+}
+
+func (w *testWriter) Write(points []Point) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.written = append(w.written, points)
+	return nil
+}
+
+func testResolve(objectType string, id []byte) (string, string, error) {
+	switch string(id) {
+	case "h":
+		return "web", "", nil
+	case "s":
+		return "web", "disk", nil
+	default:
+		return "", "", names.ErrUnknown
+	}
+}
+
+func value(f float64) *float64 {
+	return &f
+}
+
+func TestQueue(t *testing.T) {
+	writer := &testWriter{err: errors.New("unavailable")}
+	q := newQueue("test", writer, testResolve, &config.PerfdataExportInfo{
+		BatchSize: 2, BufferSize: 3, FlushInterval: time.Hour,
+	})
+	exporter := &Exporter{queues: []*queue{q}}
+
+	now := time.Now()
+	exporter.Export("host", []byte("h"), []perfdata.Metric{{Label: "a", Value: value(1)}, {Label: "unknown"}}, now)
+	exporter.Export("service", []byte("s"), []perfdata.Metric{{Label: "b", Value: value(2)}}, now)
+	exporter.Export("service", []byte("gone"), []perfdata.Metric{{Label: "c", Value: value(3)}}, now)
+
+	q.flush()
+	assert.Len(t, q.points, 3, "points must be kept while the writer is unavailable")
+
+	exporter.Export("host", []byte("h"), []perfdata.Metric{{Label: "d", Value: value(4)}}, now)
+	assert.Len(t, q.points, 3, "the oldest points must be dropped beyond the buffer size")
+
+	writer.err = nil
+	q.flush()
+	assert.Empty(t, q.points)
+
+	assert.Equal(t, [][]Point{
+		{
+			{ObjectType: "service", Host: "web", Service: "disk", Metric: perfdata.Metric{Label: "b", Value: value(2)}, Time: now, id: []byte("s")},
+		},
+		{
+			{ObjectType: "host", Host: "web", Metric: perfdata.Metric{Label: "d", Value: value(4)}, Time: now, id: []byte("h")},
+		},
+	}, writer.written, "points of unknown objects must be dropped")
+}
+
+func TestQueueRejected(t *testing.T) {
+	writer := &testWriter{err: RejectedError{errors.New("bad request")}}
+	q := newQueue("test", writer, testResolve, &config.PerfdataExportInfo{
+		BatchSize: 1, BufferSize: 10, FlushInterval: time.Hour,
+	})
+
+	q.add([]Point{{ObjectType: "host", Metric: perfdata.Metric{Label: "a", Value: value(1)}, id: []byte("h")}})
+	q.flush()
+
+	assert.Empty(t, q.points, "rejected points must not be retried")
+}
+
+func TestNilExporter(t *testing.T) {
+	assert.Nil(t, NewExporter(&config.PerfdataExportInfo{}, testResolve))
+
+	var exporter *Exporter
+	exporter.Export("host", []byte("h"), []perfdata.Metric{{Label: "a", Value: value(1)}}, time.Now())
+}
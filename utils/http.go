@@ -0,0 +1,33 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package utils
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// WriteJSON writes response as JSON with the given status code.
+func WriteJSON(w http.ResponseWriter, code int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.WithFields(log.Fields{"context": "HTTP", "error": err}).Debug("Can't write response")
+	}
+}
+
+// WriteJSONError writes {"error": message} with the given status code.
+func WriteJSONError(w http.ResponseWriter, code int, message string) {
+	WriteJSON(w, code, map[string]string{"error": message})
+}
+
+// HasBearerToken returns whether r is authorized by token, sent as "Authorization: Bearer <token>".
+// The comparison takes constant time, so that the token can't be guessed by timing.
+func HasBearerToken(r *http.Request, token string) bool {
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
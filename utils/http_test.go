@@ -0,0 +1,30 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSONError(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteJSONError(w, http.StatusNotFound, "unknown resource")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"unknown resource"}`, w.Body.String())
+}
+
+func TestHasBearerToken(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	assert.False(t, HasBearerToken(r, "secret"))
+
+	r.Header.Set("Authorization", "Bearer guess")
+	assert.False(t, HasBearerToken(r, "secret"))
+
+	r.Header.Set("Authorization", "Bearer secret")
+	assert.True(t, HasBearerToken(r, "secret"))
+}
@@ -0,0 +1,134 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/events"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// endpoint posts the events matching its filters to the URL of a [webhook.<name>] section.
+type endpoint struct {
+	info     *config.WebhookInfo
+	filter   events.Filter
+	template *template.Template
+	client   *http.Client
+}
+
+func newEndpoint(info *config.WebhookInfo) (*endpoint, error) {
+	e := &endpoint{
+		info:   info,
+		filter: events.Filter{Types: info.EventTypes, Hosts: info.Hosts, Services: info.Services},
+		client: &http.Client{Timeout: info.Timeout},
+	}
+
+	if info.PayloadTemplate != "" {
+		text, err := ioutil.ReadFile(info.PayloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("webhook.%s: %s", info.Name, err.Error())
+		}
+
+		e.template, err = template.New(info.PayloadTemplate).Funcs(templateFuncs).Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("webhook.%s: %s", info.Name, err.Error())
+		}
+	}
+
+	return e, nil
+}
+
+// templateFuncs are available in payload templates in addition to the builtin ones.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. {"text": {{ json .Data.output }}}.
+	"json": func(v interface{}) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// render returns the payload of event.
+func (e *endpoint) render(event *events.Event) (string, error) {
+	if e.template == nil {
+		encoded, err := json.Marshal(event)
+		return string(encoded), err
+	}
+
+	var payload strings.Builder
+	if err := e.template.Execute(&payload, event); err != nil {
+		return "", err
+	}
+
+	return payload.String(), nil
+}
+
+// post sends the payload of d. The error says whether retrying may help.
+func (e *endpoint) post(d *delivery) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, e.info.Url, strings.NewReader(d.Payload))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "icingadb")
+	req.Header.Set("X-Icingadb-Event", d.EventType)
+	req.Header.Set("X-Icingadb-Delivery", d.EventID)
+
+	if e.info.Secret != "" {
+		req.Header.Set("X-Icingadb-Signature", "sha256="+sign(e.info.Secret, d.Payload))
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer res.Body.Close()
+	message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("%s responded with %s: %s", e.info.Url, res.Status, string(bytes.TrimSpace(message)))
+
+	switch {
+	case res.StatusCode >= 500, res.StatusCode == http.StatusRequestTimeout, res.StatusCode == http.StatusTooManyRequests:
+		return true, err
+	default:
+		return false, err
+	}
+}
+
+// backoff returns how long to wait before the given retry, doubling from RetryInterval up to an hour.
+func (e *endpoint) backoff(retry int) time.Duration {
+	wait := e.info.RetryInterval
+	for i := 1; i < retry && wait < time.Hour; i++ {
+		wait *= 2
+	}
+
+	if wait > time.Hour {
+		wait = time.Hour
+	}
+
+	return wait
+}
+
+// sign returns the hex encoded HMAC-SHA256 of payload.
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,108 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package webhook
+
+import (
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEndpoint_Render(t *testing.T) {
+	event := &events.Event{
+		ID: "1", Type: "state_change", Time: time.Unix(1577836800, 0).UTC(), ObjectType: "service",
+		Host: "db-1", Service: "disk /", Data: map[string]string{"output": `DISK "CRITICAL"`},
+	}
+
+	e, err := newEndpoint(&config.WebhookInfo{Name: "chat"})
+	require.NoError(t, err)
+
+	payload, err := e.render(event)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "1", "type": "state_change", "time": "2020-01-01T00:00:00Z", "object_type": "service",
+		"host": "db-1", "service": "disk /", "data": {"output": "DISK \"CRITICAL\""}
+	}`, payload)
+
+	file, err := ioutil.TempFile("", "icingadb")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{"text": {{ json (printf "%s!%s: %s" .Host .Service .Data.output) }}}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	e, err = newEndpoint(&config.WebhookInfo{Name: "chat", PayloadTemplate: file.Name()})
+	require.NoError(t, err)
+
+	payload, err = e.render(event)
+	require.NoError(t, err)
+	assert.Equal(t, `{"text": "db-1!disk /: DISK \"CRITICAL\""}`, payload)
+}
+
+func TestEndpoint_Post(t *testing.T) {
+	var request *http.Request
+	var body string
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		request, body = r, string(b)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	e, err := newEndpoint(&config.WebhookInfo{Name: "chat", Url: server.URL, Secret: "secret", Timeout: time.Second})
+	require.NoError(t, err)
+
+	del := &delivery{Endpoint: "chat", EventID: "1", EventType: "notification", Payload: `{"id":"1"}`}
+
+	retry, err := e.post(del)
+	require.NoError(t, err)
+	assert.False(t, retry)
+	assert.Equal(t, `{"id":"1"}`, body)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, "notification", request.Header.Get("X-Icingadb-Event"))
+	assert.Equal(t, "1", request.Header.Get("X-Icingadb-Delivery"))
+	assert.Equal(
+		t, "sha256=6146142a2ce0159e84c0767881e4ec80bc397da62526e7d19f70795eb79460c0",
+		request.Header.Get("X-Icingadb-Signature"),
+	)
+
+	for s, shouldRetry := range map[int]bool{
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusTooManyRequests:     true,
+		http.StatusRequestTimeout:      true,
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+	} {
+		status = s
+
+		retry, err := e.post(del)
+		assert.Error(t, err, s)
+		assert.Equal(t, shouldRetry, retry, s)
+	}
+
+	server.Close()
+
+	retry, err = e.post(del)
+	assert.Error(t, err)
+	assert.True(t, retry, "network errors should be retried")
+}
+
+func TestEndpoint_Backoff(t *testing.T) {
+	e := &endpoint{info: &config.WebhookInfo{RetryInterval: 10 * time.Second}}
+
+	assert.Equal(t, 10*time.Second, e.backoff(1))
+	assert.Equal(t, 20*time.Second, e.backoff(2))
+	assert.Equal(t, 40*time.Second, e.backoff(3))
+	assert.Equal(t, time.Hour, e.backoff(20))
+}
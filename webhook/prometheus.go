@@ -0,0 +1,23 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package webhook
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var DeliveriesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "webhook_deliveries_total",
+		Help: "Webhook delivery attempts per webhook and outcome (delivered, retried or failed)",
+	},
+	[]string{"webhook", "outcome"},
+)
+
+var RetryQueueSize = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "webhook_retry_queue_size",
+		Help: "Webhook deliveries waiting to be retried",
+	},
+)
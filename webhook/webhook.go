@@ -0,0 +1,221 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+// Package webhook posts history events, e.g. state changes and notifications, to HTTP endpoints.
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/connection"
+	"github.com/Icinga/icingadb/events"
+	"github.com/Icinga/icingadb/supervisor"
+	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"time"
+)
+
+// RetryQueue is the Redis sorted set of the deliveries to retry, scored by the time of their next attempt.
+// As it outlives restarts and is shared by all instances, no delivery is lost on a takeover.
+const RetryQueue = "icingadb:webhook:retry"
+
+// senders is the number of deliveries posted concurrently.
+const senders = 4
+
+// delivery is the payload of an event for one endpoint, as stored in RetryQueue.
+type delivery struct {
+	Endpoint  string `json:"endpoint"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Payload   string `json:"payload"`
+	Attempts  int    `json:"attempts"`
+}
+
+// Dispatcher posts events to the endpoints whose filters they match.
+// All of its methods may be called on nil, which posts nothing.
+type Dispatcher struct {
+	rdbw      *connection.RDBWrapper
+	resolve   events.Resolve
+	endpoints []*endpoint
+	queue     chan *delivery
+}
+
+// NewDispatcher returns a Dispatcher for the configured webhooks, nil if there are none.
+func NewDispatcher(rdbw *connection.RDBWrapper, resolve events.Resolve, webhooks []*config.WebhookInfo) (*Dispatcher, error) {
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+
+	d := &Dispatcher{rdbw: rdbw, resolve: resolve, queue: make(chan *delivery, 1000)}
+	for _, info := range webhooks {
+		e, err := newEndpoint(info)
+		if err != nil {
+			return nil, err
+		}
+
+		d.endpoints = append(d.endpoints, e)
+	}
+
+	return d, nil
+}
+
+// Start starts posting the dispatched events and retrying the failed ones until super is shutting down.
+func (d *Dispatcher) Start(super *supervisor.Supervisor) {
+	if d == nil {
+		return
+	}
+
+	for i := 0; i < senders; i++ {
+		super.Go(func() { d.send(super.Done) })
+	}
+
+	super.Go(func() { d.pollRetries(super.Done) })
+}
+
+// Dispatch queues the event of the history stream entry values for the matching endpoints.
+// It doesn't block on them: if too many deliveries are pending, they're queued for retry right away.
+func (d *Dispatcher) Dispatch(eventType string, eventTime time.Time, values map[string]interface{}) {
+	if d == nil {
+		return
+	}
+
+	event := events.FromHistory(d.resolve, eventType, eventTime, values)
+
+	for _, e := range d.endpoints {
+		if !e.filter.Matches(event) {
+			continue
+		}
+
+		payload, err := e.render(event)
+		if err != nil {
+			DeliveriesTotal.WithLabelValues(e.info.Name, "failed").Inc()
+			log.WithFields(log.Fields{"context": "Webhook", "webhook": e.info.Name}).Error(err)
+			continue
+		}
+
+		del := &delivery{Endpoint: e.info.Name, EventID: event.ID, EventType: event.Type, Payload: payload}
+
+		select {
+		case d.queue <- del:
+		default:
+			d.scheduleRetry(del, time.Now())
+		}
+	}
+}
+
+// send posts the queued deliveries until done is closed. Those still queued then are moved to RetryQueue.
+func (d *Dispatcher) send(done <-chan struct{}) {
+	for {
+		select {
+		case del := <-d.queue:
+			d.post(del)
+		case <-done:
+			for {
+				select {
+				case del := <-d.queue:
+					d.scheduleRetry(del, time.Now())
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// post posts del and schedules a retry if it failed for a reason which may go away.
+func (d *Dispatcher) post(del *delivery) {
+	var e *endpoint
+	for _, candidate := range d.endpoints {
+		if candidate.info.Name == del.Endpoint {
+			e = candidate
+		}
+	}
+
+	if e == nil {
+		// The webhook has been removed from the config since the delivery failed.
+		return
+	}
+
+	retry, err := e.post(del)
+	if err == nil {
+		DeliveriesTotal.WithLabelValues(e.info.Name, "delivered").Inc()
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"context": "Webhook", "webhook": e.info.Name, "event": del.EventID, "attempts": del.Attempts + 1,
+	})
+
+	if !retry || del.Attempts >= e.info.MaxRetries {
+		DeliveriesTotal.WithLabelValues(e.info.Name, "failed").Inc()
+		logger.Errorf("Giving up delivery: %s", err.Error())
+		return
+	}
+
+	del.Attempts++
+	DeliveriesTotal.WithLabelValues(e.info.Name, "retried").Inc()
+	logger.Warnf("Delivery failed, retrying: %s", err.Error())
+
+	d.scheduleRetry(del, time.Now().Add(e.backoff(del.Attempts)))
+}
+
+// scheduleRetry adds del to RetryQueue to be posted at the given time.
+func (d *Dispatcher) scheduleRetry(del *delivery, at time.Time) {
+	member, err := json.Marshal(del)
+	if err == nil {
+		err = d.rdbw.ZAdd(RetryQueue, redis.Z{Score: float64(at.Unix()), Member: string(member)}).Err()
+	}
+
+	if err != nil {
+		DeliveriesTotal.WithLabelValues(del.Endpoint, "failed").Inc()
+		log.WithFields(log.Fields{
+			"context": "Webhook", "webhook": del.Endpoint, "event": del.EventID,
+		}).Errorf("Can't queue delivery for retry: %s", err.Error())
+	}
+}
+
+// pollRetries queues the deliveries of RetryQueue which are due, every second until done is closed.
+func (d *Dispatcher) pollRetries(done <-chan struct{}) {
+	every1s := time.NewTicker(time.Second)
+	defer every1s.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-every1s.C:
+		}
+
+		if size, err := d.rdbw.ZCard(RetryQueue).Result(); err == nil {
+			RetryQueueSize.Set(float64(size))
+		}
+
+		due, err := d.rdbw.ZRangeByScore(RetryQueue, redis.ZRangeBy{
+			Min: "-inf", Max: strconv.FormatInt(time.Now().Unix(), 10), Count: 100,
+		}).Result()
+		if err != nil {
+			log.WithFields(log.Fields{"context": "Webhook"}).Error(err)
+			continue
+		}
+
+		for _, member := range due {
+			// Only the instance which removed it posts a delivery.
+			if removed, err := d.rdbw.ZRem(RetryQueue, member).Result(); err != nil || removed == 0 {
+				continue
+			}
+
+			del := &delivery{}
+			if err := json.Unmarshal([]byte(member), del); err != nil {
+				log.WithFields(log.Fields{"context": "Webhook"}).Errorf("Dropping invalid delivery: %s", err.Error())
+				continue
+			}
+
+			select {
+			case d.queue <- del:
+			case <-done:
+				d.scheduleRetry(del, time.Now())
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// IcingaDB | (c) 2019 Icinga GmbH | GPLv2+
+
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/Icinga/icingadb/config"
+	"github.com/Icinga/icingadb/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	var nilDispatcher *Dispatcher
+	nilDispatcher.Dispatch("state_change", time.Now(), nil)
+
+	d, err := NewDispatcher(nil, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	var resolved []string
+	resolve := func(objectType string, id []byte) (string, string, error) {
+		resolved = append(resolved, objectType+" "+string(id))
+		return "db-1", "disk /", nil
+	}
+
+	d, err = NewDispatcher(nil, resolve, []*config.WebhookInfo{
+		{Name: "all"},
+		{Name: "notifications", EventTypes: []string{"notification"}},
+		{Name: "disks", Services: []string{"disk *"}},
+	})
+	require.NoError(t, err)
+
+	at := time.Unix(1577836800, 0).UTC()
+	d.Dispatch("state_change", at, map[string]interface{}{
+		"event_id": "42", "object_type": "service", "host_id": "00", "service_id": "7365727669636531",
+		"output": "DISK CRITICAL", "check_attempt": 3,
+	})
+
+	assert.Equal(t, []string{"service service1"}, resolved)
+	require.Len(t, d.queue, 2)
+
+	for _, endpoint := range []string{"all", "disks"} {
+		del := <-d.queue
+		assert.Equal(t, endpoint, del.Endpoint)
+		assert.Equal(t, "42", del.EventID)
+		assert.Equal(t, "state_change", del.EventType)
+		assert.Equal(t, 0, del.Attempts)
+
+		var event events.Event
+		require.NoError(t, json.Unmarshal([]byte(del.Payload), &event))
+		assert.Equal(t, events.Event{
+			ID: "42", Type: "state_change", Time: at, ObjectType: "service", Host: "db-1", Service: "disk /",
+			Data: map[string]string{
+				"event_id": "42", "object_type": "service", "host_id": "00", "service_id": "7365727669636531",
+				"output": "DISK CRITICAL",
+			},
+		}, event)
+	}
+}